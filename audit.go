@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	"github.com/giantswarm/microerror"
+
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/audit"
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/azure"
+)
+
+const (
+	auditOutputTable = "table"
+	auditOutputJSON  = "json"
+)
+
+// runAudit runs the audit subcommand, which compares the private endpoints that the operator
+// wants for all AzureClusters in the management cluster with the private endpoints in the
+// AzureCluster specs and in Azure, and prints the missing, orphaned, pending approval and IP
+// mismatch findings. The management cluster is accessed with the current kubeconfig context:
+//
+//	manager audit -management-cluster-name=giant -management-cluster-namespace=org-giantswarm
+//
+// It returns the exit code: 1 when the audit fails, 2 when there are findings, and 0 otherwise.
+func runAudit(args []string) int {
+	var (
+		managementClusterName      string
+		managementClusterNamespace string
+		output                     string
+		fallbackCredential         string
+		resourceManagerEndpoint    string
+		verbose                    bool
+	)
+	flags := flag.NewFlagSet("audit", flag.ContinueOnError)
+	flags.StringVar(&managementClusterName, "management-cluster-name", "",
+		"The name of the management cluster AzureCluster CR")
+	flags.StringVar(&managementClusterNamespace, "management-cluster-namespace", "",
+		"The namespace where the management cluster AzureCluster CR is deployed")
+	flags.StringVar(&output, "output", auditOutputTable,
+		"The output format: 'table' or 'json'")
+	flags.StringVar(&fallbackCredential, "fallback-credential", azure.FallbackCredentialNone,
+		"The credential that is used for AzureClusters without an identityRef: 'none' or 'default' (Azure default credential, e.g. from az login)")
+	flags.StringVar(&resourceManagerEndpoint, "azure-resource-manager-endpoint", "",
+		"Custom Azure Resource Manager endpoint that replaces the endpoint of the Azure cloud of every AzureCluster")
+	flags.BoolVar(&verbose, "v", false,
+		"Print the logs to stderr")
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	logOutput := io.Discard
+	if verbose {
+		logOutput = os.Stderr
+	}
+	ctrl.SetLogger(zap.New(zap.WriteTo(logOutput)))
+
+	if output != auditOutputTable && output != auditOutputJSON {
+		fmt.Fprintf(os.Stderr, "invalid output %q, expected %q or %q\n", output, auditOutputTable, auditOutputJSON)
+		return 1
+	}
+
+	config, err := ctrl.GetConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "unable to get kubeconfig: %s\n", err)
+		return 1
+	}
+	k8sClient, err := client.New(config, client.Options{Scheme: scheme})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "unable to create client: %s\n", err)
+		return 1
+	}
+
+	azureFallbackCredential, err := azure.NewFallbackCredentialCreator(fallbackCredential)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "unable to create fallback Azure credential: %s\n", microerror.Pretty(err, false))
+		return 1
+	}
+	azureClientCache := azure.NewClientCache(azure.ClientCacheOptions{
+		FallbackCredential:      azureFallbackCredential,
+		ResourceManagerEndpoint: resourceManagerEndpoint,
+	})
+
+	auditor, err := audit.NewAuditor(k8sClient, azureClientCache.NewPrivateEndpointClient, types.NamespacedName{
+		Namespace: managementClusterNamespace,
+		Name:      managementClusterName,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "unable to create auditor: %s\n", microerror.Pretty(err, false))
+		return 1
+	}
+
+	report, err := auditor.Audit(context.Background())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "unable to audit: %s\n", microerror.Pretty(err, false))
+		return 1
+	}
+
+	if output == auditOutputJSON {
+		err = report.WriteJSON(os.Stdout)
+	} else {
+		err = report.WriteTable(os.Stdout)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "unable to write report: %s\n", microerror.Pretty(err, false))
+		return 1
+	}
+
+	if len(report.Findings) > 0 {
+		return 2
+	}
+	return 0
+}
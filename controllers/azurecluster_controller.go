@@ -21,61 +21,114 @@ import (
 	"fmt"
 	"time"
 
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
 	capz "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	capi "sigs.k8s.io/cluster-api/api/core/v1beta1"
+	v1beta1conditions "sigs.k8s.io/cluster-api/util/deprecated/v1beta1/conditions"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	"github.com/giantswarm/microerror"
 
 	"github.com/giantswarm/azure-private-endpoint-operator/pkg/azure"
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/azurecluster"
 	"github.com/giantswarm/azure-private-endpoint-operator/pkg/errors"
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/events"
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/metrics"
 	"github.com/giantswarm/azure-private-endpoint-operator/pkg/privateendpoints"
 	"github.com/giantswarm/azure-private-endpoint-operator/pkg/privatelinks"
 )
 
 const (
 	AzureClusterControllerFinalizer string = "azure-private-endpoint-operator.giantswarm.io/azurecluster"
+
+	// ConditionGSAzureAccessReady is set to False on the workload AzureCluster when Azure rejects
+	// the requests of the operator in a way that retrying does not fix, e.g. when the identity is
+	// missing permissions or a quota has been exceeded.
+	ConditionGSAzureAccessReady capi.ConditionType = "GSAzureAccessReady"
+
+	AzureAuthFailureReason               = "AzureAuthFailure"
+	AzureQuotaExceededReason             = "AzureQuotaExceeded"
+	AzureClusterIdentityNotAllowedReason = "AzureClusterIdentityNotAllowed"
+	AzureClusterIdentityInvalidReason    = "AzureClusterIdentityInvalid"
+	AzureEnvironmentInvalidReason        = "AzureEnvironmentInvalid"
+
+	DefaultRetryInitialDelay = 5 * time.Second
+	DefaultRetryMaxDelay     = 5 * time.Minute
 )
 
 // Options holds optional configuration for AzureClusterReconciler.
-type Options struct{}
+type Options struct {
+	// RetryInitialDelay is the delay before the first retry after a retriable error, e.g. when the
+	// private links of a workload cluster are not ready yet. The delay doubles with every further
+	// retry of the same cluster and is reset when the cluster is reconciled successfully. Defaults
+	// to DefaultRetryInitialDelay.
+	RetryInitialDelay time.Duration
+	// RetryMaxDelay is the maximum delay between retries after retriable errors. Defaults to
+	// DefaultRetryMaxDelay.
+	RetryMaxDelay time.Duration
+}
 
 // AzureClusterReconciler reconciles a AzureCluster object
 type AzureClusterReconciler struct {
 	client.Client
 	privateEndpointsClientCreator azure.PrivateEndpointsClientCreator
+	recorder                      *events.Recorder
 	managementClusterName         types.NamespacedName
 	options                       Options
+	retryBackoff                  workqueue.TypedRateLimiter[types.NamespacedName]
 }
 
-func NewAzureClusterReconciler(client client.Client, privateEndpointsClientCreator azure.PrivateEndpointsClientCreator, managementClusterName types.NamespacedName, options Options) (*AzureClusterReconciler, error) {
+func NewAzureClusterReconciler(client client.Client, privateEndpointsClientCreator azure.PrivateEndpointsClientCreator, recorder *events.Recorder, managementClusterName types.NamespacedName, options Options) (*AzureClusterReconciler, error) {
 	if client == nil {
 		return nil, microerror.Maskf(errors.InvalidConfigError, "client must be set")
 	}
 	if privateEndpointsClientCreator == nil {
 		return nil, microerror.Maskf(errors.InvalidConfigError, "privateEndpointsClientCreator must be set")
 	}
+	if recorder == nil {
+		return nil, microerror.Maskf(errors.InvalidConfigError, "recorder must be set")
+	}
 	if managementClusterName.Name == "" {
 		return nil, microerror.Maskf(errors.InvalidConfigError, "%T.Name must be set", managementClusterName)
 	}
 	if managementClusterName.Namespace == "" {
 		return nil, microerror.Maskf(errors.InvalidConfigError, "%T.Namespace must be set", managementClusterName)
 	}
+	if options.RetryInitialDelay == 0 {
+		options.RetryInitialDelay = DefaultRetryInitialDelay
+	}
+	if options.RetryMaxDelay == 0 {
+		options.RetryMaxDelay = DefaultRetryMaxDelay
+	}
+	if options.RetryInitialDelay < 0 {
+		return nil, microerror.Maskf(errors.InvalidConfigError, "%T.RetryInitialDelay must not be negative", options)
+	}
+	if options.RetryMaxDelay < options.RetryInitialDelay {
+		return nil, microerror.Maskf(errors.InvalidConfigError, "%T.RetryMaxDelay must not be less than %T.RetryInitialDelay", options, options)
+	}
 	return &AzureClusterReconciler{
 		Client:                        client,
 		privateEndpointsClientCreator: privateEndpointsClientCreator,
+		recorder:                      recorder,
 		managementClusterName:         managementClusterName,
 		options:                       options,
+		retryBackoff:                  workqueue.NewTypedItemExponentialFailureRateLimiter[types.NamespacedName](options.RetryInitialDelay, options.RetryMaxDelay),
 	}, nil
 }
 
 //+kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io.giantswarm.io,resources=azureclusters,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io.giantswarm.io,resources=azureclusters/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io.giantswarm.io,resources=azureclusters/finalizers,verbs=update
+//+kubebuilder:rbac:groups=events.k8s.io,resources=events,verbs=create;patch
+//+kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
 
 // Reconcile AzureCluster for private workload clusters by ensuring that there is a private
 // endpoint for every private link.
@@ -90,6 +143,7 @@ func (r *AzureClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request
 	err = r.Get(ctx, req.NamespacedName, &workloadAzureCluster)
 	if apierrors.IsNotFound(err) {
 		logger.Info("AzureCluster no longer exists")
+		r.resetRetryBackoff(req.NamespacedName)
 		return ctrl.Result{}, nil
 	} else if err != nil {
 		return ctrl.Result{}, microerror.Mask(err)
@@ -102,16 +156,26 @@ func (r *AzureClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		return ctrl.Result{}, nil
 	}
 
+	// Every error that is not retriable is also reported as an event on the workload AzureCluster,
+	// so that it is visible without looking into the operator logs, and counted in the metrics.
+	defer func() {
+		if err != nil {
+			metrics.RecordReconcileError(metrics.ControllerAzureCluster, err)
+			r.recorder.Warningf(&workloadAzureCluster, events.ReasonReconcileError, events.ActionReconcile,
+				"Failed to reconcile private endpoints: %s", microerror.Pretty(err, false))
+		}
+	}()
+
 	var managementAzureCluster capz.AzureCluster
 	if err = r.Get(ctx, r.managementClusterName, &managementAzureCluster); err != nil {
 		return ctrl.Result{}, microerror.Mask(err)
 	}
 
-	if err = validateLBType(workloadAzureCluster); err != nil {
+	if err = azurecluster.ValidateLBType(workloadAzureCluster); err != nil {
 		return ctrl.Result{}, microerror.Mask(err)
 	}
 
-	if err = validateLBType(managementAzureCluster); err != nil {
+	if err = azurecluster.ValidateLBType(managementAzureCluster); err != nil {
 		return ctrl.Result{}, microerror.Mask(err)
 	}
 
@@ -133,12 +197,12 @@ func (r *AzureClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request
 	// private endpoints and to update them.
 	mcPrivateEndpointsClient, err := r.privateEndpointsClientCreator(ctx, r.Client, &managementAzureCluster)
 	if err != nil {
-		return ctrl.Result{}, microerror.Mask(err)
+		return r.handleReconcileError(ctx, privateLinksScope, err)
 	}
 
 	wcPrivateEndpointsClient, err := r.privateEndpointsClientCreator(ctx, r.Client, &workloadAzureCluster)
 	if err != nil {
-		return ctrl.Result{}, microerror.Mask(err)
+		return r.handleReconcileError(ctx, privateLinksScope, err)
 	}
 
 	// will be used for MC to WC connections
@@ -160,12 +224,12 @@ func (r *AzureClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		return ctrl.Result{}, microerror.Mask(err)
 	}
 
-	mcPrivateEndpointsService, err := privateendpoints.NewService(mcPrivateEndpointsScope, privateLinksScope)
+	mcPrivateEndpointsService, err := privateendpoints.NewService(mcPrivateEndpointsScope, privateLinksScope, r.recorder)
 	if err != nil {
 		return ctrl.Result{}, microerror.Mask(err)
 	}
 
-	wcPrivateEndpointsService, err := privateendpoints.NewService(wcPrivateEndpointsScope, privateLinksScope)
+	wcPrivateEndpointsService, err := privateendpoints.NewService(wcPrivateEndpointsScope, privateLinksScope, r.recorder)
 	if err != nil {
 		return ctrl.Result{}, microerror.Mask(err)
 	}
@@ -181,16 +245,11 @@ func (r *AzureClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		// and the gateway LB is internal with a private link (<mc-name>-gateway-privatelink).
 		// We add a private endpoint to WC so that monitoring tools in WC can access the MC gateway.
 		if err == nil && managementAzureCluster.Spec.NetworkSpec.APIServerLB.Type == capz.Internal {
-			err = wcPrivateEndpointsService.ReconcileWcToMcIngress(ctx, generateWcToMcPrivateEndpointSpecs(workloadAzureCluster, managementAzureCluster))
+			err = wcPrivateEndpointsService.ReconcileWcToMcIngress(ctx, privateendpoints.WcToMcIngressPrivateEndpointSpecs(workloadAzureCluster, managementAzureCluster))
 		}
 
-		if errors.IsRetriable(err) {
-			logger.Info("A retriable error occurred, trying again in a minute", "error", err)
-			return ctrl.Result{
-				RequeueAfter: time.Minute,
-			}, nil
-		} else if err != nil {
-			return ctrl.Result{}, microerror.Mask(err)
+		if result, err = r.handleReconcileError(ctx, privateLinksScope, err); err != nil || !result.IsZero() {
+			return result, err
 		}
 	} else {
 		if workloadAzureCluster.Spec.NetworkSpec.APIServerLB.Type == capz.Internal {
@@ -199,8 +258,8 @@ func (r *AzureClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		// We don't need to do anything for WC to MC connections.
 		// CAPI controllers will clean private endpoints in WC side automatically.
 
-		if err != nil {
-			return ctrl.Result{}, microerror.Mask(err)
+		if result, err = r.handleReconcileError(ctx, privateLinksScope, err); err != nil || !result.IsZero() {
+			return result, err
 		}
 		r.removeFinalizer(&workloadAzureCluster)
 	}
@@ -208,42 +267,84 @@ func (r *AzureClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request
 	return ctrl.Result{}, nil
 }
 
-// generateWcToMcPrivateEndpointSpecs generates PrivateEndpointSpecs for the private endpoint that
-// connects the WC to the gateway of the management cluster.
-// It assumes a private link named "<mc-name>-gateway-privatelink".
-func generateWcToMcPrivateEndpointSpecs(wc capz.AzureCluster, mc capz.AzureCluster) []capz.PrivateEndpointSpec {
-	gatewaySpec := capz.PrivateEndpointSpec{
-		Name:     fmt.Sprintf("%s-to-%s-gateway-privateendpoint", wc.Name, mc.Name),
-		Location: wc.Spec.Location,
-		PrivateLinkServiceConnections: []capz.PrivateLinkServiceConnection{
-			{
-				Name: fmt.Sprintf("%s-to-%s-gateway-connection", wc.Name, mc.Name),
-				PrivateLinkServiceID: fmt.Sprintf(
-					"/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Network/privateLinkServices/%s",
-					mc.Spec.SubscriptionID,
-					mc.Name,
-					fmt.Sprintf("%s-gateway-privatelink", mc.Name)),
-			},
-		},
-		ManualApproval: false,
+// handleReconcileError decides how the reconciliation is retried, based on the classification of
+// the error that was returned when reconciling the private endpoints:
+//   - our own retriable errors (e.g. private links that are not ready yet) are retried with a
+//     per-cluster exponential backoff,
+//   - throttled Azure API requests are retried after the duration from the Retry-After header,
+//   - transient Azure API errors are returned, so they are retried with exponential backoff,
+//   - Azure auth failures, exceeded quotas, unknown Azure environments, and AzureClusterIdentities
+//     that are not set, not supported or do not allow the namespace of the cluster are set as a
+//     condition on the workload AzureCluster and returned as terminal errors, as retrying does
+//     not help until they are fixed.
+func (r *AzureClusterReconciler) handleReconcileError(ctx context.Context, privateLinksScope *privatelinks.Scope, err error) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+	clusterName := privateLinksScope.GetClusterName()
+
+	switch {
+	case err == nil:
+		r.resetRetryBackoff(clusterName)
+
+		// Only clear the condition when it was set before, so that it is not added to every cluster.
+		if v1beta1conditions.Has(privateLinksScope, ConditionGSAzureAccessReady) &&
+			!v1beta1conditions.IsTrue(privateLinksScope, ConditionGSAzureAccessReady) {
+			privateLinksScope.SetCondition(capi.Condition{
+				Type:               ConditionGSAzureAccessReady,
+				Status:             corev1.ConditionTrue,
+				LastTransitionTime: metav1.Now(),
+			})
+		}
+		return ctrl.Result{}, nil
+	case errors.IsRetriable(err):
+		requeueAfter := r.retryBackoff.When(clusterName)
+		attempts := r.retryBackoff.NumRequeues(clusterName)
+		metrics.RecordReconcileError(metrics.ControllerAzureCluster, err)
+		metrics.SetRetriableErrorAttempts(clusterName, attempts)
+		logger.Info("A retriable error occurred, trying again later", "error", err, "requeueAfter", requeueAfter, "attempts", attempts)
+		return ctrl.Result{RequeueAfter: requeueAfter}, nil
+	case errors.IsAzureThrottled(err):
+		if retryAfter, ok := errors.AzureRetryAfter(err); ok && retryAfter > 0 {
+			metrics.RecordReconcileError(metrics.ControllerAzureCluster, err)
+			logger.Info("Azure API request was throttled, trying again after Retry-After", "retryAfter", retryAfter)
+			return ctrl.Result{RequeueAfter: retryAfter}, nil
+		}
+		return ctrl.Result{}, microerror.Mask(err)
+	case errors.IsAzureAuthFailure(err):
+		setAzureAccessNotReady(privateLinksScope, AzureAuthFailureReason, err)
+		return ctrl.Result{}, reconcile.TerminalError(err)
+	case errors.IsAzureClusterIdentityNotAllowed(err):
+		setAzureAccessNotReady(privateLinksScope, AzureClusterIdentityNotAllowedReason, err)
+		return ctrl.Result{}, reconcile.TerminalError(err)
+	case errors.IsIdentityRefNotSet(err), errors.IsUnsupportedIdentityType(err):
+		setAzureAccessNotReady(privateLinksScope, AzureClusterIdentityInvalidReason, err)
+		return ctrl.Result{}, reconcile.TerminalError(err)
+	case errors.IsUnknownAzureEnvironment(err):
+		setAzureAccessNotReady(privateLinksScope, AzureEnvironmentInvalidReason, err)
+		return ctrl.Result{}, reconcile.TerminalError(err)
+	case errors.IsAzureQuotaExceeded(err):
+		setAzureAccessNotReady(privateLinksScope, AzureQuotaExceededReason, err)
+		return ctrl.Result{}, reconcile.TerminalError(err)
+	default:
+		return ctrl.Result{}, microerror.Mask(err)
 	}
-	return []capz.PrivateEndpointSpec{gatewaySpec}
 }
 
-// validateLBType checks if the load balancer type is either Internal or Public. Any
-// other load balancer type (e.g. potentially added in the future) is considered an error here.
-func validateLBType(azureCluster capz.AzureCluster) error {
-	if azureCluster.Spec.NetworkSpec.APIServerLB.Type != capz.Internal &&
-		azureCluster.Spec.NetworkSpec.APIServerLB.Type != capz.Public {
-		return microerror.Maskf(
-			errors.UnknownLoadBalancerTypeError,
-			"expected that load balancer type is %s or %s, got %s in cluster %s",
-			capz.Internal,
-			capz.Public,
-			azureCluster.Spec.NetworkSpec.APIServerLB.Type,
-			azureCluster.Name)
-	}
-	return nil
+// resetRetryBackoff resets the backoff of the workload cluster, so that the next retriable error
+// is retried after the initial delay again.
+func (r *AzureClusterReconciler) resetRetryBackoff(clusterName types.NamespacedName) {
+	r.retryBackoff.Forget(clusterName)
+	metrics.ResetRetriableErrorAttempts(clusterName)
+}
+
+func setAzureAccessNotReady(privateLinksScope *privatelinks.Scope, reason string, err error) {
+	privateLinksScope.SetCondition(capi.Condition{
+		Type:               ConditionGSAzureAccessReady,
+		Status:             corev1.ConditionFalse,
+		Severity:           capi.ConditionSeverityError,
+		Reason:             reason,
+		Message:            microerror.Pretty(err, false),
+		LastTransitionTime: metav1.Now(),
+	})
 }
 
 func (r *AzureClusterReconciler) setFinalizer(workloadCluster *capz.AzureCluster) {
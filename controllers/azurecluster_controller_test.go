@@ -2,7 +2,9 @@ package controllers_test
 
 import (
 	"context"
+	goerrors "errors"
 	"fmt"
+	"net/http"
 	"time"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -12,16 +14,22 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	k8sevents "k8s.io/client-go/tools/events"
 	capz "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
 	capi "sigs.k8s.io/cluster-api/api/core/v1beta1"
+	v1beta1conditions "sigs.k8s.io/cluster-api/util/deprecated/v1beta1/conditions"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/giantswarm/microerror"
 
 	"github.com/giantswarm/azure-private-endpoint-operator/controllers"
 	"github.com/giantswarm/azure-private-endpoint-operator/pkg/azure"
 	"github.com/giantswarm/azure-private-endpoint-operator/pkg/azure/mock_azure"
 	"github.com/giantswarm/azure-private-endpoint-operator/pkg/errors"
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/events"
 	"github.com/giantswarm/azure-private-endpoint-operator/pkg/privateendpoints"
 	"github.com/giantswarm/azure-private-endpoint-operator/pkg/privatelinks"
 	"github.com/giantswarm/azure-private-endpoint-operator/pkg/testhelpers"
@@ -46,6 +54,7 @@ var _ = Describe("AzureClusterReconciler", func() {
 	var workloadAzureCluster *capz.AzureCluster
 	var k8sClient client.Client
 	var privateEndpointsClientCreator azure.PrivateEndpointsClientCreator
+	var recorder *events.Recorder
 	var reconciler *controllers.AzureClusterReconciler
 	var scheme *runtime.Scheme
 
@@ -74,6 +83,10 @@ var _ = Describe("AzureClusterReconciler", func() {
 			gomockController := gomock.NewController(GinkgoT())
 			return mock_azure.NewMockPrivateEndpointsClient(gomockController), nil
 		}
+
+		var err error
+		recorder, err = events.NewRecorder(k8sevents.NewFakeRecorder(100), events.DefaultDeduplicationWindow)
+		Expect(err).NotTo(HaveOccurred())
 	})
 
 	JustBeforeEach(func() {
@@ -99,21 +112,28 @@ var _ = Describe("AzureClusterReconciler", func() {
 	Describe("creating reconciler", func() {
 		It("creates reconciler", func(ctx context.Context) {
 			var err error
-			reconciler, err = controllers.NewAzureClusterReconciler(k8sClient, privateEndpointsClientCreator, managementClusterNamespacedName, controllers.Options{})
+			reconciler, err = controllers.NewAzureClusterReconciler(k8sClient, privateEndpointsClientCreator, recorder, managementClusterNamespacedName, controllers.Options{})
 			Expect(err).NotTo(HaveOccurred())
 			Expect(reconciler).NotTo(BeNil())
 		})
 
 		It("fails to create reconciler when client is nil", func(ctx context.Context) {
 			var err error
-			_, err = controllers.NewAzureClusterReconciler(nil, privateEndpointsClientCreator, managementClusterNamespacedName, controllers.Options{})
+			_, err = controllers.NewAzureClusterReconciler(nil, privateEndpointsClientCreator, recorder, managementClusterNamespacedName, controllers.Options{})
 			Expect(err).To(HaveOccurred())
 			Expect(errors.IsInvalidConfig(err)).To(BeTrue())
 		})
 
 		It("fails to create reconciler when private endpoints creator is nil", func(ctx context.Context) {
 			var err error
-			_, err = controllers.NewAzureClusterReconciler(k8sClient, nil, managementClusterNamespacedName, controllers.Options{})
+			_, err = controllers.NewAzureClusterReconciler(k8sClient, nil, recorder, managementClusterNamespacedName, controllers.Options{})
+			Expect(err).To(HaveOccurred())
+			Expect(errors.IsInvalidConfig(err)).To(BeTrue())
+		})
+
+		It("fails to create reconciler when event recorder is nil", func(ctx context.Context) {
+			var err error
+			_, err = controllers.NewAzureClusterReconciler(k8sClient, privateEndpointsClientCreator, nil, managementClusterNamespacedName, controllers.Options{})
 			Expect(err).To(HaveOccurred())
 			Expect(errors.IsInvalidConfig(err)).To(BeTrue())
 		})
@@ -121,7 +141,7 @@ var _ = Describe("AzureClusterReconciler", func() {
 		It("fails to create reconciler when MC name is empty", func(ctx context.Context) {
 			var err error
 			managementClusterNamespacedName.Name = ""
-			_, err = controllers.NewAzureClusterReconciler(k8sClient, privateEndpointsClientCreator, managementClusterNamespacedName, controllers.Options{})
+			_, err = controllers.NewAzureClusterReconciler(k8sClient, privateEndpointsClientCreator, recorder, managementClusterNamespacedName, controllers.Options{})
 			Expect(err).To(HaveOccurred())
 			Expect(errors.IsInvalidConfig(err)).To(BeTrue())
 		})
@@ -129,7 +149,16 @@ var _ = Describe("AzureClusterReconciler", func() {
 		It("fails to create reconciler when MC namespace is empty", func(ctx context.Context) {
 			var err error
 			managementClusterNamespacedName.Namespace = ""
-			_, err = controllers.NewAzureClusterReconciler(k8sClient, privateEndpointsClientCreator, managementClusterNamespacedName, controllers.Options{})
+			_, err = controllers.NewAzureClusterReconciler(k8sClient, privateEndpointsClientCreator, recorder, managementClusterNamespacedName, controllers.Options{})
+			Expect(err).To(HaveOccurred())
+			Expect(errors.IsInvalidConfig(err)).To(BeTrue())
+		})
+
+		It("fails to create reconciler when retry max delay is less than initial delay", func(ctx context.Context) {
+			_, err := controllers.NewAzureClusterReconciler(k8sClient, privateEndpointsClientCreator, recorder, managementClusterNamespacedName, controllers.Options{
+				RetryInitialDelay: time.Minute,
+				RetryMaxDelay:     time.Second,
+			})
 			Expect(err).To(HaveOccurred())
 			Expect(errors.IsInvalidConfig(err)).To(BeTrue())
 		})
@@ -139,7 +168,7 @@ var _ = Describe("AzureClusterReconciler", func() {
 		When("workload AzureCluster resources does not exist", func() {
 			JustBeforeEach(func() {
 				var err error
-				reconciler, err = controllers.NewAzureClusterReconciler(k8sClient, privateEndpointsClientCreator, managementClusterNamespacedName, controllers.Options{})
+				reconciler, err = controllers.NewAzureClusterReconciler(k8sClient, privateEndpointsClientCreator, recorder, managementClusterNamespacedName, controllers.Options{})
 				Expect(err).NotTo(HaveOccurred())
 			})
 
@@ -167,7 +196,7 @@ var _ = Describe("AzureClusterReconciler", func() {
 
 			JustBeforeEach(func() {
 				var err error
-				reconciler, err = controllers.NewAzureClusterReconciler(k8sClient, privateEndpointsClientCreator, managementClusterNamespacedName, controllers.Options{})
+				reconciler, err = controllers.NewAzureClusterReconciler(k8sClient, privateEndpointsClientCreator, recorder, managementClusterNamespacedName, controllers.Options{})
 				Expect(err).NotTo(HaveOccurred())
 			})
 
@@ -201,7 +230,7 @@ var _ = Describe("AzureClusterReconciler", func() {
 
 			JustBeforeEach(func() {
 				var err error
-				reconciler, err = controllers.NewAzureClusterReconciler(k8sClient, privateEndpointsClientCreator, managementClusterNamespacedName, controllers.Options{})
+				reconciler, err = controllers.NewAzureClusterReconciler(k8sClient, privateEndpointsClientCreator, recorder, managementClusterNamespacedName, controllers.Options{})
 				Expect(err).NotTo(HaveOccurred())
 			})
 
@@ -229,7 +258,7 @@ var _ = Describe("AzureClusterReconciler", func() {
 
 			JustBeforeEach(func() {
 				var err error
-				reconciler, err = controllers.NewAzureClusterReconciler(k8sClient, privateEndpointsClientCreator, managementClusterNamespacedName, controllers.Options{})
+				reconciler, err = controllers.NewAzureClusterReconciler(k8sClient, privateEndpointsClientCreator, recorder, managementClusterNamespacedName, controllers.Options{})
 				Expect(err).NotTo(HaveOccurred())
 			})
 
@@ -296,7 +325,7 @@ var _ = Describe("AzureClusterReconciler", func() {
 
 		JustBeforeEach(func() {
 			var err error
-			reconciler, err = controllers.NewAzureClusterReconciler(k8sClient, privateEndpointsClientCreator, managementClusterNamespacedName, controllers.Options{})
+			reconciler, err = controllers.NewAzureClusterReconciler(k8sClient, privateEndpointsClientCreator, recorder, managementClusterNamespacedName, controllers.Options{})
 			Expect(err).NotTo(HaveOccurred())
 		})
 
@@ -329,7 +358,7 @@ var _ = Describe("AzureClusterReconciler", func() {
 			var result ctrl.Result
 			result, err = reconciler.Reconcile(ctx, workloadClusterRequest)
 			Expect(err).NotTo(HaveOccurred())
-			Expect(result).To(Equal(ctrl.Result{RequeueAfter: time.Minute}))
+			Expect(result).To(Equal(ctrl.Result{RequeueAfter: controllers.DefaultRetryInitialDelay}))
 
 			// get updated management AzureCluster
 			err = k8sClient.Get(ctx, managementClusterNamespacedName, managementAzureCluster)
@@ -421,7 +450,7 @@ var _ = Describe("AzureClusterReconciler", func() {
 
 		JustBeforeEach(func() {
 			var err error
-			reconciler, err = controllers.NewAzureClusterReconciler(k8sClient, privateEndpointsClientCreator, managementClusterNamespacedName, controllers.Options{})
+			reconciler, err = controllers.NewAzureClusterReconciler(k8sClient, privateEndpointsClientCreator, recorder, managementClusterNamespacedName, controllers.Options{})
 			Expect(err).NotTo(HaveOccurred())
 		})
 
@@ -519,7 +548,7 @@ var _ = Describe("AzureClusterReconciler", func() {
 
 		JustBeforeEach(func() {
 			var err error
-			reconciler, err = controllers.NewAzureClusterReconciler(k8sClient, privateEndpointsClientCreator, managementClusterNamespacedName, controllers.Options{})
+			reconciler, err = controllers.NewAzureClusterReconciler(k8sClient, privateEndpointsClientCreator, recorder, managementClusterNamespacedName, controllers.Options{})
 			Expect(err).NotTo(HaveOccurred())
 		})
 
@@ -543,17 +572,17 @@ var _ = Describe("AzureClusterReconciler", func() {
 		})
 	})
 
-	Describe("scenarios where reconciliation is requeued after a minute", func() {
-		var expectedResultRequeueAfterMinute ctrl.Result
+	Describe("scenarios where reconciliation is requeued with backoff", func() {
+		var expectedResultRequeueAfterInitialDelay ctrl.Result
 		BeforeEach(func() {
-			expectedResultRequeueAfterMinute = ctrl.Result{
-				RequeueAfter: time.Minute,
+			expectedResultRequeueAfterInitialDelay = ctrl.Result{
+				RequeueAfter: controllers.DefaultRetryInitialDelay,
 			}
 		})
 
 		JustBeforeEach(func() {
 			var err error
-			reconciler, err = controllers.NewAzureClusterReconciler(k8sClient, privateEndpointsClientCreator, managementClusterNamespacedName, controllers.Options{})
+			reconciler, err = controllers.NewAzureClusterReconciler(k8sClient, privateEndpointsClientCreator, recorder, managementClusterNamespacedName, controllers.Options{})
 			Expect(err).NotTo(HaveOccurred())
 		})
 
@@ -583,10 +612,56 @@ var _ = Describe("AzureClusterReconciler", func() {
 					Build()
 			})
 
-			It("will requeue reconciliation after 1 minute", func(ctx context.Context) {
+			It("will requeue reconciliation after the initial delay", func(ctx context.Context) {
 				result, err := reconciler.Reconcile(ctx, workloadClusterRequest)
 				Expect(err).NotTo(HaveOccurred())
-				Expect(result).To(Equal(expectedResultRequeueAfterMinute))
+				Expect(result).To(Equal(expectedResultRequeueAfterInitialDelay))
+			})
+
+			It("doubles the delay with every retry up to the maximum delay", func(ctx context.Context) {
+				var err error
+				reconciler, err = controllers.NewAzureClusterReconciler(k8sClient, privateEndpointsClientCreator, recorder, managementClusterNamespacedName, controllers.Options{
+					RetryInitialDelay: 10 * time.Second,
+					RetryMaxDelay:     30 * time.Second,
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				for _, expectedRequeueAfter := range []time.Duration{10 * time.Second, 20 * time.Second, 30 * time.Second, 30 * time.Second} {
+					result, err := reconciler.Reconcile(ctx, workloadClusterRequest)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(result).To(Equal(ctrl.Result{RequeueAfter: expectedRequeueAfter}))
+				}
+			})
+
+			It("resets the delay after the cluster is reconciled successfully", func(ctx context.Context) {
+				var err error
+				reconciler, err = controllers.NewAzureClusterReconciler(k8sClient, privateEndpointsClientCreator, recorder, managementClusterNamespacedName, controllers.Options{
+					RetryInitialDelay: 10 * time.Second,
+					RetryMaxDelay:     30 * time.Second,
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				for _, expectedRequeueAfter := range []time.Duration{10 * time.Second, 20 * time.Second} {
+					result, err := reconciler.Reconcile(ctx, workloadClusterRequest)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(result).To(Equal(ctrl.Result{RequeueAfter: expectedRequeueAfter}))
+				}
+
+				// the cluster is reconciled successfully once it has no private links to connect to
+				setAPIServerLBType := func(lbType capz.LBType) {
+					Expect(k8sClient.Get(ctx, workloadClusterNamespacedName, workloadAzureCluster)).To(Succeed())
+					workloadAzureCluster.Spec.NetworkSpec.APIServerLB.Type = lbType
+					Expect(k8sClient.Update(ctx, workloadAzureCluster)).To(Succeed())
+				}
+				setAPIServerLBType(capz.Public)
+				result, err := reconciler.Reconcile(ctx, workloadClusterRequest)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result).To(Equal(ctrl.Result{}))
+
+				setAPIServerLBType(capz.Internal)
+				result, err = reconciler.Reconcile(ctx, workloadClusterRequest)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result).To(Equal(ctrl.Result{RequeueAfter: 10 * time.Second}))
 			})
 		})
 
@@ -631,10 +706,10 @@ var _ = Describe("AzureClusterReconciler", func() {
 				}
 			})
 
-			It("will requeue reconciliation after 1 minute", func(ctx context.Context) {
+			It("will requeue reconciliation after the initial delay", func(ctx context.Context) {
 				result, err := reconciler.Reconcile(ctx, workloadClusterRequest)
 				Expect(err).NotTo(HaveOccurred())
-				Expect(result).To(Equal(expectedResultRequeueAfterMinute))
+				Expect(result).To(Equal(expectedResultRequeueAfterInitialDelay))
 			})
 		})
 
@@ -678,10 +753,10 @@ var _ = Describe("AzureClusterReconciler", func() {
 				}
 			})
 
-			It("will requeue reconciliation after 1 minute", func(ctx context.Context) {
+			It("will requeue reconciliation after the initial delay", func(ctx context.Context) {
 				result, err := reconciler.Reconcile(ctx, workloadClusterRequest)
 				Expect(err).NotTo(HaveOccurred())
-				Expect(result).To(Equal(expectedResultRequeueAfterMinute))
+				Expect(result).To(Equal(expectedResultRequeueAfterInitialDelay))
 			})
 		})
 
@@ -728,10 +803,10 @@ var _ = Describe("AzureClusterReconciler", func() {
 				}
 			})
 
-			It("will requeue reconciliation after 1 minute", func(ctx context.Context) {
+			It("will requeue reconciliation after the initial delay", func(ctx context.Context) {
 				result, err := reconciler.Reconcile(ctx, workloadClusterRequest)
 				Expect(err).NotTo(HaveOccurred())
-				Expect(result).To(Equal(expectedResultRequeueAfterMinute))
+				Expect(result).To(Equal(expectedResultRequeueAfterInitialDelay))
 			})
 		})
 
@@ -776,10 +851,167 @@ var _ = Describe("AzureClusterReconciler", func() {
 				}
 			})
 
-			It("will requeue reconciliation after 1 minute", func(ctx context.Context) {
+			It("will requeue reconciliation after the initial delay", func(ctx context.Context) {
+				result, err := reconciler.Reconcile(ctx, workloadClusterRequest)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result).To(Equal(expectedResultRequeueAfterInitialDelay))
+			})
+		})
+	})
+
+	Describe("scenarios where Azure API returns an error", func() {
+		var expectedPrivateEndpointName string
+		var statusCode int
+		var errorCode string
+		var header http.Header
+
+		BeforeEach(func() {
+			expectedPrivateEndpointName = fmt.Sprintf("%s-privateendpoint", testPrivateLinkNameForWcAPI)
+			errorCode = ""
+			header = http.Header{}
+
+			managementAzureCluster = testhelpers.NewAzureClusterBuilder("org-giantswarm", managementClusterNamespacedName.Name).
+				WithSubscriptionID(subscriptionID).
+				WithResourceGroup(managementClusterNamespacedName.Name).
+				WithLocation(location).
+				WithSubnet("test-subnet", capz.SubnetNode, nil).
+				WithAPILoadBalancerType(capz.Public).
+				Build()
+
+			workloadAzureCluster = testhelpers.NewAzureClusterBuilder("org-giantswarm", workloadClusterName).
+				WithSubscriptionID(subscriptionID).
+				WithResourceGroup(workloadClusterName).
+				WithAPILoadBalancerType(capz.Internal).
+				WithSubnet("test-subnet", capz.SubnetNode, nil).
+				WithPrivateLink(testhelpers.NewPrivateLinkBuilder(testPrivateLinkNameForWcAPI).
+					WithAllowedSubscription(subscriptionID).
+					WithAutoApprovedSubscription(subscriptionID).
+					Build()).
+				WithCondition(&capi.Condition{
+					Type:   capz.PrivateLinksReadyCondition,
+					Status: corev1.ConditionTrue,
+				}).
+				Build()
+
+			privateEndpointsClientCreator = func(_ context.Context, _ client.Client, cluster *capz.AzureCluster) (azure.PrivateEndpointsClient, error) {
+				gomockController := gomock.NewController(GinkgoT())
+				privateEndpointsClient := mock_azure.NewMockPrivateEndpointsClient(gomockController)
+				if cluster.Name == managementClusterName {
+					testhelpers.SetupPrivateEndpointClientToReturnError(
+						privateEndpointsClient,
+						managementClusterNamespacedName.Name,
+						expectedPrivateEndpointName,
+						statusCode,
+						errorCode,
+						header)
+				}
+				return privateEndpointsClient, nil
+			}
+		})
+
+		JustBeforeEach(func() {
+			var err error
+			reconciler, err = controllers.NewAzureClusterReconciler(k8sClient, privateEndpointsClientCreator, recorder, managementClusterNamespacedName, controllers.Options{})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		When("Azure API request is throttled with Retry-After header", func() {
+			BeforeEach(func() {
+				statusCode = http.StatusTooManyRequests
+				header.Set("Retry-After", "42")
+			})
+
+			It("will requeue reconciliation after Retry-After duration", func(ctx context.Context) {
 				result, err := reconciler.Reconcile(ctx, workloadClusterRequest)
 				Expect(err).NotTo(HaveOccurred())
-				Expect(result).To(Equal(expectedResultRequeueAfterMinute))
+				Expect(result).To(Equal(ctrl.Result{RequeueAfter: 42 * time.Second}))
+			})
+		})
+
+		When("Azure API request is throttled without Retry-After header", func() {
+			BeforeEach(func() {
+				statusCode = http.StatusTooManyRequests
+			})
+
+			It("returns the error, so reconciliation is requeued with exponential backoff", func(ctx context.Context) {
+				_, err := reconciler.Reconcile(ctx, workloadClusterRequest)
+				Expect(errors.IsAzureThrottled(err)).To(BeTrue())
+				Expect(goerrors.Is(err, reconcile.TerminalError(nil))).To(BeFalse())
+			})
+		})
+
+		When("Azure API returns a transient error", func() {
+			BeforeEach(func() {
+				statusCode = http.StatusServiceUnavailable
+			})
+
+			It("returns the error, so reconciliation is requeued with exponential backoff", func(ctx context.Context) {
+				_, err := reconciler.Reconcile(ctx, workloadClusterRequest)
+				Expect(errors.IsAzureTransient(err)).To(BeTrue())
+				Expect(goerrors.Is(err, reconcile.TerminalError(nil))).To(BeFalse())
+			})
+		})
+
+		When("Azure API returns an authorization failure", func() {
+			BeforeEach(func() {
+				statusCode = http.StatusForbidden
+				errorCode = "AuthorizationFailed"
+			})
+
+			It("returns a terminal error and sets the GSAzureAccessReady condition to False", func(ctx context.Context) {
+				_, err := reconciler.Reconcile(ctx, workloadClusterRequest)
+				Expect(errors.IsAzureAuthFailure(err)).To(BeTrue())
+				Expect(goerrors.Is(err, reconcile.TerminalError(nil))).To(BeTrue())
+
+				updatedWorkloadAzureCluster := &capz.AzureCluster{}
+				err = k8sClient.Get(ctx, workloadClusterNamespacedName, updatedWorkloadAzureCluster)
+				Expect(err).NotTo(HaveOccurred())
+				condition := v1beta1conditions.Get(updatedWorkloadAzureCluster, controllers.ConditionGSAzureAccessReady)
+				Expect(condition).NotTo(BeNil())
+				Expect(condition.Status).To(Equal(corev1.ConditionFalse))
+				Expect(condition.Reason).To(Equal(controllers.AzureAuthFailureReason))
+			})
+		})
+
+		When("AzureClusterIdentity does not allow the namespace of the AzureCluster", func() {
+			BeforeEach(func() {
+				privateEndpointsClientCreator = func(context.Context, client.Client, *capz.AzureCluster) (azure.PrivateEndpointsClient, error) {
+					return nil, microerror.Maskf(errors.AzureClusterIdentityNotAllowedError, "not allowed")
+				}
+			})
+
+			It("returns a terminal error and sets the GSAzureAccessReady condition to False", func(ctx context.Context) {
+				_, err := reconciler.Reconcile(ctx, workloadClusterRequest)
+				Expect(errors.IsAzureClusterIdentityNotAllowed(err)).To(BeTrue())
+				Expect(goerrors.Is(err, reconcile.TerminalError(nil))).To(BeTrue())
+
+				updatedWorkloadAzureCluster := &capz.AzureCluster{}
+				err = k8sClient.Get(ctx, workloadClusterNamespacedName, updatedWorkloadAzureCluster)
+				Expect(err).NotTo(HaveOccurred())
+				condition := v1beta1conditions.Get(updatedWorkloadAzureCluster, controllers.ConditionGSAzureAccessReady)
+				Expect(condition).NotTo(BeNil())
+				Expect(condition.Status).To(Equal(corev1.ConditionFalse))
+				Expect(condition.Reason).To(Equal(controllers.AzureClusterIdentityNotAllowedReason))
+			})
+		})
+
+		When("Azure API returns a quota exceeded error", func() {
+			BeforeEach(func() {
+				statusCode = http.StatusConflict
+				errorCode = "QuotaExceeded"
+			})
+
+			It("returns a terminal error and sets the GSAzureAccessReady condition to False", func(ctx context.Context) {
+				_, err := reconciler.Reconcile(ctx, workloadClusterRequest)
+				Expect(errors.IsAzureQuotaExceeded(err)).To(BeTrue())
+				Expect(goerrors.Is(err, reconcile.TerminalError(nil))).To(BeTrue())
+
+				updatedWorkloadAzureCluster := &capz.AzureCluster{}
+				err = k8sClient.Get(ctx, workloadClusterNamespacedName, updatedWorkloadAzureCluster)
+				Expect(err).NotTo(HaveOccurred())
+				condition := v1beta1conditions.Get(updatedWorkloadAzureCluster, controllers.ConditionGSAzureAccessReady)
+				Expect(condition).NotTo(BeNil())
+				Expect(condition.Reason).To(Equal(controllers.AzureQuotaExceededReason))
 			})
 		})
 	})
@@ -0,0 +1,435 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	capz "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	kcp "sigs.k8s.io/cluster-api/api/controlplane/kubeadm/v1beta2"
+	capiv1beta1 "sigs.k8s.io/cluster-api/api/core/v1beta1"
+	capi "sigs.k8s.io/cluster-api/api/core/v1beta2"
+	caputil "sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/patch"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/util"
+)
+
+var (
+	ErrReconcileCancelled                = errors.New("reconciliation cancelled")
+	ErrReasonManagementClusterNotPrivate = errors.New("management cluster is not private")
+	ErrReasonControlPlaneDeleted         = errors.New("control plane has been deleted")
+	ErrReasonControlPlaneDeleting        = errors.New("control plane is being deleted")
+	ErrReasonControlPlaneProvisioned     = errors.New("control plane is provisioned")
+	ErrReasonControlPlaneHasNoOwner      = errors.New("control plane does not yet have an owner")
+	ErrReasonClusterPaused               = errors.New("owning cluster is paused")
+	ErrReasonInfraClusterMissing         = errors.New("owning cluster has no infrastructure ref")
+)
+
+var (
+	// KubeadmControlPlaneKind is the control plane that is gated by default.
+	KubeadmControlPlaneKind = schema.GroupKind{
+		Group: kcp.GroupVersion.Group,
+		Kind:  "KubeadmControlPlane",
+	}
+	// AzureClusterKind is the infrastructure cluster that carries the gate conditions by default.
+	AzureClusterKind = schema.GroupKind{
+		Group: capz.GroupVersion.Group,
+		Kind:  capz.AzureClusterKind,
+	}
+)
+
+type ControlPlaneReconcilerOptions struct {
+	// AzureClusterGates are the status conditions of the infrastructure cluster that must be true
+	// before the control plane is unpaused. Without gates, no control plane is paused.
+	AzureClusterGates []capiv1beta1.ConditionType
+	// ControlPlaneKind is the kind of the control planes that are gated. Defaults to
+	// KubeadmControlPlaneKind.
+	ControlPlaneKind schema.GroupKind
+	// InfrastructureClusterKind is the kind of the infrastructure clusters that carry the gate
+	// conditions. Control planes of Clusters with infrastructure clusters of other kinds are not
+	// paused, as nothing would ever set the conditions on them. Defaults to AzureClusterKind.
+	InfrastructureClusterKind schema.GroupKind
+}
+
+func NewControlPlaneReconciler(client client.Client, managmentCluster types.NamespacedName, opts *ControlPlaneReconcilerOptions) (*ControlPlaneReconciler, error) {
+	if client == nil {
+		return nil, errors.New("failed to build reconciler: client is nil")
+	}
+
+	if managmentCluster.Name == "" {
+		return nil, errors.New("management cluster name must be set")
+	}
+
+	if managmentCluster.Namespace == "" {
+		return nil, errors.New("management cluster namespace must be set")
+	}
+
+	r := &ControlPlaneReconciler{
+		client:                    client,
+		managementCluster:         managmentCluster,
+		controlPlaneKind:          KubeadmControlPlaneKind,
+		infrastructureClusterKind: AzureClusterKind,
+	}
+
+	if opts != nil {
+		r.azureClusterGates = opts.AzureClusterGates
+		if !opts.ControlPlaneKind.Empty() {
+			r.controlPlaneKind = opts.ControlPlaneKind
+		}
+		if !opts.InfrastructureClusterKind.Empty() {
+			r.infrastructureClusterKind = opts.InfrastructureClusterKind
+		}
+	}
+
+	if r.controlPlaneKind.Kind == "" {
+		return nil, fmt.Errorf("control plane kind of group %q must be set", r.controlPlaneKind.Group)
+	}
+
+	if r.infrastructureClusterKind.Kind == "" {
+		return nil, fmt.Errorf("infrastructure cluster kind of group %q must be set", r.infrastructureClusterKind.Group)
+	}
+
+	return r, nil
+}
+
+// ControlPlaneReconciler pauses or unpauses reconciliation of a cluster's control plane based on
+// the status conditions of its infrastructure cluster. A new control plane will be automatically
+// paused, and will remain so until all status conditions pass.
+//
+// It works on the control planes of one kind, e.g. KubeadmControlPlane or
+// AzureASOManagedControlPlane, as unstructured objects, so that any CAPI control plane provider
+// that respects the CAPI paused annotation can be gated.
+type ControlPlaneReconciler struct {
+	client                    client.Client
+	managementCluster         types.NamespacedName
+	azureClusterGates         []capiv1beta1.ConditionType
+	controlPlaneKind          schema.GroupKind
+	infrastructureClusterKind schema.GroupKind
+}
+
+// Reconcile the control plane to ensure that its associated InfraCluster has passed specific
+// status conditions. As long as these conditions are not met, the control plane is paused.
+func (r *ControlPlaneReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	if len(r.azureClusterGates) == 0 {
+		// No gates, so no checks to perform.
+		return
+	}
+
+	logger := log.FromContext(ctx).WithValues("controlplane", req.NamespacedName)
+
+	logger.Info("starting reconciliation")
+	defer logger.Info("finished reconciliation")
+
+	managementInfraCluster := new(capz.AzureCluster)
+	err = r.client.Get(ctx, r.managementCluster, managementInfraCluster)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			logger.Info("management cluster has been deleted")
+			return result, nil
+		}
+		return
+	}
+
+	if err = r.PreflightCheckManagementCluster(ctx, managementInfraCluster); err != nil {
+		if errors.Is(err, ErrReconcileCancelled) {
+			logger.Info(err.Error())
+			return result, nil
+		}
+		return
+	}
+
+	controlPlane, err := r.get(ctx, r.controlPlaneKind, req.NamespacedName)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			logger.Info("control plane has been deleted")
+			return result, nil
+		}
+		return
+	}
+
+	if err = r.PreflightCheckControlPlane(ctx, controlPlane); err != nil {
+		if errors.Is(err, ErrReconcileCancelled) {
+			logger.Info(err.Error())
+			return result, nil
+		}
+		return
+	}
+
+	cluster, err := caputil.GetOwnerCluster(ctx, r.client, metav1.ObjectMeta{
+		Namespace:       controlPlane.GetNamespace(),
+		OwnerReferences: controlPlane.GetOwnerReferences(),
+	})
+	if err != nil {
+		return
+	}
+
+	if err = r.PreflightCheckCluster(ctx, cluster); err != nil {
+		if errors.Is(err, ErrReconcileCancelled) {
+			logger.Info(err.Error())
+			return result, nil
+		}
+		return
+	}
+
+	// The gate conditions are only set on infrastructure clusters of one kind, e.g. by this
+	// operator on AzureClusters, so the control planes of other infrastructure clusters are not
+	// gated.
+	var unmet []capiv1beta1.ConditionType
+	infraClusterKind := schema.GroupKind{
+		Group: cluster.Spec.InfrastructureRef.APIGroup,
+		Kind:  cluster.Spec.InfrastructureRef.Kind,
+	}
+	if infraClusterKind == r.infrastructureClusterKind {
+		var infraCluster *unstructured.Unstructured
+		infraCluster, err = r.get(ctx, infraClusterKind, types.NamespacedName{
+			Namespace: req.Namespace,
+			Name:      cluster.Spec.InfrastructureRef.Name,
+		})
+		if err != nil {
+			return
+		}
+		unmet = util.FindUnmetStatusConditions(statusConditions(infraCluster), r.azureClusterGates)
+	} else {
+		logger.Info("not gating control plane of infrastructure cluster without gate conditions", "kind", infraClusterKind)
+	}
+
+	helper, err := patch.NewHelper(controlPlane, r.client)
+	if err != nil {
+		return result, err
+	}
+	defer func() {
+		if err := helper.Patch(ctx, controlPlane); err != nil {
+			logger.Error(err, "failed to patch control plane", "kind", r.controlPlaneKind)
+		}
+	}()
+
+	if len(unmet) != 0 {
+		logger.Info("pausing control plane because infrastructure cluster conditions were not met", "conditions", unmet)
+		annotations := controlPlane.GetAnnotations()
+		if annotations == nil {
+			annotations = make(map[string]string)
+		}
+		annotations[capi.PausedAnnotation] = "true"
+		controlPlane.SetAnnotations(annotations)
+		return
+	}
+
+	annotations := controlPlane.GetAnnotations()
+	if _, ok := annotations[capi.PausedAnnotation]; ok {
+		logger.Info("unpausing control plane because all infrastructure cluster conditions were met")
+		delete(annotations, capi.PausedAnnotation)
+		controlPlane.SetAnnotations(annotations)
+	}
+	return
+}
+
+// get returns the object of the kind in the preferred version of the API server.
+func (r *ControlPlaneReconciler) get(ctx context.Context, gk schema.GroupKind, name types.NamespacedName) (*unstructured.Unstructured, error) {
+	mapping, err := r.client.RESTMapper().RESTMapping(gk)
+	if err != nil {
+		return nil, err
+	}
+
+	obj := new(unstructured.Unstructured)
+	obj.SetGroupVersionKind(mapping.GroupVersionKind)
+	if err = r.client.Get(ctx, name, obj); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+func (r *ControlPlaneReconciler) PreflightCheckManagementCluster(ctx context.Context, azureCluster *capz.AzureCluster) error {
+	if azureCluster.Spec.NetworkSpec.APIServerLB.Type != capz.Internal {
+		return fmt.Errorf("%w: %w", ErrReconcileCancelled, ErrReasonManagementClusterNotPrivate)
+	}
+
+	return nil
+}
+
+// PreflightCheckControlPlane asserts that it is safe to proceed reconciling the control plane.
+func (r *ControlPlaneReconciler) PreflightCheckControlPlane(ctx context.Context, controlPlane *unstructured.Unstructured) error {
+	if controlPlaneInitialized(controlPlane) {
+		return fmt.Errorf("%w: %w", ErrReconcileCancelled, ErrReasonControlPlaneProvisioned)
+	}
+
+	// Normally when an object is being deleted, a reconciler goes into a deletion reconcile loop.
+	// But as of writing, this reconciler only pauses or unpauses the control plane.
+	// It is not involved at all in deletion.
+	if !controlPlane.GetDeletionTimestamp().IsZero() {
+		return fmt.Errorf("%w: %w", ErrReconcileCancelled, ErrReasonControlPlaneDeleting)
+	}
+
+	if !controllerutil.HasControllerReference(controlPlane) {
+		return fmt.Errorf("%w: %w", ErrReconcileCancelled, ErrReasonControlPlaneHasNoOwner)
+	}
+
+	return nil
+}
+
+func (r *ControlPlaneReconciler) PreflightCheckCluster(ctx context.Context, cluster *capi.Cluster) error {
+	// If the Cluster is paused, then we should not, in any circumstance, unpause the control plane.
+	if cluster.Spec.Paused != nil &&
+		*cluster.Spec.Paused {
+		return fmt.Errorf("%w: %w", ErrReconcileCancelled, ErrReasonClusterPaused)
+	}
+
+	if !cluster.Spec.InfrastructureRef.IsDefined() {
+		return fmt.Errorf("%w: %w", ErrReconcileCancelled, ErrReasonInfraClusterMissing)
+	}
+
+	return nil
+}
+
+// SetupWithManager watches the control planes and the infrastructure clusters of the kinds in the
+// preferred versions of the API server, so the CRDs of the kinds must be installed.
+func (r *ControlPlaneReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	mapping, err := mgr.GetRESTMapper().RESTMapping(r.controlPlaneKind)
+	if err != nil {
+		return fmt.Errorf("failed to find control plane kind %s: %w", r.controlPlaneKind, err)
+	}
+	controlPlane := new(unstructured.Unstructured)
+	controlPlane.SetGroupVersionKind(mapping.GroupVersionKind)
+
+	mapping, err = mgr.GetRESTMapper().RESTMapping(r.infrastructureClusterKind)
+	if err != nil {
+		return fmt.Errorf("failed to find infrastructure cluster kind %s: %w", r.infrastructureClusterKind, err)
+	}
+	infraCluster, err := newObject(mgr.GetScheme(), mapping.GroupVersionKind)
+	if err != nil {
+		return err
+	}
+
+	// Only watch for changes in status conditions.
+	infraClusterChanged := predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			old, err := toUnstructured(e.ObjectOld)
+			if err != nil {
+				return false
+			}
+			new, err := toUnstructured(e.ObjectNew)
+			if err != nil {
+				return false
+			}
+			return !reflect.DeepEqual(statusConditions(old), statusConditions(new))
+		},
+		CreateFunc:  func(e event.CreateEvent) bool { return false },
+		DeleteFunc:  func(e event.DeleteEvent) bool { return false },
+		GenericFunc: func(e event.GenericEvent) bool { return false },
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(strings.ToLower(r.controlPlaneKind.Kind)).
+		For(controlPlane).
+		Watches(infraCluster, handler.EnqueueRequestsFromMapFunc(r.ControlPlaneRequestsFor), builder.WithPredicates(infraClusterChanged)).
+		Complete(r)
+}
+
+// ControlPlaneRequestsFor returns the request for the control plane of the Cluster that owns the
+// infrastructure cluster, if the control plane is of the kind of the reconciler.
+func (r *ControlPlaneReconciler) ControlPlaneRequestsFor(ctx context.Context, infraCluster client.Object) []reconcile.Request {
+	logger := log.FromContext(ctx)
+
+	cluster, err := caputil.GetOwnerCluster(ctx, r.client, metav1.ObjectMeta{
+		Namespace:       infraCluster.GetNamespace(),
+		OwnerReferences: infraCluster.GetOwnerReferences(),
+	})
+	if err != nil {
+		logger.Error(err, "while getting owning cluster", "infracluster", infraCluster.GetName())
+		return nil
+	}
+	if cluster == nil {
+		return nil
+	}
+
+	controlPlaneRef := cluster.Spec.ControlPlaneRef
+	if !controlPlaneRef.IsDefined() || controlPlaneRef.APIGroup != r.controlPlaneKind.Group || controlPlaneRef.Kind != r.controlPlaneKind.Kind {
+		return nil
+	}
+
+	return []reconcile.Request{{
+		NamespacedName: types.NamespacedName{
+			Namespace: cluster.Namespace,
+			Name:      controlPlaneRef.Name,
+		},
+	}}
+}
+
+// newObject returns a typed object of the kind when it is registered in the scheme, so that it
+// shares the cache with the other controllers, and an unstructured object otherwise.
+func newObject(scheme *runtime.Scheme, gvk schema.GroupVersionKind) (client.Object, error) {
+	if scheme.Recognizes(gvk) {
+		obj, err := scheme.New(gvk)
+		if err != nil {
+			return nil, err
+		}
+		if obj, ok := obj.(client.Object); ok {
+			return obj, nil
+		}
+	}
+
+	obj := new(unstructured.Unstructured)
+	obj.SetGroupVersionKind(gvk)
+	return obj, nil
+}
+
+// toUnstructured returns the object as unstructured object.
+func toUnstructured(obj client.Object) (*unstructured.Unstructured, error) {
+	if u, ok := obj.(*unstructured.Unstructured); ok {
+		return u, nil
+	}
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+	return &unstructured.Unstructured{Object: content}, nil
+}
+
+// controlPlaneInitialized returns whether the control plane is initialized, following the v1beta2
+// and the v1beta1 CAPI control plane contracts.
+func controlPlaneInitialized(controlPlane *unstructured.Unstructured) bool {
+	if initialized, found, _ := unstructured.NestedBool(controlPlane.Object, "status", "initialization", "controlPlaneInitialized"); found {
+		return initialized
+	}
+	initialized, _, _ := unstructured.NestedBool(controlPlane.Object, "status", "initialized")
+	return initialized
+}
+
+// statusConditions returns the type and the status of the status conditions of the object, which
+// are the same for CAPI v1beta1 conditions and for metav1 conditions.
+func statusConditions(obj *unstructured.Unstructured) capiv1beta1.Conditions {
+	items, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+
+	conditions := make(capiv1beta1.Conditions, 0, len(items))
+	for _, item := range items {
+		condition, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		conditionType, _ := condition["type"].(string)
+		status, _ := condition["status"].(string)
+		conditions = append(conditions, capiv1beta1.Condition{
+			Type:   capiv1beta1.ConditionType(conditionType),
+			Status: corev1.ConditionStatus(status),
+		})
+	}
+	return conditions
+}
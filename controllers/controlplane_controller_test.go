@@ -0,0 +1,456 @@
+package controllers_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta/testrestmapper"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	capz "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	capiv1beta1 "sigs.k8s.io/cluster-api/api/core/v1beta1"
+	capi "sigs.k8s.io/cluster-api/api/core/v1beta2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/giantswarm/azure-private-endpoint-operator/controllers"
+	. "github.com/giantswarm/azure-private-endpoint-operator/pkg/testhelpers"
+)
+
+var _ = Describe("ControlPlaneReconciler", func() {
+	Describe("Constructor", func() {
+		var client client.WithWatch
+		var mcName types.NamespacedName
+
+		BeforeEach(func() {
+			client = fake.NewClientBuilder().Build()
+			mcName = types.NamespacedName{Namespace: "foo", Name: "bar"}
+		})
+
+		It("creates reconciler", func() {
+			reconciler, err := controllers.NewControlPlaneReconciler(client, mcName, nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(reconciler).NotTo(BeNil())
+		})
+
+		It("it fails to create a reconciler when the client is nil", func() {
+			client = nil
+			reconciler, err := controllers.NewControlPlaneReconciler(client, mcName, nil)
+			Expect(err).Should(HaveOccurred())
+			Expect(reconciler).To(BeNil())
+		})
+
+		It("fails to create reconciler when MC name is empty", func(ctx context.Context) {
+			mcName.Name = ""
+			reconciler, err := controllers.NewControlPlaneReconciler(client, mcName, nil)
+			Expect(err).Should(HaveOccurred())
+			Expect(reconciler).To(BeNil())
+		})
+
+		It("fails to create reconciler when MC namespace is empty", func(ctx context.Context) {
+			mcName.Namespace = ""
+			reconciler, err := controllers.NewControlPlaneReconciler(client, mcName, nil)
+			Expect(err).Should(HaveOccurred())
+			Expect(reconciler).To(BeNil())
+		})
+	})
+
+	Describe("PreflightChecks", func() {
+		// These tests don't rely on internal state.
+		reconciler := new(controllers.ControlPlaneReconciler)
+		namespace, name := "default", "test"
+
+		Describe("Management AzureCluster", func() {
+			It("cancels when the management cluster is not private", func(ctx context.Context) {
+				azureCluster := NewAzureClusterBuilder("", "").WithAPILoadBalancerType(capz.Public).Build()
+
+				err := reconciler.PreflightCheckManagementCluster(ctx, azureCluster)
+				Expect(err).To(MatchError(controllers.ErrReasonManagementClusterNotPrivate))
+			})
+
+			It("proceeds when all preflight checks pass", func(ctx context.Context) {
+				azureCluster := NewAzureClusterBuilder("", "").WithAPILoadBalancerType(capz.Internal).Build()
+
+				err := reconciler.PreflightCheckManagementCluster(ctx, azureCluster)
+				Expect(err).ShouldNot(HaveOccurred())
+			})
+		})
+
+		Describe("ControlPlane", func() {
+			It("cancels when the control plane is being deleted", func(ctx context.Context) {
+				kcp := NewKubeadmControlPlaneBuilder(namespace, name).
+					WithDeletionTimestamp().
+					BuildUnstructured()
+
+				err := reconciler.PreflightCheckControlPlane(ctx, kcp)
+				Expect(err).To(MatchError(controllers.ErrReasonControlPlaneDeleting))
+			})
+
+			It("cancels when the control plane is already provisioned", func(ctx context.Context) {
+				kcp := NewKubeadmControlPlaneBuilder(namespace, name).
+					WithStatusProvisioned().
+					BuildUnstructured()
+
+				err := reconciler.PreflightCheckControlPlane(ctx, kcp)
+				Expect(err).To(MatchError(controllers.ErrReasonControlPlaneProvisioned))
+			})
+
+			It("cancels when the v1beta1 control plane is already initialized", func(ctx context.Context) {
+				controlPlane := NewAzureASOManagedControlPlaneBuilder(namespace, name).Build()
+				_ = NewClusterBuilder(namespace, name).WithAzureASOManagedControlPlane(controlPlane).Build()
+				content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(controlPlane)
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(unstructured.SetNestedField(content, true, "status", "initialized")).To(Succeed())
+
+				err = reconciler.PreflightCheckControlPlane(ctx, &unstructured.Unstructured{Object: content})
+				Expect(err).To(MatchError(controllers.ErrReasonControlPlaneProvisioned))
+			})
+
+			It("cancels when the control plane does not yet have an owning cluster", func(ctx context.Context) {
+				kcp := NewKubeadmControlPlaneBuilder(namespace, name).BuildUnstructured()
+
+				err := reconciler.PreflightCheckControlPlane(ctx, kcp)
+				Expect(err).To(MatchError(controllers.ErrReasonControlPlaneHasNoOwner))
+			})
+
+			It("proceeds when all preflight checks pass", func(ctx context.Context) {
+				kcpBuilder := NewKubeadmControlPlaneBuilder(namespace, name)
+				_ = NewClusterBuilder(namespace, name).WithKubeadmControlPlane(kcpBuilder.Build()).Build()
+
+				err := reconciler.PreflightCheckControlPlane(ctx, kcpBuilder.BuildUnstructured())
+				Expect(err).ShouldNot(HaveOccurred())
+			})
+		})
+
+		Describe("Cluster", func() {
+			It("cancels when the cluster is paused", func(ctx context.Context) {
+				cluster := NewClusterBuilder(namespace, name).WithPause().Build()
+
+				err := reconciler.PreflightCheckCluster(ctx, cluster)
+				Expect(err).To(MatchError(controllers.ErrReasonClusterPaused))
+			})
+
+			It("cancels when the cluster has no infrastructure ref", func(ctx context.Context) {
+				cluster := NewClusterBuilder(namespace, name).Build()
+
+				err := reconciler.PreflightCheckCluster(ctx, cluster)
+				Expect(err).To(MatchError(controllers.ErrReasonInfraClusterMissing))
+			})
+
+			It("proceeds when all preflight checks pass", func(ctx context.Context) {
+				azureCluster := NewAzureClusterBuilder("", "").Build()
+				cluster := NewClusterBuilder(namespace, name).WithAzureCluster(azureCluster).Build()
+
+				err := reconciler.PreflightCheckCluster(ctx, cluster)
+				Expect(err).ShouldNot(HaveOccurred())
+			})
+		})
+	})
+
+	Describe("Reconciliation", func() {
+		It("pauses the control plane when infracluster status conditions are unmet", func(ctx context.Context) {
+			name, namespace := "test", "org-giantswarm"
+			mcInfraCluster := NewAzureClusterBuilder(namespace, "management-cluster").
+				WithResourceGroup("management-cluster").
+				WithAPILoadBalancerType(capz.Internal).
+				Build()
+			mcName := types.NamespacedName{Namespace: mcInfraCluster.Namespace, Name: mcInfraCluster.Name}
+			kcp := NewKubeadmControlPlaneBuilder(namespace, name).Build()
+			infraCluster := NewAzureClusterBuilder(namespace, name).
+				WithResourceGroup(name).
+				Build()
+			cluster := NewClusterBuilder(namespace, name).
+				WithKubeadmControlPlane(kcp).
+				WithAzureCluster(infraCluster).
+				Build()
+
+			client := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithRESTMapper(testrestmapper.TestOnlyStaticRESTMapper(scheme)).
+				WithObjects(mcInfraCluster, kcp, infraCluster, cluster).
+				Build()
+
+			reconciler, err := controllers.NewControlPlaneReconciler(client, mcName, &controllers.ControlPlaneReconcilerOptions{
+				AzureClusterGates: []capiv1beta1.ConditionType{"NotMet"},
+			})
+			Expect(err).ShouldNot(HaveOccurred())
+
+			request := Request(namespace, name)
+			result, err := reconciler.Reconcile(ctx, request)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(result.RequeueAfter).Should(BeZero())
+
+			err = client.Get(ctx, request.NamespacedName, kcp)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(kcp.Annotations).To(HaveKey(capi.PausedAnnotation))
+		})
+
+		It("unpauses the control plane when all conditions are met", func(ctx context.Context) {
+			name, namespace := "test", "org-giantswarm"
+			condition := capiv1beta1.Condition{
+				Type:   "YesMet",
+				Status: corev1.ConditionTrue,
+			}
+			mcInfraCluster := NewAzureClusterBuilder(namespace, "management-cluster").
+				WithResourceGroup("management-cluster").
+				WithAPILoadBalancerType(capz.Internal).
+				Build()
+			mcName := types.NamespacedName{Namespace: mcInfraCluster.Namespace, Name: mcInfraCluster.Name}
+			kcp := NewKubeadmControlPlaneBuilder(namespace, name).WithPause().Build()
+			infraCluster := NewAzureClusterBuilder(namespace, name).
+				WithResourceGroup(name).
+				WithAPILoadBalancerType(capz.Internal).
+				WithCondition(&condition).
+				Build()
+			cluster := NewClusterBuilder(namespace, name).
+				WithKubeadmControlPlane(kcp).
+				WithAzureCluster(infraCluster).
+				Build()
+
+			client := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithRESTMapper(testrestmapper.TestOnlyStaticRESTMapper(scheme)).
+				WithObjects(mcInfraCluster, kcp, infraCluster, cluster).
+				Build()
+
+			reconciler, err := controllers.NewControlPlaneReconciler(client, mcName, &controllers.ControlPlaneReconcilerOptions{
+				AzureClusterGates: []capiv1beta1.ConditionType{condition.Type},
+			})
+			Expect(err).ShouldNot(HaveOccurred())
+
+			request := Request(namespace, name)
+			result, err := reconciler.Reconcile(ctx, request)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(result.RequeueAfter).Should(BeZero())
+
+			err = client.Get(ctx, request.NamespacedName, kcp)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(kcp.Annotations).To(Not(HaveKey(capi.PausedAnnotation)))
+		})
+
+		It("pauses other kinds of control planes when infracluster status conditions are unmet", func(ctx context.Context) {
+			name, namespace := "test", "org-giantswarm"
+			mcInfraCluster := NewAzureClusterBuilder(namespace, "management-cluster").
+				WithResourceGroup("management-cluster").
+				WithAPILoadBalancerType(capz.Internal).
+				Build()
+			mcName := types.NamespacedName{Namespace: mcInfraCluster.Namespace, Name: mcInfraCluster.Name}
+			controlPlane := NewAzureASOManagedControlPlaneBuilder(namespace, name).Build()
+			infraCluster := NewAzureClusterBuilder(namespace, name).
+				WithResourceGroup(name).
+				Build()
+			cluster := NewClusterBuilder(namespace, name).
+				WithAzureASOManagedControlPlane(controlPlane).
+				WithAzureCluster(infraCluster).
+				Build()
+
+			client := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithRESTMapper(testrestmapper.TestOnlyStaticRESTMapper(scheme)).
+				WithObjects(mcInfraCluster, controlPlane, infraCluster, cluster).
+				Build()
+
+			reconciler, err := controllers.NewControlPlaneReconciler(client, mcName, &controllers.ControlPlaneReconcilerOptions{
+				AzureClusterGates: []capiv1beta1.ConditionType{"NotMet"},
+				ControlPlaneKind:  asoControlPlaneKind,
+			})
+			Expect(err).ShouldNot(HaveOccurred())
+
+			request := Request(namespace, name)
+			result, err := reconciler.Reconcile(ctx, request)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(result.RequeueAfter).Should(BeZero())
+
+			err = client.Get(ctx, request.NamespacedName, controlPlane)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(controlPlane.Annotations).To(HaveKey(capi.PausedAnnotation))
+		})
+
+		It("unpauses other kinds of control planes when all conditions are met", func(ctx context.Context) {
+			name, namespace := "test", "org-giantswarm"
+			condition := capiv1beta1.Condition{
+				Type:   "YesMet",
+				Status: corev1.ConditionTrue,
+			}
+			mcInfraCluster := NewAzureClusterBuilder(namespace, "management-cluster").
+				WithResourceGroup("management-cluster").
+				WithAPILoadBalancerType(capz.Internal).
+				Build()
+			mcName := types.NamespacedName{Namespace: mcInfraCluster.Namespace, Name: mcInfraCluster.Name}
+			controlPlane := NewAzureASOManagedControlPlaneBuilder(namespace, name).Build()
+			controlPlane.Annotations = map[string]string{capi.PausedAnnotation: "true"}
+			infraCluster := NewAzureClusterBuilder(namespace, name).
+				WithResourceGroup(name).
+				WithCondition(&condition).
+				Build()
+			cluster := NewClusterBuilder(namespace, name).
+				WithAzureASOManagedControlPlane(controlPlane).
+				WithAzureCluster(infraCluster).
+				Build()
+
+			client := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithRESTMapper(testrestmapper.TestOnlyStaticRESTMapper(scheme)).
+				WithObjects(mcInfraCluster, controlPlane, infraCluster, cluster).
+				Build()
+
+			reconciler, err := controllers.NewControlPlaneReconciler(client, mcName, &controllers.ControlPlaneReconcilerOptions{
+				AzureClusterGates: []capiv1beta1.ConditionType{condition.Type},
+				ControlPlaneKind:  asoControlPlaneKind,
+			})
+			Expect(err).ShouldNot(HaveOccurred())
+
+			request := Request(namespace, name)
+			result, err := reconciler.Reconcile(ctx, request)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(result.RequeueAfter).Should(BeZero())
+
+			err = client.Get(ctx, request.NamespacedName, controlPlane)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(controlPlane.Annotations).NotTo(HaveKey(capi.PausedAnnotation))
+		})
+
+		It("does not pause control planes of infraclusters without the gate conditions", func(ctx context.Context) {
+			name, namespace := "test", "org-giantswarm"
+			mcInfraCluster := NewAzureClusterBuilder(namespace, "management-cluster").
+				WithResourceGroup("management-cluster").
+				WithAPILoadBalancerType(capz.Internal).
+				Build()
+			mcName := types.NamespacedName{Namespace: mcInfraCluster.Namespace, Name: mcInfraCluster.Name}
+			controlPlane := NewAzureASOManagedControlPlaneBuilder(namespace, name).Build()
+			// CAPZ only marks the AzureASOManagedCluster ready once the control plane has an endpoint,
+			// so waiting for it would never unpause the control plane.
+			infraCluster := &capz.AzureASOManagedCluster{}
+			infraCluster.SetNamespace(namespace)
+			infraCluster.SetName(name)
+			cluster := NewClusterBuilder(namespace, name).
+				WithAzureASOManagedControlPlane(controlPlane).
+				Build()
+
+			client := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithRESTMapper(testrestmapper.TestOnlyStaticRESTMapper(scheme)).
+				WithObjects(mcInfraCluster, controlPlane, infraCluster, cluster).
+				Build()
+
+			reconciler, err := controllers.NewControlPlaneReconciler(client, mcName, &controllers.ControlPlaneReconcilerOptions{
+				AzureClusterGates: []capiv1beta1.ConditionType{"NotMet"},
+				ControlPlaneKind:  asoControlPlaneKind,
+			})
+			Expect(err).ShouldNot(HaveOccurred())
+
+			request := Request(namespace, name)
+			result, err := reconciler.Reconcile(ctx, request)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(result.RequeueAfter).Should(BeZero())
+
+			err = client.Get(ctx, request.NamespacedName, controlPlane)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(controlPlane.Annotations).NotTo(HaveKey(capi.PausedAnnotation))
+		})
+
+		It("ignores control planes of other kinds", func(ctx context.Context) {
+			name, namespace := "test", "org-giantswarm"
+			mcInfraCluster := NewAzureClusterBuilder(namespace, "management-cluster").
+				WithResourceGroup("management-cluster").
+				WithAPILoadBalancerType(capz.Internal).
+				Build()
+			mcName := types.NamespacedName{Namespace: mcInfraCluster.Namespace, Name: mcInfraCluster.Name}
+			controlPlane := NewAzureASOManagedControlPlaneBuilder(namespace, name).Build()
+			cluster := NewClusterBuilder(namespace, name).
+				WithAzureASOManagedControlPlane(controlPlane).
+				Build()
+
+			client := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithRESTMapper(testrestmapper.TestOnlyStaticRESTMapper(scheme)).
+				WithObjects(mcInfraCluster, controlPlane, cluster).
+				Build()
+
+			reconciler, err := controllers.NewControlPlaneReconciler(client, mcName, &controllers.ControlPlaneReconcilerOptions{
+				AzureClusterGates: []capiv1beta1.ConditionType{"NotMet"},
+			})
+			Expect(err).ShouldNot(HaveOccurred())
+
+			request := Request(namespace, name)
+			result, err := reconciler.Reconcile(ctx, request)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(result.RequeueAfter).Should(BeZero())
+
+			err = client.Get(ctx, request.NamespacedName, controlPlane)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(controlPlane.Annotations).NotTo(HaveKey(capi.PausedAnnotation))
+		})
+	})
+
+	Describe("ControlPlaneRequestsFor", func() {
+		var cluster *capi.Cluster
+		var infraCluster *capz.AzureASOManagedCluster
+		var k8sClient client.Client
+		mcName := types.NamespacedName{Namespace: "org-giantswarm", Name: "management-cluster"}
+
+		BeforeEach(func() {
+			controlPlane := NewAzureASOManagedControlPlaneBuilder("org-giantswarm", "test-cp").Build()
+			cluster = NewClusterBuilder("org-giantswarm", "test").
+				WithAzureASOManagedControlPlane(controlPlane).
+				Build()
+			infraCluster = &capz.AzureASOManagedCluster{}
+			infraCluster.SetNamespace("org-giantswarm")
+			infraCluster.SetName("test")
+			infraCluster.SetOwnerReferences([]metav1.OwnerReference{{
+				APIVersion: capi.GroupVersion.String(),
+				Kind:       "Cluster",
+				Name:       cluster.Name,
+			}})
+
+			k8sClient = fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(cluster, infraCluster).
+				Build()
+		})
+
+		It("enqueues the control plane of the owning cluster", func(ctx context.Context) {
+			reconciler, err := controllers.NewControlPlaneReconciler(k8sClient, mcName, &controllers.ControlPlaneReconcilerOptions{
+				ControlPlaneKind:          asoControlPlaneKind,
+				InfrastructureClusterKind: asoClusterKind,
+			})
+			Expect(err).ShouldNot(HaveOccurred())
+
+			Expect(reconciler.ControlPlaneRequestsFor(ctx, infraCluster)).To(ConsistOf(Request("org-giantswarm", "test-cp")))
+		})
+
+		It("ignores control planes of other kinds", func(ctx context.Context) {
+			reconciler, err := controllers.NewControlPlaneReconciler(k8sClient, mcName, nil)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			Expect(reconciler.ControlPlaneRequestsFor(ctx, infraCluster)).To(BeEmpty())
+		})
+
+		It("ignores infraclusters without owning cluster", func(ctx context.Context) {
+			reconciler, err := controllers.NewControlPlaneReconciler(k8sClient, mcName, &controllers.ControlPlaneReconcilerOptions{
+				ControlPlaneKind:          asoControlPlaneKind,
+				InfrastructureClusterKind: asoClusterKind,
+			})
+			Expect(err).ShouldNot(HaveOccurred())
+
+			infraCluster.SetOwnerReferences(nil)
+			Expect(reconciler.ControlPlaneRequestsFor(ctx, infraCluster)).To(BeEmpty())
+		})
+	})
+})
+
+var (
+	asoControlPlaneKind = schema.GroupKind{
+		Group: capz.GroupVersion.Group,
+		Kind:  capz.AzureASOManagedControlPlaneKind,
+	}
+	asoClusterKind = schema.GroupKind{
+		Group: capz.GroupVersion.Group,
+		Kind:  capz.AzureASOManagedClusterKind,
+	}
+)
@@ -2,46 +2,299 @@ package controllers
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"strings"
+	"text/template"
 
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/msi/armmsi"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metaerr "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
 	capz "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
 	"sigs.k8s.io/cluster-api-provider-azure/pkg/mutators"
 	capi "sigs.k8s.io/cluster-api/api/core/v1beta2"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/patch"
+	"sigs.k8s.io/cluster-api/util/secret"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/azure"
+	pkgerrors "github.com/giantswarm/azure-private-endpoint-operator/pkg/errors"
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/events"
 )
 
 const (
+	// ProviderConfigControllerFinalizer was added to Clusters by earlier versions of the operator.
+	// It is only removed now, once the ProviderConfig of earlier versions was adopted, so that it
+	// can't block the deletion of Clusters and namespaces, and the ProviderConfigs of deleted
+	// Clusters are cleaned up by the ProviderConfigSweeper instead.
 	ProviderConfigControllerFinalizer = "azure.giantswarm.io/providerconfig"
 	SecretIdentityKind                = "Secret"
+
+	// ProviderConfigClusterNameLabel and ProviderConfigClusterNamespaceLabel link the cluster-scoped
+	// ProviderConfig to the Cluster it was generated for.
+	ProviderConfigClusterNameLabel      = "azure.giantswarm.io/cluster-name"
+	ProviderConfigClusterNamespaceLabel = "azure.giantswarm.io/cluster-namespace"
+
+	// DefaultProviderConfigNameTemplate names the ProviderConfigs after the Cluster like earlier
+	// versions, so that the references of managed resources to them stay valid. Clusters with the
+	// same name in different organizations need a template with the namespace, e.g.
+	// "{{ .Namespace }}-{{ .Name }}".
+	DefaultProviderConfigNameTemplate = "{{ .Name }}"
+
+	// ProviderConfigModeAuto creates the ProviderConfigs whose CRDs are installed, the
+	// namespaced ones of Crossplane v2 and/or the cluster-scoped ones.
+	ProviderConfigModeAuto ProviderConfigMode = "Auto"
+	// ProviderConfigModeClusterScoped creates cluster-scoped azure.upbound.io ProviderConfigs.
+	ProviderConfigModeClusterScoped ProviderConfigMode = "ClusterScoped"
+	// ProviderConfigModeNamespaced creates namespaced azure.m.upbound.io ProviderConfigs of
+	// Crossplane v2 in the namespace of the Cluster.
+	ProviderConfigModeNamespaced ProviderConfigMode = "Namespaced"
+	// ProviderConfigModeBoth creates both the namespaced and the cluster-scoped ProviderConfigs.
+	ProviderConfigModeBoth ProviderConfigMode = "Both"
+)
+
+// Credentials sources of the Crossplane Azure ProviderConfigs.
+const (
+	CredentialsSourceUserAssignedManagedIdentity = "UserAssignedManagedIdentity"
+	CredentialsSourceOIDCTokenFile               = "OIDCTokenFile"
+	CredentialsSourceSecret                      = "Secret"
+
+	// CredentialsSecretKey is the key of the Azure credentials JSON in the credentials Secrets
+	// that are generated for service principals.
+	CredentialsSecretKey = "credentials"
+
+	identityClientSecretKey = "clientSecret"
+)
+
+// ASO credential conventions, see https://azure.github.io/azure-service-operator/guide/authentication/.
+const (
+	// ASONamespaceCredentialSecretName is the Secret that ASO uses for the resources in its
+	// namespace without the credential-from annotation.
+	ASONamespaceCredentialSecretName = "aso-credential"
+
+	asoCredentialFromAnnotation = "serviceoperator.azure.com/credential-from"
+	asoAuthModePodIdentity      = "podidentity"
+)
+
+// DefaultASOGlobalCredentialSecret is the Secret that ASO uses when neither the credential-from
+// annotation nor the namespace Secret is set.
+var DefaultASOGlobalCredentialSecret = types.NamespacedName{
+	Namespace: "azureserviceoperator-system",
+	Name:      "aso-controller-settings",
+}
+
+// Federated identity credentials of the workload identities, see
+// https://learn.microsoft.com/en-us/entra/workload-id/workload-identity-federation.
+const (
+	// FederatedIdentityCredentialFinalizer is added to Clusters whose workload identity got a
+	// federated identity credential, so that it is deleted with the Cluster.
+	FederatedIdentityCredentialFinalizer = "azure.giantswarm.io/federated-identity-credential"
+
+	// DefaultFederatedIdentityCredentialName is the name of the federated identity credentials.
+	DefaultFederatedIdentityCredentialName = "crossplane"
+
+	federatedIdentityCredentialAudience = "api://AzureADTokenExchange"
+)
+
+// CrossplaneProviderConfigReady condition of the Cluster and its reasons.
+const (
+	ProviderConfigReadyCondition = "CrossplaneProviderConfigReady"
+
+	// ProviderConfigCreatedReason is set when the ProviderConfigs of the Cluster are up to date.
+	ProviderConfigCreatedReason = "Created"
+	// UnsupportedInfrastructureReason is set when the infrastructure of the Cluster is not an
+	// AzureCluster, AzureManagedCluster or AzureASOManagedCluster.
+	UnsupportedInfrastructureReason = "UnsupportedInfrastructure"
+	// UnsupportedIdentityReason is set when the identity of the Cluster has an unsupported kind or
+	// type.
+	UnsupportedIdentityReason = "UnsupportedIdentity"
+	// IdentityMissingReason is set when the Cluster has no identityRef, or the identity or its
+	// Secret does not exist.
+	IdentityMissingReason = "IdentityMissing"
+	// ReconcileFailedReason is set for all other errors, e.g. when the ProviderConfig is owned by
+	// another Cluster.
+	ReconcileFailedReason = "ReconcileFailed"
 )
 
+// ProviderConfigMode selects the kinds of ProviderConfigs that are created for the Clusters.
+type ProviderConfigMode string
+
 var (
-	ErrIdentityRefUnset = errors.New("identity ref is not set")
+	ErrIdentityRefUnset                  = errors.New("identity ref is not set")
+	ErrProviderConfigOwnedByOtherCluster = errors.New("provider config is owned by another cluster")
+	ErrCredentialsSecretNotOwned         = errors.New("credentials secret is not owned by the cluster")
 )
 
-func NewProviderConfigReconciler(client client.Client) (*ProviderConfigReconciler, error) {
+type ProviderConfigReconcilerOptions struct {
+	// NameTemplate is the text/template for the names of the ProviderConfigs. It is executed with
+	// the Namespace and the Name of the Cluster. Defaults to DefaultProviderConfigNameTemplate.
+	// Namespaced ProviderConfigs are always named after the Cluster.
+	NameTemplate string
+	// DeleteLegacyProviderConfigs deletes the cluster-scoped ProviderConfigs that earlier versions
+	// named after the Cluster, once the ProviderConfigs of NameTemplate exist. Otherwise they are
+	// kept, so that the managed resources that still reference them keep working.
+	DeleteLegacyProviderConfigs bool
+	// Mode selects the kinds of ProviderConfigs that are created. Defaults to
+	// ProviderConfigModeAuto, which discovers the installed ProviderConfig CRDs once.
+	Mode ProviderConfigMode
+	// WorkloadIdentitySource is the credentials source of the ProviderConfigs of Clusters with
+	// workload identity: CredentialsSourceUserAssignedManagedIdentity (default) or
+	// CredentialsSourceOIDCTokenFile, which makes the provider use the projected service account
+	// token of its pod.
+	WorkloadIdentitySource string
+	// ASOGlobalCredentialSecret is the global credential Secret of ASO. Defaults to
+	// DefaultASOGlobalCredentialSecret.
+	ASOGlobalCredentialSecret types.NamespacedName
+	// Generators generate the ProviderConfigs of other Crossplane providers, in addition to the
+	// Azure ProviderConfigs.
+	Generators []ProviderConfigGenerator
+	// AzureAD enables the generation of azuread.upbound.io ProviderConfigs with the identity of the
+	// Cluster, of the same kinds as the Azure ProviderConfigs.
+	AzureAD bool
+	// FederatedIdentityCredential configures the federated identity credentials that let the
+	// Crossplane provider use the workload identities of the Clusters. They are only created when
+	// the Issuer is set.
+	FederatedIdentityCredential FederatedIdentityCredentialOptions
+	// FederatedIdentityCredentialsClientCreator creates the Azure clients for the federated
+	// identity credentials. It is required when FederatedIdentityCredential.Issuer is set.
+	FederatedIdentityCredentialsClientCreator azure.FederatedIdentityCredentialsClientCreator
+}
+
+// FederatedIdentityCredentialOptions configures the federated identity credentials on the
+// user-assigned identities of the Clusters with workload identity.
+type FederatedIdentityCredentialOptions struct {
+	// Issuer is the URL of the OIDC issuer of the management cluster.
+	Issuer string
+	// Subject is the subject of the service account tokens of the Crossplane provider, e.g.
+	// system:serviceaccount:crossplane-system:provider-azure.
+	Subject string
+	// Name is the name of the federated identity credentials. Defaults to
+	// DefaultFederatedIdentityCredentialName.
+	Name string
+}
+
+func NewProviderConfigReconciler(client client.Client, recorder *events.Recorder, options *ProviderConfigReconcilerOptions) (*ProviderConfigReconciler, error) {
 	if client == nil {
 		return nil, errors.New("client may not be nil")
 	}
+	if recorder == nil {
+		return nil, errors.New("recorder may not be nil")
+	}
+
+	nameTemplate := DefaultProviderConfigNameTemplate
+	if options != nil && options.NameTemplate != "" {
+		nameTemplate = options.NameTemplate
+	}
+	tmpl, err := template.New("providerconfig-name").Option("missingkey=error").Parse(nameTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid provider config name template: %w", err)
+	}
+
+	mode := ProviderConfigModeAuto
+	if options != nil && options.Mode != "" {
+		mode = options.Mode
+	}
+	if mode == ProviderConfigModeAuto {
+		mode, err = discoverProviderConfigMode(client.RESTMapper())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	workloadIdentitySource := CredentialsSourceUserAssignedManagedIdentity
+	if options != nil && options.WorkloadIdentitySource != "" {
+		workloadIdentitySource = options.WorkloadIdentitySource
+	}
+	if workloadIdentitySource != CredentialsSourceUserAssignedManagedIdentity && workloadIdentitySource != CredentialsSourceOIDCTokenFile {
+		return nil, fmt.Errorf("unsupported workload identity credentials source %q", workloadIdentitySource)
+	}
 
-	return &ProviderConfigReconciler{
-		client: client,
-	}, nil
+	asoGlobalCredentialSecret := DefaultASOGlobalCredentialSecret
+	if options != nil && options.ASOGlobalCredentialSecret.Name != "" {
+		asoGlobalCredentialSecret = options.ASOGlobalCredentialSecret
+	}
+
+	r := &ProviderConfigReconciler{
+		client:                    client,
+		recorder:                  recorder,
+		nameTemplate:              tmpl,
+		workloadIdentitySource:    workloadIdentitySource,
+		asoGlobalCredentialSecret: asoGlobalCredentialSecret,
+	}
+	if options != nil {
+		r.deleteLegacyProviderConfigs = options.DeleteLegacyProviderConfigs
+		r.generators = options.Generators
+		r.azureAD = options.AzureAD
+		r.federatedIdentityCredential = options.FederatedIdentityCredential
+		r.federatedIdentityCredentialsClientCreator = options.FederatedIdentityCredentialsClientCreator
+	}
+	if r.federatedIdentityCredential.Issuer != "" {
+		if r.federatedIdentityCredential.Subject == "" {
+			return nil, errors.New("federated identity credential subject may not be empty")
+		}
+		if r.federatedIdentityCredentialsClientCreator == nil {
+			return nil, errors.New("federated identity credentials client creator may not be nil")
+		}
+		if r.federatedIdentityCredential.Name == "" {
+			r.federatedIdentityCredential.Name = DefaultFederatedIdentityCredentialName
+		}
+	}
+	switch mode {
+	case ProviderConfigModeClusterScoped:
+		r.clusterScoped = true
+	case ProviderConfigModeNamespaced:
+		r.namespaced = true
+	case ProviderConfigModeBoth:
+		r.clusterScoped = true
+		r.namespaced = true
+	default:
+		return nil, fmt.Errorf("unsupported provider config mode %q", mode)
+	}
+
+	if _, err = r.providerConfigName(types.NamespacedName{Namespace: "org-example", Name: "example"}); err != nil {
+		return nil, err
+	}
+
+	return r, nil
 }
 
 // ProviderConfigReconciler manages Crossplane ProviderConfig resources for Azure and AKS clusters.
 type ProviderConfigReconciler struct {
-	client client.Client
+	client       client.Client
+	recorder     *events.Recorder
+	nameTemplate *template.Template
+	// deleteLegacyProviderConfigs deletes the ProviderConfigs named after the Cluster when
+	// nameTemplate names them differently.
+	deleteLegacyProviderConfigs bool
+	// clusterScoped and namespaced select the kinds of ProviderConfigs that are created.
+	clusterScoped bool
+	namespaced    bool
+	// workloadIdentitySource is the credentials source for Clusters with workload identity.
+	workloadIdentitySource string
+	// asoGlobalCredentialSecret is the credential Secret of ASO clusters without a credential-from
+	// annotation and namespace Secret.
+	asoGlobalCredentialSecret types.NamespacedName
+	// generators generate the ProviderConfigs of other Crossplane providers.
+	generators []ProviderConfigGenerator
+	// azureAD enables the Azure AD ProviderConfigs.
+	azureAD bool
+	// federatedIdentityCredential is the federated identity credential of the workload identities.
+	federatedIdentityCredential               FederatedIdentityCredentialOptions
+	federatedIdentityCredentialsClientCreator azure.FederatedIdentityCredentialsClientCreator
 }
 
 func (r *ProviderConfigReconciler) Reconcile(ctx context.Context, req reconcile.Request) (result reconcile.Result, err error) {
@@ -55,7 +308,8 @@ func (r *ProviderConfigReconciler) Reconcile(ctx context.Context, req reconcile.
 	if err != nil {
 		if apierrors.IsNotFound(err) {
 			logger.Info("cluster has been deleted")
-			return result, nil
+			err = r.deleteProviderConfigs(ctx, req.NamespacedName)
+			return result, err
 		}
 		return
 	}
@@ -70,12 +324,30 @@ func (r *ProviderConfigReconciler) Reconcile(ctx context.Context, req reconcile.
 func (r *ProviderConfigReconciler) reconcileNormal(ctx context.Context, cluster *capi.Cluster) (result reconcile.Result, err error) {
 	logger := log.FromContext(ctx)
 
+	// The outcome is reported on the Cluster, so that app teams see why their Crossplane claims
+	// fail.
+	var reason, message string
+	defer func() {
+		if statusErr := r.reportStatus(ctx, cluster, reason, message, err); statusErr != nil && err == nil {
+			err = statusErr
+		}
+	}()
+
+	// The ProviderConfigs of the other providers only need the kubeconfig of the Cluster, so they
+	// are generated independently of the Azure identity.
+	for _, generator := range r.generators {
+		if err = r.reconcileGeneratedProviderConfig(ctx, cluster, generator); err != nil {
+			return
+		}
+	}
+
 	var info identityInfo
 	var identityRef *corev1.ObjectReference
+	var azureCluster *capz.AzureCluster
 
 	switch cluster.Spec.InfrastructureRef.Kind {
 	case capz.AzureClusterKind:
-		azureCluster := new(capz.AzureCluster)
+		azureCluster = new(capz.AzureCluster)
 		name := types.NamespacedName{
 			Namespace: cluster.Namespace,
 			Name:      cluster.Spec.InfrastructureRef.Name,
@@ -100,16 +372,17 @@ func (r *ProviderConfigReconciler) reconcileNormal(ctx context.Context, cluster
 			return
 		}
 
-		var resources []*unstructured.Unstructured
-		resources, err = mutators.ToUnstructured(ctx, cp.Spec.Resources)
+		var secretName types.NamespacedName
+		secretName, err = r.asoCredentialSecretName(ctx, cp)
 		if err != nil {
-			logger.Error(err, "failed to convert ASO resource into unstructured")
+			logger.Error(err, "failed to discover ASO credential secret", "name", name)
 			return
 		}
 
 		identityRef = &corev1.ObjectReference{
-			Kind: SecretIdentityKind,
-			Name: resources[0].GetAnnotations()["serviceoperator.azure.com/credential-from"],
+			Kind:      SecretIdentityKind,
+			Namespace: secretName.Namespace,
+			Name:      secretName.Name,
 		}
 
 	case capz.AzureManagedClusterKind:
@@ -128,11 +401,16 @@ func (r *ProviderConfigReconciler) reconcileNormal(ctx context.Context, cluster
 
 	default:
 		logger.Info("skipping provider config generation for unsupported cluster")
+		reason = UnsupportedInfrastructureReason
+		message = fmt.Sprintf("infrastructure kind %q is not supported", cluster.Spec.InfrastructureRef.Kind)
+		// Earlier versions did not create ProviderConfigs for unsupported clusters either.
+		err = r.removeLegacyFinalizer(ctx, cluster)
 		return
 	}
 
 	if identityRef == nil {
 		logger.Error(ErrIdentityRefUnset, "unable to proceed")
+		reason = IdentityMissingReason
 		err = reconcile.TerminalError(ErrIdentityRefUnset)
 		return result, err
 	}
@@ -147,82 +425,377 @@ func (r *ProviderConfigReconciler) reconcileNormal(ctx context.Context, cluster
 		err = r.client.Get(ctx, name, identity)
 		if err != nil {
 			logger.Error(err, "failed to get identity", "name", name)
+			if apierrors.IsNotFound(err) {
+				reason = IdentityMissingReason
+			}
 			return
 		}
 
 		switch identity.Spec.Type {
-		case capz.WorkloadIdentity:
+		case capz.WorkloadIdentity, capz.UserAssignedMSI:
+			info.Type = identity.Spec.Type
+			info.TenantID = identity.Spec.TenantID
+			info.ClientID = identity.Spec.ClientID
+		case capz.ServicePrincipal, capz.ManualServicePrincipal:
 			info.Type = identity.Spec.Type
 			info.TenantID = identity.Spec.TenantID
 			info.ClientID = identity.Spec.ClientID
+
+			secret := new(corev1.Secret)
+			name := identityClientSecretName(identity)
+			err = r.client.Get(ctx, name, secret)
+			if err != nil {
+				logger.Error(err, "failed to get identity client secret", "name", name)
+				if apierrors.IsNotFound(err) {
+					reason = IdentityMissingReason
+				}
+				return
+			}
+			info.ClientSecret = string(secret.Data[identityClientSecretKey])
 		default:
 			logger.Info("skipping provider config generation for unsupported cluster identity type", "type", identity.Spec.Type)
+			reason = UnsupportedIdentityReason
+			message = fmt.Sprintf("identity type %q is not supported", identity.Spec.Type)
 			return
 		}
 
 	case SecretIdentityKind:
 		secret := new(corev1.Secret)
 		name := types.NamespacedName{
-			Namespace: cluster.Namespace,
+			Namespace: identityRef.Namespace,
 			Name:      identityRef.Name,
 		}
+		if name.Namespace == "" {
+			name.Namespace = cluster.Namespace
+		}
 		err = r.client.Get(ctx, name, secret)
 		if err != nil {
 			logger.Error(err, "failed to get ASO secret", "name", name)
+			if apierrors.IsNotFound(err) {
+				reason = IdentityMissingReason
+			}
 			return
 		}
 
-		// Just assume it's workload identity for now.
-		info.Type = capz.WorkloadIdentity
 		info.ClientID = string(secret.Data["AZURE_CLIENT_ID"])
 		info.SubscriptionID = string(secret.Data["AZURE_SUBSCRIPTION_ID"])
 		info.TenantID = string(secret.Data["AZURE_TENANT_ID"])
+		info.ClientSecret = string(secret.Data["AZURE_CLIENT_SECRET"])
+
+		// ASO uses the client secret when it is set, and the AUTH_MODE otherwise.
+		switch {
+		case info.ClientSecret != "":
+			info.Type = capz.ManualServicePrincipal
+		case strings.EqualFold(string(secret.Data["AUTH_MODE"]), asoAuthModePodIdentity):
+			info.Type = capz.UserAssignedMSI
+		default:
+			info.Type = capz.WorkloadIdentity
+		}
 
 	default:
 		logger.Info("skipping provider config generation for unsupported identity", "kind", identityRef.GroupVersionKind())
+		reason = UnsupportedIdentityReason
+		message = fmt.Sprintf("identity kind %q is not supported", identityRef.Kind)
 		return
 	}
 
-	// Cluster has a supported configuration, so we will create a ProviderConfig.
-	// Let's add a finalizer to the Cluster so that we can clean up the ProviderConfig
-	// when this Cluster gets deleted.
-	// TODO: Should there just be an owner reference and let the GC handle cleanup?
-	origCluster := cluster.DeepCopy()
-	if controllerutil.AddFinalizer(cluster, ProviderConfigControllerFinalizer) {
-		if err = r.client.Patch(ctx, cluster, client.MergeFrom(origCluster)); err != nil {
+	// Cluster has a supported configuration, so we will create the ProviderConfigs.
+	if err = r.adoptLegacyProviderConfig(ctx, cluster, &info); err != nil {
+		return
+	}
+	if err = r.removeLegacyFinalizer(ctx, cluster); err != nil {
+		return
+	}
+	if info.isServicePrincipal() {
+		if err = r.reconcileCredentialsSecret(ctx, cluster, info); err != nil {
+			return
+		}
+	}
+	if r.clusterScoped {
+		if err = r.reconcileClusterScopedProviderConfig(ctx, cluster, info); err != nil {
+			return
+		}
+	}
+	if r.namespaced {
+		if err = r.reconcileNamespacedProviderConfig(ctx, cluster, info); err != nil {
 			return
 		}
 	}
+	if r.federatedIdentityCredential.Issuer != "" && azureCluster != nil && info.Type == capz.WorkloadIdentity {
+		if err = r.reconcileFederatedIdentityCredential(ctx, cluster, azureCluster, info); err != nil {
+			return
+		}
+	}
+
+	reason = ProviderConfigCreatedReason
+	return
+}
+
+// reportStatus sets the CrossplaneProviderConfigReady condition of the Cluster and emits an event
+// with the outcome of the reconciliation. Errors without a reason are reported as
+// ReconcileFailedReason.
+func (r *ProviderConfigReconciler) reportStatus(ctx context.Context, cluster *capi.Cluster, reason, message string, reconcileErr error) error {
+	if reconcileErr != nil {
+		if reason == "" {
+			reason = ReconcileFailedReason
+		}
+		if message == "" {
+			message = reconcileErr.Error()
+		}
+	}
+	if reason == "" {
+		return nil
+	}
+
+	condition := metav1.Condition{
+		Type:    ProviderConfigReadyCondition,
+		Status:  metav1.ConditionFalse,
+		Reason:  reason,
+		Message: message,
+	}
+	switch reason {
+	case ProviderConfigCreatedReason:
+		condition.Status = metav1.ConditionTrue
+		r.recorder.Normalf(cluster, events.ReasonProviderConfigReady, events.ActionGenerateProviderConfig, "Crossplane ProviderConfig is ready")
+	case UnsupportedInfrastructureReason:
+		r.recorder.Normalf(cluster, events.ReasonProviderConfigNotReady, events.ActionGenerateProviderConfig, "%s: %s", reason, message)
+	default:
+		r.recorder.Warningf(cluster, events.ReasonProviderConfigNotReady, events.ActionGenerateProviderConfig, "%s: %s", reason, message)
+	}
+
+	patchHelper, err := patch.NewHelper(cluster, r.client)
+	if err != nil {
+		return err
+	}
+	conditions.Set(cluster, condition)
+	if err = patchHelper.Patch(ctx, cluster, patch.WithOwnedConditions{Conditions: []string{ProviderConfigReadyCondition}}); err != nil {
+		log.FromContext(ctx).Error(err, "failed to patch cluster conditions")
+		return err
+	}
+
+	return nil
+}
+
+// reconcileClusterScopedProviderConfig creates or updates the cluster-scoped ProviderConfig. It
+// can't be owned by the namespaced Cluster, so it is labelled with the Cluster instead, and deleted
+// when the Cluster is deleted or, if the deletion was missed, by the ProviderConfigSweeper.
+func (r *ProviderConfigReconciler) reconcileClusterScopedProviderConfig(ctx context.Context, cluster *capi.Cluster, info identityInfo) error {
+	clusterName := client.ObjectKeyFromObject(cluster)
+	providerConfigName, err := r.providerConfigName(clusterName)
+	if err != nil {
+		return reconcile.TerminalError(err)
+	}
+
+	err = r.createOrPatchProviderConfig(ctx, cluster, NewProviderConfig(providerConfigName), r.providerConfigSpec(cluster, info))
+	if err != nil {
+		return err
+	}
+	if r.azureAD {
+		err = r.createOrPatchProviderConfig(ctx, cluster, NewAzureADProviderConfig(providerConfigName), r.azureADProviderConfigSpec(cluster, info))
+		if err != nil {
+			return err
+		}
+	}
 
+	// Earlier versions named the ProviderConfig after the Cluster only. Managed resources keep
+	// referencing it by name, so it is only deleted when this is opted in, after the references
+	// have been updated, and only after the ProviderConfig with the new name exists.
+	if providerConfigName != cluster.Name && r.deleteLegacyProviderConfigs {
+		return r.deleteProviderConfigOfCluster(ctx, NewProviderConfig(cluster.Name), clusterName)
+	}
+
+	return nil
+}
+
+// adoptLegacyProviderConfig labels the cluster-scoped ProviderConfig that earlier versions created
+// for the Cluster, so that it is renamed and deleted like the ProviderConfigs of this version.
+// Earlier versions named it after the Cluster without labelling it, so Clusters with the same name
+// in different namespaces shared it. It is therefore only adopted when no other Cluster has the
+// same name, and when it has the credentials of the identity of the Cluster, or, without identity,
+// when it is deleted with the Cluster.
+func (r *ProviderConfigReconciler) adoptLegacyProviderConfig(ctx context.Context, cluster *capi.Cluster, info *identityInfo) error {
 	providerConfig := NewProviderConfig(cluster.Name)
-	_, err = controllerutil.CreateOrPatch(ctx, r.client, providerConfig, func() error {
-		providerConfig.Object["spec"] = map[string]any{
-			"credentials": map[string]any{
-				"source": "UserAssignedManagedIdentity",
-			},
-			"clientID":       info.ClientID,
-			"subscriptionID": info.SubscriptionID,
-			"tenantID":       info.TenantID,
+	err := r.client.Get(ctx, client.ObjectKeyFromObject(providerConfig), providerConfig)
+	if apierrors.IsNotFound(err) || metaerr.IsNoMatchError(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if _, ok := ProviderConfigCluster(providerConfig); ok {
+		return nil
+	}
+	if info != nil && !isLegacyProviderConfigOf(providerConfig, *info) {
+		return nil
+	}
+
+	clusters := new(capi.ClusterList)
+	if err = r.client.List(ctx, clusters); err != nil {
+		return err
+	}
+	for _, other := range clusters.Items {
+		if other.Name == cluster.Name && other.Namespace != cluster.Namespace {
+			log.FromContext(ctx).Info("not adopting provider config of earlier versions that may be shared with another cluster",
+				"name", providerConfig.GetName(), "otherNamespace", other.Namespace)
+			return nil
+		}
+	}
+
+	log.FromContext(ctx).Info("adopting provider config of earlier versions", "name", providerConfig.GetName())
+	origProviderConfig := providerConfig.DeepCopy()
+	setProviderConfigClusterLabels(providerConfig, cluster)
+	return r.client.Patch(ctx, providerConfig, client.MergeFrom(origProviderConfig))
+}
+
+// isLegacyProviderConfigOf returns whether the ProviderConfig of earlier versions has the
+// credentials of the identity.
+func isLegacyProviderConfigOf(providerConfig *unstructured.Unstructured, info identityInfo) bool {
+	for field, value := range map[string]string{
+		"clientID":       info.ClientID,
+		"subscriptionID": info.SubscriptionID,
+		"tenantID":       info.TenantID,
+	} {
+		got, _, _ := unstructured.NestedString(providerConfig.Object, "spec", field)
+		if got != value {
+			return false
 		}
+	}
+	return true
+}
+
+// removeLegacyFinalizer removes the finalizer of earlier versions from the Cluster.
+func (r *ProviderConfigReconciler) removeLegacyFinalizer(ctx context.Context, cluster *capi.Cluster) error {
+	origCluster := cluster.DeepCopy()
+	if controllerutil.RemoveFinalizer(cluster, ProviderConfigControllerFinalizer) {
+		return r.client.Patch(ctx, cluster, client.MergeFrom(origCluster))
+	}
+	return nil
+}
+
+// reconcileGeneratedProviderConfig creates or updates the ProviderConfig of the generator. It
+// has the same name as the cluster-scoped Azure ProviderConfig.
+func (r *ProviderConfigReconciler) reconcileGeneratedProviderConfig(ctx context.Context, cluster *capi.Cluster, generator ProviderConfigGenerator) error {
+	providerConfigName, err := r.providerConfigName(client.ObjectKeyFromObject(cluster))
+	if err != nil {
+		return reconcile.TerminalError(err)
+	}
+
+	generated := generator.NewProviderConfig(providerConfigName)
+	ok, err := generator.Generate(ctx, cluster, generated)
+	if err != nil || !ok {
+		return err
+	}
+
+	return r.createOrPatchProviderConfig(ctx, cluster, generator.NewProviderConfig(providerConfigName), generated.Object["spec"])
+}
+
+// createOrPatchProviderConfig creates or updates the cluster-scoped ProviderConfig with the spec,
+// and labels it with the Cluster. It never overwrites the credentials of another Cluster, e.g. when
+// the name template does not make the names unique.
+func (r *ProviderConfigReconciler) createOrPatchProviderConfig(ctx context.Context, cluster *capi.Cluster, providerConfig *unstructured.Unstructured, spec any) error {
+	clusterName := client.ObjectKeyFromObject(cluster)
+	_, err := controllerutil.CreateOrPatch(ctx, r.client, providerConfig, func() error {
+		if owner, ok := ProviderConfigCluster(providerConfig); ok && owner != clusterName {
+			return fmt.Errorf("%w: %s is owned by cluster %s", ErrProviderConfigOwnedByOtherCluster, providerConfig.GetName(), owner)
+		}
+
+		setProviderConfigClusterLabels(providerConfig, cluster)
+		providerConfig.Object["spec"] = spec
 		return nil
 	})
+	if err != nil {
+		log.FromContext(ctx).Error(err, "failed to create or patch provider config", "kind", providerConfig.GroupVersionKind(), "name", providerConfig.GetName())
+		return err
+	}
 
-	return
+	return nil
+}
+
+// reconcileNamespacedProviderConfig creates or updates the namespaced ProviderConfig of
+// Crossplane v2 in the namespace of the Cluster. It is owned by the Cluster, so it is garbage
+// collected with the Cluster.
+func (r *ProviderConfigReconciler) reconcileNamespacedProviderConfig(ctx context.Context, cluster *capi.Cluster, info identityInfo) error {
+	err := r.createOrPatchNamespacedProviderConfig(ctx, cluster, NewNamespacedProviderConfig(cluster.Namespace, cluster.Name), r.providerConfigSpec(cluster, info))
+	if err != nil {
+		return err
+	}
+	if r.azureAD {
+		return r.createOrPatchNamespacedProviderConfig(ctx, cluster, NewNamespacedAzureADProviderConfig(cluster.Namespace, cluster.Name), r.azureADProviderConfigSpec(cluster, info))
+	}
+
+	return nil
+}
+
+// createOrPatchNamespacedProviderConfig creates or updates the namespaced ProviderConfig with the
+// spec, labels it with the Cluster and makes the Cluster its owner.
+func (r *ProviderConfigReconciler) createOrPatchNamespacedProviderConfig(ctx context.Context, cluster *capi.Cluster, providerConfig *unstructured.Unstructured, spec any) error {
+	_, err := controllerutil.CreateOrPatch(ctx, r.client, providerConfig, func() error {
+		setProviderConfigClusterLabels(providerConfig, cluster)
+		providerConfig.Object["spec"] = spec
+		return controllerutil.SetOwnerReference(cluster, providerConfig, r.client.Scheme())
+	})
+	if err != nil {
+		log.FromContext(ctx).Error(err, "failed to create or patch namespaced provider config", "kind", providerConfig.GroupVersionKind(), "namespace", cluster.Namespace, "name", cluster.Name)
+		return err
+	}
+
+	return nil
+}
+
+// reconcileFederatedIdentityCredential creates or updates the federated identity credential that
+// lets the Crossplane provider authenticate as the workload identity of the Cluster with its
+// service account token. The Cluster gets a finalizer first, so that the credential is deleted
+// with the Cluster.
+func (r *ProviderConfigReconciler) reconcileFederatedIdentityCredential(ctx context.Context, cluster *capi.Cluster, azureCluster *capz.AzureCluster, info identityInfo) error {
+	origCluster := cluster.DeepCopy()
+	if controllerutil.AddFinalizer(cluster, FederatedIdentityCredentialFinalizer) {
+		if err := r.client.Patch(ctx, cluster, client.MergeFrom(origCluster)); err != nil {
+			return err
+		}
+	}
+
+	credentialsClient, err := r.federatedIdentityCredentialsClientCreator(ctx, r.client, azureCluster)
+	if err != nil {
+		return err
+	}
+
+	err = credentialsClient.CreateOrUpdate(ctx, info.ClientID, r.federatedIdentityCredential.Name, armmsi.FederatedIdentityCredentialProperties{
+		Audiences: []*string{to.Ptr(federatedIdentityCredentialAudience)},
+		Issuer:    to.Ptr(r.federatedIdentityCredential.Issuer),
+		Subject:   to.Ptr(r.federatedIdentityCredential.Subject),
+	})
+	if err != nil {
+		log.FromContext(ctx).Error(err, "failed to create or update federated identity credential", "clientID", info.ClientID)
+		return err
+	}
+
+	return nil
 }
 
 func (r *ProviderConfigReconciler) reconcileDelete(ctx context.Context, cluster *capi.Cluster) (result reconcile.Result, err error) {
-	providerConfig := NewProviderConfig(cluster.Name)
-	err = r.client.Delete(ctx, providerConfig)
+	// Earlier versions deleted the ProviderConfig named after the Cluster as long as the Cluster had
+	// their finalizer.
+	if controllerutil.ContainsFinalizer(cluster, ProviderConfigControllerFinalizer) {
+		if err = r.adoptLegacyProviderConfig(ctx, cluster, nil); err != nil {
+			return
+		}
+	}
+
+	err = r.deleteProviderConfigs(ctx, client.ObjectKeyFromObject(cluster))
 	if err != nil {
-		if !apierrors.IsNotFound(err) &&
-			!metaerr.IsNoMatchError(err) {
+		return
+	}
+
+	if controllerutil.ContainsFinalizer(cluster, FederatedIdentityCredentialFinalizer) {
+		if err = r.deleteFederatedIdentityCredential(ctx, cluster); err != nil {
 			return
 		}
-		err = nil
 	}
 
 	origCluster := cluster.DeepCopy()
-	if controllerutil.RemoveFinalizer(cluster, ProviderConfigControllerFinalizer) {
+	removed := controllerutil.RemoveFinalizer(cluster, ProviderConfigControllerFinalizer)
+	removed = controllerutil.RemoveFinalizer(cluster, FederatedIdentityCredentialFinalizer) || removed
+	if removed {
 		if err = r.client.Patch(ctx, cluster, client.MergeFrom(origCluster)); err != nil {
 			return
 		}
@@ -231,17 +804,551 @@ func (r *ProviderConfigReconciler) reconcileDelete(ctx context.Context, cluster
 	return
 }
 
+// deleteFederatedIdentityCredential deletes the federated identity credential of the Cluster. All
+// Clusters with the same workload identity share the credential, so it is kept while other
+// AzureClusters use the identity. When the credential can't be deleted anymore, e.g. because the
+// AzureCluster is already gone, the identity is not allowed to be used, or Azure rejects the
+// request for other reasons than throttling or temporary problems, it is left behind instead of
+// blocking the deletion of the Cluster.
+func (r *ProviderConfigReconciler) deleteFederatedIdentityCredential(ctx context.Context, cluster *capi.Cluster) error {
+	logger := log.FromContext(ctx)
+
+	if r.federatedIdentityCredential.Issuer == "" {
+		return nil
+	}
+
+	azureCluster := new(capz.AzureCluster)
+	err := r.client.Get(ctx, types.NamespacedName{Namespace: cluster.Namespace, Name: cluster.Spec.InfrastructureRef.Name}, azureCluster)
+	if apierrors.IsNotFound(err) {
+		logger.Info("infracluster is already deleted, leaving federated identity credential behind")
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	identity, err := r.workloadIdentity(ctx, azureCluster)
+	if err != nil || identity == nil {
+		return err
+	}
+
+	azureClusters := new(capz.AzureClusterList)
+	if err = r.client.List(ctx, azureClusters); err != nil {
+		return err
+	}
+	for i := range azureClusters.Items {
+		other := &azureClusters.Items[i]
+		if client.ObjectKeyFromObject(other) == client.ObjectKeyFromObject(azureCluster) || !other.DeletionTimestamp.IsZero() {
+			continue
+		}
+		otherIdentity, err := r.workloadIdentity(ctx, other)
+		if err != nil {
+			return err
+		}
+		if otherIdentity != nil && otherIdentity.Spec.ClientID == identity.Spec.ClientID {
+			logger.Info("federated identity credential is used by another cluster", "azureCluster", client.ObjectKeyFromObject(other))
+			return nil
+		}
+	}
+
+	credentialsClient, err := r.federatedIdentityCredentialsClientCreator(ctx, r.client, azureCluster)
+	if err == nil {
+		err = credentialsClient.Delete(ctx, identity.Spec.ClientID, r.federatedIdentityCredential.Name)
+	}
+	if isTerminalFederatedIdentityCredentialError(err) {
+		logger.Error(err, "failed to delete federated identity credential, leaving it behind", "clientID", identity.Spec.ClientID)
+		r.recorder.Warningf(cluster, events.ReasonReconcileError, events.ActionDelete, "Federated identity credential %s of identity %s was not deleted: %s", r.federatedIdentityCredential.Name, identity.Spec.ClientID, err)
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	logger.Info("deleted federated identity credential", "clientID", identity.Spec.ClientID)
+	return nil
+}
+
+// isTerminalFederatedIdentityCredentialError returns whether deleting the federated identity
+// credential failed in a way that retrying does not fix.
+func isTerminalFederatedIdentityCredentialError(err error) bool {
+	return pkgerrors.IsAzureTerminal(err) ||
+		pkgerrors.IsAzureClusterIdentityNotAllowed(err) ||
+		pkgerrors.IsIdentityRefNotSet(err) ||
+		pkgerrors.IsUnsupportedIdentityType(err) ||
+		pkgerrors.IsUnknownAzureEnvironment(err) ||
+		apierrors.IsNotFound(err)
+}
+
+// workloadIdentity returns the AzureClusterIdentity of the AzureCluster, or nil when the
+// AzureCluster has no identity with workload identity.
+func (r *ProviderConfigReconciler) workloadIdentity(ctx context.Context, azureCluster *capz.AzureCluster) (*capz.AzureClusterIdentity, error) {
+	if azureCluster.Spec.IdentityRef == nil || azureCluster.Spec.IdentityRef.Kind != capz.AzureClusterIdentityKind {
+		return nil, nil
+	}
+
+	identity := new(capz.AzureClusterIdentity)
+	err := r.client.Get(ctx, types.NamespacedName{Namespace: azureCluster.Namespace, Name: azureCluster.Spec.IdentityRef.Name}, identity)
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if identity.Spec.Type != capz.WorkloadIdentity {
+		return nil, nil
+	}
+
+	return identity, nil
+}
+
 func (r *ProviderConfigReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	// The ProviderConfigs are generated from the infrastructure cluster or control plane, the
+	// identity and the ASO credential Secret of the Cluster, so changing any of them, e.g. rotating
+	// the client ID, updates the ProviderConfigs of the Clusters that use them.
+	clusterRequests := handler.EnqueueRequestsFromMapFunc(r.ClusterRequestsFor)
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&capi.Cluster{}).
+		Watches(&capz.AzureCluster{}, clusterRequests).
+		Watches(&capz.AzureManagedControlPlane{}, clusterRequests).
+		Watches(&capz.AzureASOManagedControlPlane{}, clusterRequests).
+		Watches(&capz.AzureClusterIdentity{}, clusterRequests).
+		Watches(&corev1.Secret{}, clusterRequests).
 		Complete(r)
 }
 
+// ClusterRequestsFor returns the requests for the Clusters whose ProviderConfigs are generated from
+// the object: the Clusters that reference an AzureCluster, AzureManagedControlPlane or
+// AzureASOManagedControlPlane, and the Clusters whose infrastructure cluster or control plane
+// reference an AzureClusterIdentity or ASO credential Secret. Objects are only matched within the
+// namespace of the Cluster, like in Reconcile, except for the client secrets of service principal
+// identities, which are mapped to the Clusters of the identities.
+func (r *ProviderConfigReconciler) ClusterRequestsFor(ctx context.Context, obj client.Object) []reconcile.Request {
+	logger := log.FromContext(ctx)
+	namespace := obj.GetNamespace()
+	name := obj.GetName()
+
+	listOptions := []client.ListOption{client.InNamespace(namespace)}
+	var requests []reconcile.Request
+	var matches func(cluster *capi.Cluster) bool
+	switch obj.(type) {
+	case *capz.AzureCluster:
+		matches = func(cluster *capi.Cluster) bool {
+			return refersTo(cluster.Spec.InfrastructureRef, capz.AzureClusterKind, name)
+		}
+
+	case *capz.AzureManagedControlPlane:
+		matches = func(cluster *capi.Cluster) bool {
+			return refersTo(cluster.Spec.ControlPlaneRef, capz.AzureManagedControlPlaneKind, name)
+		}
+
+	case *capz.AzureASOManagedControlPlane:
+		matches = func(cluster *capi.Cluster) bool {
+			return refersTo(cluster.Spec.ControlPlaneRef, capz.AzureASOManagedControlPlaneKind, name)
+		}
+
+	case *capz.AzureClusterIdentity:
+		azureClusters := map[string]bool{}
+		var azureClusterList capz.AzureClusterList
+		if err := r.client.List(ctx, &azureClusterList, client.InNamespace(namespace)); err != nil {
+			logger.Error(err, "failed to list infraclusters", "namespace", namespace)
+			return nil
+		}
+		for _, azureCluster := range azureClusterList.Items {
+			if identityRef := azureCluster.Spec.IdentityRef; identityRef != nil &&
+				identityRef.Kind == capz.AzureClusterIdentityKind && identityRef.Name == name {
+				azureClusters[azureCluster.Name] = true
+			}
+		}
+
+		controlPlanes := map[string]bool{}
+		var controlPlaneList capz.AzureManagedControlPlaneList
+		if err := r.client.List(ctx, &controlPlaneList, client.InNamespace(namespace)); err != nil {
+			logger.Error(err, "failed to list controlplanes", "namespace", namespace)
+			return nil
+		}
+		for _, controlPlane := range controlPlaneList.Items {
+			if identityRef := controlPlane.Spec.IdentityRef; identityRef != nil &&
+				identityRef.Kind == capz.AzureClusterIdentityKind && identityRef.Name == name {
+				controlPlanes[controlPlane.Name] = true
+			}
+		}
+
+		matches = func(cluster *capi.Cluster) bool {
+			infraRef, controlPlaneRef := cluster.Spec.InfrastructureRef, cluster.Spec.ControlPlaneRef
+			return (infraRef.Kind == capz.AzureClusterKind && azureClusters[infraRef.Name]) ||
+				(controlPlaneRef.Kind == capz.AzureManagedControlPlaneKind && controlPlanes[controlPlaneRef.Name])
+		}
+
+	case *corev1.Secret:
+		// The client secret of service principal identities can be in any namespace.
+		var identities capz.AzureClusterIdentityList
+		if err := r.client.List(ctx, &identities); err != nil {
+			logger.Error(err, "failed to list identities")
+			return nil
+		}
+		for i := range identities.Items {
+			identity := &identities.Items[i]
+			if identity.Spec.Type != capz.ServicePrincipal && identity.Spec.Type != capz.ManualServicePrincipal {
+				continue
+			}
+			if identityClientSecretName(identity) == client.ObjectKeyFromObject(obj) {
+				requests = append(requests, r.ClusterRequestsFor(ctx, identity)...)
+			}
+		}
+
+		// ASO uses the global Secret for the control planes of all namespaces without the
+		// credential-from annotation and the namespace Secret.
+		isGlobal := client.ObjectKeyFromObject(obj) == r.asoGlobalCredentialSecret
+		if isGlobal {
+			listOptions = nil
+		}
+		controlPlanes := map[types.NamespacedName]bool{}
+		var controlPlaneList capz.AzureASOManagedControlPlaneList
+		if err := r.client.List(ctx, &controlPlaneList, listOptions...); err != nil {
+			logger.Error(err, "failed to list controlplanes", "namespace", namespace)
+			return requests
+		}
+		for i := range controlPlaneList.Items {
+			controlPlane := &controlPlaneList.Items[i]
+			credentialFrom, err := asoCredentialFrom(ctx, controlPlane)
+			if err != nil {
+				continue
+			}
+			var uses bool
+			if credentialFrom != "" {
+				uses = controlPlane.Namespace == namespace && credentialFrom == name
+			} else {
+				// Creating or deleting the namespace Secret switches between the namespace and the
+				// global Secret.
+				uses = isGlobal || (controlPlane.Namespace == namespace && name == ASONamespaceCredentialSecretName)
+			}
+			if uses {
+				controlPlanes[client.ObjectKeyFromObject(controlPlane)] = true
+			}
+		}
+
+		// The generators reference the CAPI kubeconfig Secret of the Cluster.
+		var kubeconfigOf string
+		if len(r.generators) > 0 {
+			kubeconfigOf, _ = strings.CutSuffix(name, "-"+string(secret.Kubeconfig))
+			if kubeconfigOf == name {
+				kubeconfigOf = ""
+			}
+		}
+
+		if len(controlPlanes) == 0 && kubeconfigOf == "" {
+			return requests
+		}
+
+		matches = func(cluster *capi.Cluster) bool {
+			if kubeconfigOf != "" && cluster.Namespace == namespace && cluster.Name == kubeconfigOf {
+				return true
+			}
+			controlPlaneRef := cluster.Spec.ControlPlaneRef
+			return controlPlaneRef.Kind == capz.AzureASOManagedControlPlaneKind &&
+				controlPlanes[types.NamespacedName{Namespace: cluster.Namespace, Name: controlPlaneRef.Name}]
+		}
+
+	default:
+		return nil
+	}
+
+	var clusters capi.ClusterList
+	if err := r.client.List(ctx, &clusters, listOptions...); err != nil {
+		logger.Error(err, "failed to list clusters", "namespace", namespace)
+		return requests
+	}
+
+	for i := range clusters.Items {
+		if matches(&clusters.Items[i]) {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&clusters.Items[i])})
+		}
+	}
+	return requests
+}
+
+func refersTo(ref capi.ContractVersionedObjectReference, kind, name string) bool {
+	return ref.Kind == kind && ref.Name == name
+}
+
+// deleteProviderConfigs deletes the ProviderConfigs of the Cluster of all kinds, including the
+// cluster-scoped ProviderConfig with the name of earlier versions, so that no credentials are left
+// behind when the mode was changed.
+func (r *ProviderConfigReconciler) deleteProviderConfigs(ctx context.Context, clusterName types.NamespacedName) error {
+	providerConfigName, err := r.providerConfigName(clusterName)
+	if err != nil {
+		return err
+	}
+
+	providerConfigs := []*unstructured.Unstructured{
+		NewProviderConfig(providerConfigName),
+		NewNamespacedProviderConfig(clusterName.Namespace, clusterName.Name),
+	}
+	if providerConfigName != clusterName.Name {
+		providerConfigs = append(providerConfigs, NewProviderConfig(clusterName.Name))
+	}
+	if r.azureAD {
+		providerConfigs = append(providerConfigs,
+			NewAzureADProviderConfig(providerConfigName),
+			NewNamespacedAzureADProviderConfig(clusterName.Namespace, clusterName.Name),
+		)
+	}
+	for _, generator := range r.generators {
+		providerConfigs = append(providerConfigs, generator.NewProviderConfig(providerConfigName))
+	}
+	for _, providerConfig := range providerConfigs {
+		if err = r.deleteProviderConfigOfCluster(ctx, providerConfig, clusterName); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteProviderConfigOfCluster deletes the ProviderConfig, if it is labelled with the Cluster.
+func (r *ProviderConfigReconciler) deleteProviderConfigOfCluster(ctx context.Context, providerConfig *unstructured.Unstructured, clusterName types.NamespacedName) error {
+	err := r.client.Get(ctx, client.ObjectKeyFromObject(providerConfig), providerConfig)
+	if apierrors.IsNotFound(err) || metaerr.IsNoMatchError(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	owner, ok := ProviderConfigCluster(providerConfig)
+	if !ok || owner != clusterName {
+		return nil
+	}
+
+	log.FromContext(ctx).Info("deleting provider config", "kind", providerConfig.GroupVersionKind(), "namespace", providerConfig.GetNamespace(), "name", providerConfig.GetName())
+	return client.IgnoreNotFound(r.client.Delete(ctx, providerConfig))
+}
+
+// providerConfigName returns the name of the ProviderConfig of the Cluster from the name template.
+func (r *ProviderConfigReconciler) providerConfigName(clusterName types.NamespacedName) (string, error) {
+	var name strings.Builder
+	if err := r.nameTemplate.Execute(&name, clusterName); err != nil {
+		return "", fmt.Errorf("failed to execute provider config name template: %w", err)
+	}
+	if errs := validation.IsDNS1123Subdomain(name.String()); len(errs) > 0 {
+		return "", fmt.Errorf("invalid provider config name %q for cluster %s: %s", name.String(), clusterName, strings.Join(errs, ", "))
+	}
+	return name.String(), nil
+}
+
+// discoverProviderConfigMode returns the mode that creates the ProviderConfigs whose CRDs are
+// installed. Without any ProviderConfig CRD, the cluster-scoped ProviderConfigs of earlier versions
+// are created.
+func discoverProviderConfigMode(mapper metaerr.RESTMapper) (ProviderConfigMode, error) {
+	isInstalled := func(gvk schema.GroupVersionKind) (bool, error) {
+		_, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+		if metaerr.IsNoMatchError(err) {
+			return false, nil
+		} else if err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+
+	clusterScoped, err := isInstalled(NewProviderConfig("").GroupVersionKind())
+	if err != nil {
+		return "", err
+	}
+	namespaced, err := isInstalled(NewNamespacedProviderConfig("", "").GroupVersionKind())
+	if err != nil {
+		return "", err
+	}
+
+	switch {
+	case clusterScoped && namespaced:
+		return ProviderConfigModeBoth, nil
+	case namespaced:
+		return ProviderConfigModeNamespaced, nil
+	default:
+		return ProviderConfigModeClusterScoped, nil
+	}
+}
+
+func setProviderConfigClusterLabels(providerConfig *unstructured.Unstructured, cluster *capi.Cluster) {
+	labels := providerConfig.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labels[ProviderConfigClusterNameLabel] = cluster.Name
+	labels[ProviderConfigClusterNamespaceLabel] = cluster.Namespace
+	providerConfig.SetLabels(labels)
+}
+
+// providerConfigSpec returns the spec of the ProviderConfigs with the credentials source of the
+// identity type. Service principals use the credentials Secret of the Cluster.
+func (r *ProviderConfigReconciler) providerConfigSpec(cluster *capi.Cluster, info identityInfo) map[string]any {
+	if info.isServicePrincipal() {
+		return map[string]any{
+			"credentials": map[string]any{
+				"source": CredentialsSourceSecret,
+				"secretRef": map[string]any{
+					"namespace": cluster.Namespace,
+					"name":      CredentialsSecretName(cluster.Name),
+					"key":       CredentialsSecretKey,
+				},
+			},
+		}
+	}
+
+	source := CredentialsSourceUserAssignedManagedIdentity
+	if info.Type == capz.WorkloadIdentity {
+		source = r.workloadIdentitySource
+	}
+	return map[string]any{
+		"credentials": map[string]any{
+			"source": source,
+		},
+		"clientID":       info.ClientID,
+		"subscriptionID": info.SubscriptionID,
+		"tenantID":       info.TenantID,
+	}
+}
+
+// azureADProviderConfigSpec returns the spec of the Azure AD ProviderConfigs. It is the spec of the
+// Azure ProviderConfigs without the subscription, which Azure AD does not have.
+func (r *ProviderConfigReconciler) azureADProviderConfigSpec(cluster *capi.Cluster, info identityInfo) map[string]any {
+	spec := r.providerConfigSpec(cluster, info)
+	delete(spec, "subscriptionID")
+	return spec
+}
+
+// reconcileCredentialsSecret creates or updates the Crossplane credentials Secret of a Cluster
+// with a service principal identity. It is owned by the Cluster, so it is garbage collected with
+// the Cluster. Existing Secrets that are not labelled with the Cluster are never overwritten, as
+// they may hold the credentials of another Cluster or of another application.
+func (r *ProviderConfigReconciler) reconcileCredentialsSecret(ctx context.Context, cluster *capi.Cluster, info identityInfo) error {
+	credentials, err := json.Marshal(map[string]string{
+		"clientId":       info.ClientID,
+		"clientSecret":   info.ClientSecret,
+		"subscriptionId": info.SubscriptionID,
+		"tenantId":       info.TenantID,
+	})
+	if err != nil {
+		return err
+	}
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: cluster.Namespace,
+			Name:      CredentialsSecretName(cluster.Name),
+		},
+	}
+	clusterName := client.ObjectKeyFromObject(cluster)
+	_, err = controllerutil.CreateOrPatch(ctx, r.client, secret, func() error {
+		if owner, ok := ProviderConfigCluster(secret); ok && owner != clusterName {
+			return fmt.Errorf("%w: %s/%s is owned by cluster %s", ErrCredentialsSecretNotOwned, secret.Namespace, secret.Name, owner)
+		} else if !ok && secret.ResourceVersion != "" {
+			return fmt.Errorf("%w: %s/%s is not labelled with a cluster", ErrCredentialsSecretNotOwned, secret.Namespace, secret.Name)
+		}
+
+		if secret.Labels == nil {
+			secret.Labels = map[string]string{}
+		}
+		secret.Labels[ProviderConfigClusterNameLabel] = cluster.Name
+		secret.Labels[ProviderConfigClusterNamespaceLabel] = cluster.Namespace
+		secret.Data = map[string][]byte{
+			CredentialsSecretKey: credentials,
+		}
+		return controllerutil.SetOwnerReference(cluster, secret, r.client.Scheme())
+	})
+	if err != nil {
+		log.FromContext(ctx).Error(err, "failed to create or patch credentials secret", "namespace", secret.Namespace, "name", secret.Name)
+		return err
+	}
+
+	return nil
+}
+
+// asoCredentialSecretName returns the name of the Secret with the credentials that ASO uses for
+// the resources of the control plane, following the ASO conventions: the Secret in the
+// credential-from annotation of the resources, the aso-credential Secret in the namespace, or the
+// global ASO Secret.
+func (r *ProviderConfigReconciler) asoCredentialSecretName(ctx context.Context, cp *capz.AzureASOManagedControlPlane) (types.NamespacedName, error) {
+	credentialFrom, err := asoCredentialFrom(ctx, cp)
+	if err != nil {
+		return types.NamespacedName{}, err
+	}
+	if credentialFrom != "" {
+		return types.NamespacedName{Namespace: cp.Namespace, Name: credentialFrom}, nil
+	}
+
+	namespaceSecretName := types.NamespacedName{Namespace: cp.Namespace, Name: ASONamespaceCredentialSecretName}
+	err = r.client.Get(ctx, namespaceSecretName, new(corev1.Secret))
+	if apierrors.IsNotFound(err) {
+		return r.asoGlobalCredentialSecret, nil
+	} else if err != nil {
+		return types.NamespacedName{}, err
+	}
+
+	return namespaceSecretName, nil
+}
+
+// asoCredentialFrom returns the credential-from annotation of the ASO resources of the control
+// plane, or "" when no resource has it. The ResourceGroup and the ManagedCluster are preferred,
+// as the other resources are created in them.
+func asoCredentialFrom(ctx context.Context, cp *capz.AzureASOManagedControlPlane) (string, error) {
+	resources, err := mutators.ToUnstructured(ctx, cp.Spec.Resources)
+	if err != nil {
+		return "", err
+	}
+
+	var credentialFrom string
+	for _, resource := range resources {
+		name := resource.GetAnnotations()[asoCredentialFromAnnotation]
+		if name == "" {
+			continue
+		}
+		switch resource.GetKind() {
+		case "ResourceGroup", "ManagedCluster":
+			return name, nil
+		}
+		if credentialFrom == "" {
+			credentialFrom = name
+		}
+	}
+	return credentialFrom, nil
+}
+
+// CredentialsSecretName returns the name of the Crossplane credentials Secret of the Cluster.
+func CredentialsSecretName(clusterName string) string {
+	return clusterName + "-crossplane-azure-credentials"
+}
+
+// ProviderConfigCluster returns the Cluster that the ProviderConfig was generated for, and false
+// when the ProviderConfig is not labelled with a Cluster.
+func ProviderConfigCluster(providerConfig client.Object) (types.NamespacedName, bool) {
+	labels := providerConfig.GetLabels()
+	clusterName := types.NamespacedName{
+		Namespace: labels[ProviderConfigClusterNamespaceLabel],
+		Name:      labels[ProviderConfigClusterNameLabel],
+	}
+	return clusterName, clusterName.Namespace != "" && clusterName.Name != ""
+}
+
 type identityInfo struct {
 	Type           capz.IdentityType
 	TenantID       string
 	SubscriptionID string
 	ClientID       string
+	// ClientSecret is only set for service principals.
+	ClientSecret string
+}
+
+func (i identityInfo) isServicePrincipal() bool {
+	return i.Type == capz.ServicePrincipal || i.Type == capz.ManualServicePrincipal
+}
+
+// identityClientSecretName returns the name of the Secret with the client secret of the
+// AzureClusterIdentity. It defaults to the namespace of the identity.
+func identityClientSecretName(identity *capz.AzureClusterIdentity) types.NamespacedName {
+	name := types.NamespacedName{
+		Namespace: identity.Spec.ClientSecret.Namespace,
+		Name:      identity.Spec.ClientSecret.Name,
+	}
+	if name.Namespace == "" {
+		name.Namespace = identity.Namespace
+	}
+	return name
 }
 
 // NewProviderConfig returns an [unstructured.Unstructured] prepared for use as a ProviderConfig.
@@ -255,3 +1362,44 @@ func NewProviderConfig(name string) *unstructured.Unstructured {
 	providerConfig.SetName(name)
 	return providerConfig
 }
+
+// NewNamespacedProviderConfig returns an [unstructured.Unstructured] prepared for use as a
+// namespaced ProviderConfig of Crossplane v2.
+func NewNamespacedProviderConfig(namespace, name string) *unstructured.Unstructured {
+	providerConfig := new(unstructured.Unstructured)
+	providerConfig.SetGroupVersionKind(schema.GroupVersionKind{
+		Group:   "azure.m.upbound.io",
+		Version: "v1beta1",
+		Kind:    "ProviderConfig",
+	})
+	providerConfig.SetNamespace(namespace)
+	providerConfig.SetName(name)
+	return providerConfig
+}
+
+// NewAzureADProviderConfig returns an [unstructured.Unstructured] prepared for use as an Azure AD
+// ProviderConfig.
+func NewAzureADProviderConfig(name string) *unstructured.Unstructured {
+	providerConfig := new(unstructured.Unstructured)
+	providerConfig.SetGroupVersionKind(schema.GroupVersionKind{
+		Group:   "azuread.upbound.io",
+		Version: "v1beta1",
+		Kind:    "ProviderConfig",
+	})
+	providerConfig.SetName(name)
+	return providerConfig
+}
+
+// NewNamespacedAzureADProviderConfig returns an [unstructured.Unstructured] prepared for use as a
+// namespaced Azure AD ProviderConfig of Crossplane v2.
+func NewNamespacedAzureADProviderConfig(namespace, name string) *unstructured.Unstructured {
+	providerConfig := new(unstructured.Unstructured)
+	providerConfig.SetGroupVersionKind(schema.GroupVersionKind{
+		Group:   "azuread.m.upbound.io",
+		Version: "v1beta1",
+		Kind:    "ProviderConfig",
+	})
+	providerConfig.SetNamespace(namespace)
+	providerConfig.SetName(name)
+	return providerConfig
+}
@@ -2,25 +2,89 @@ package controllers_test
 
 import (
 	"context"
+	"encoding/json"
 
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/msi/armmsi"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"go.uber.org/mock/gomock"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/clientcmd"
+	k8sevents "k8s.io/client-go/tools/events"
+	capz "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	capi "sigs.k8s.io/cluster-api/api/core/v1beta2"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	. "sigs.k8s.io/controller-runtime/pkg/envtest/komega"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	"github.com/giantswarm/azure-private-endpoint-operator/controllers"
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/azure"
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/azure/mock_azure"
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/dryrun"
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/events"
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/privatelinks"
 	. "github.com/giantswarm/azure-private-endpoint-operator/pkg/testhelpers"
 )
 
 var _ = Describe("CrossplaneProviderConfigReconciler", func() {
+	providerConfigName := func(req reconcile.Request) string {
+		return req.Name
+	}
+	renamingOptions := &controllers.ProviderConfigReconcilerOptions{
+		NameTemplate:                "{{ .Namespace }}-{{ .Name }}",
+		DeleteLegacyProviderConfigs: true,
+	}
+	renamedProviderConfigName := func(req reconcile.Request) string {
+		return req.Namespace + "-" + req.Name
+	}
+
+	var fakeRecorder *k8sevents.FakeRecorder
+	var recorder *events.Recorder
+
+	BeforeEach(func() {
+		fakeRecorder = k8sevents.NewFakeRecorder(100)
+		var err error
+		recorder, err = events.NewRecorder(fakeRecorder, events.DefaultDeduplicationWindow)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
 	Describe("Constructor", func() {
 		It("creates reconciler", func() {
-			r, err := controllers.NewProviderConfigReconciler(k8sClient)
+			r, err := controllers.NewProviderConfigReconciler(k8sClient, recorder, nil)
 			Expect(err).To(BeNil())
 			Expect(r).ToNot(BeNil())
 		})
 
 		It("fails to create a reconciler when the client is nil", func() {
-			r, err := controllers.NewProviderConfigReconciler(nil)
+			r, err := controllers.NewProviderConfigReconciler(nil, recorder, nil)
+			Expect(err).ToNot(BeNil())
+			Expect(r).To(BeNil())
+		})
+
+		It("fails to create a reconciler when the recorder is nil", func() {
+			r, err := controllers.NewProviderConfigReconciler(k8sClient, nil, nil)
+			Expect(err).ToNot(BeNil())
+			Expect(r).To(BeNil())
+		})
+
+		It("fails to create a reconciler when the mode is not supported", func() {
+			r, err := controllers.NewProviderConfigReconciler(k8sClient, recorder, &controllers.ProviderConfigReconcilerOptions{
+				Mode: "Invalid",
+			})
+			Expect(err).ToNot(BeNil())
+			Expect(r).To(BeNil())
+		})
+
+		It("fails to create a reconciler when the name template does not create valid names", func() {
+			r, err := controllers.NewProviderConfigReconciler(k8sClient, recorder, &controllers.ProviderConfigReconcilerOptions{
+				NameTemplate: "{{ .Namespace }}/{{ .Name }}",
+			})
 			Expect(err).ToNot(BeNil())
 			Expect(r).To(BeNil())
 		})
@@ -39,7 +103,11 @@ var _ = Describe("CrossplaneProviderConfigReconciler", func() {
 				Build()
 			cluster := NewClusterBuilder(namespace, "foo").WithAzureCluster(azureCluster).Build()
 
-			want := controllers.NewProviderConfig(req.Name)
+			want := controllers.NewProviderConfig(providerConfigName(req))
+			want.SetLabels(map[string]string{
+				controllers.ProviderConfigClusterNameLabel:      req.Name,
+				controllers.ProviderConfigClusterNamespaceLabel: req.Namespace,
+			})
 			want.Object["spec"] = map[string]any{
 				"credentials": map[string]any{
 					"source": "UserAssignedManagedIdentity",
@@ -51,14 +119,14 @@ var _ = Describe("CrossplaneProviderConfigReconciler", func() {
 
 			CreateObjects(ctx, azureClusterIdentity, azureCluster, cluster)
 
-			r, err := controllers.NewProviderConfigReconciler(k8sClient)
+			r, err := controllers.NewProviderConfigReconciler(k8sClient, recorder, nil)
 			Expect(err).To(BeNil())
 
 			_, err = r.Reconcile(context.Background(), req)
 			Expect(err).To(BeNil())
 
-			got := controllers.NewProviderConfig(req.Name)
-			err = k8sClient.Get(ctx, req.NamespacedName, got)
+			got := controllers.NewProviderConfig(providerConfigName(req))
+			err = k8sClient.Get(ctx, client.ObjectKeyFromObject(got), got)
 			Expect(err).To(BeNil())
 			Expect(got).To(EqualObject(want, IgnorePaths{
 				"metadata.creationTimestamp",
@@ -67,6 +135,440 @@ var _ = Describe("CrossplaneProviderConfigReconciler", func() {
 				"metadata.resourceVersion",
 				"metadata.uid",
 			}))
+
+			GetObjects(ctx, cluster)
+			Expect(conditions.IsTrue(cluster, controllers.ProviderConfigReadyCondition)).To(BeTrue())
+			Expect(fakeRecorder.Events).To(Receive(ContainSubstring(events.ReasonProviderConfigReady)))
+		})
+
+		It("refuses to overwrite a ProviderConfig owned by another Cluster", func(ctx context.Context) {
+			req := Request(namespace, "colliding")
+
+			azureClusterIdentity := NewAzureClusterIdentityBuilder(namespace, "colliding").
+				WithTenantID("123").
+				WithClientID("456").
+				Build()
+			azureCluster := NewAzureClusterBuilder(namespace, "colliding").
+				WithIdentity(azureClusterIdentity).
+				Build()
+			cluster := NewClusterBuilder(namespace, "colliding").WithAzureCluster(azureCluster).Build()
+			otherProviderConfig := controllers.NewProviderConfig(req.Name)
+			otherProviderConfig.SetLabels(map[string]string{
+				controllers.ProviderConfigClusterNameLabel:      req.Name,
+				controllers.ProviderConfigClusterNamespaceLabel: "org-other",
+			})
+			otherProviderConfig.Object["spec"] = map[string]any{
+				"credentials": map[string]any{
+					"source": "UserAssignedManagedIdentity",
+				},
+				"clientID": "other",
+			}
+
+			CreateObjects(ctx, otherProviderConfig, azureClusterIdentity, azureCluster, cluster)
+
+			r, err := controllers.NewProviderConfigReconciler(k8sClient, recorder, &controllers.ProviderConfigReconcilerOptions{
+				NameTemplate: "{{ .Name }}",
+			})
+			Expect(err).To(BeNil())
+
+			_, err = r.Reconcile(ctx, req)
+			Expect(err).To(MatchError(controllers.ErrProviderConfigOwnedByOtherCluster))
+
+			GetObjects(ctx, otherProviderConfig)
+			Expect(otherProviderConfig.Object["spec"]).To(HaveKeyWithValue("clientID", "other"))
+		})
+
+		It("renames the ProviderConfig of earlier versions", func(ctx context.Context) {
+			req := Request(namespace, "legacy")
+
+			azureClusterIdentity := NewAzureClusterIdentityBuilder(namespace, "legacy").
+				WithTenantID("123").
+				WithClientID("456").
+				Build()
+			azureCluster := NewAzureClusterBuilder(namespace, "legacy").
+				WithIdentity(azureClusterIdentity).
+				Build()
+			cluster := NewClusterBuilder(namespace, "legacy").WithAzureCluster(azureCluster).Build()
+			legacyProviderConfig := controllers.NewProviderConfig(req.Name)
+			legacyProviderConfig.SetLabels(map[string]string{
+				controllers.ProviderConfigClusterNameLabel:      req.Name,
+				controllers.ProviderConfigClusterNamespaceLabel: req.Namespace,
+			})
+			legacyProviderConfig.Object["spec"] = map[string]any{
+				"credentials": map[string]any{
+					"source": "UserAssignedManagedIdentity",
+				},
+			}
+
+			CreateObjects(ctx, legacyProviderConfig, azureClusterIdentity, azureCluster, cluster)
+
+			r, err := controllers.NewProviderConfigReconciler(k8sClient, recorder, renamingOptions)
+			Expect(err).To(BeNil())
+
+			_, err = r.Reconcile(ctx, req)
+			Expect(err).To(BeNil())
+
+			Expect(Get(controllers.NewProviderConfig(renamedProviderConfigName(req)))()).To(Succeed())
+			Eventually(Get(legacyProviderConfig)).ShouldNot(Succeed())
+		})
+
+		It("adopts and renames the unlabelled ProviderConfig of earlier versions", func(ctx context.Context) {
+			req := Request(namespace, "unlabelled")
+
+			azureClusterIdentity := NewAzureClusterIdentityBuilder(namespace, "unlabelled").
+				WithTenantID("123").
+				WithClientID("456").
+				Build()
+			azureCluster := NewAzureClusterBuilder(namespace, "unlabelled").
+				WithIdentity(azureClusterIdentity).
+				Build()
+			cluster := NewClusterBuilder(namespace, "unlabelled").
+				WithFinalizers(controllers.ProviderConfigControllerFinalizer).
+				WithAzureCluster(azureCluster).
+				Build()
+			// The ProviderConfig as it was created by earlier versions.
+			legacyProviderConfig := controllers.NewProviderConfig(req.Name)
+			legacyProviderConfig.Object["spec"] = map[string]any{
+				"credentials": map[string]any{
+					"source": "UserAssignedManagedIdentity",
+				},
+				"clientID":       azureClusterIdentity.Spec.ClientID,
+				"subscriptionID": azureCluster.Spec.SubscriptionID,
+				"tenantID":       azureClusterIdentity.Spec.TenantID,
+			}
+
+			CreateObjects(ctx, legacyProviderConfig, azureClusterIdentity, azureCluster, cluster)
+
+			r, err := controllers.NewProviderConfigReconciler(k8sClient, recorder, renamingOptions)
+			Expect(err).To(BeNil())
+
+			_, err = r.Reconcile(ctx, req)
+			Expect(err).To(BeNil())
+
+			Expect(Get(controllers.NewProviderConfig(renamedProviderConfigName(req)))()).To(Succeed())
+			Eventually(Get(legacyProviderConfig)).ShouldNot(Succeed())
+
+			GetObjects(ctx, cluster)
+			Expect(cluster.Finalizers).ToNot(ContainElement(controllers.ProviderConfigControllerFinalizer))
+		})
+
+		It("does not adopt the unlabelled ProviderConfig of earlier versions with other credentials", func(ctx context.Context) {
+			req := Request(namespace, "shared-name")
+
+			azureClusterIdentity := NewAzureClusterIdentityBuilder(namespace, "shared-name").
+				WithTenantID("123").
+				WithClientID("456").
+				Build()
+			azureCluster := NewAzureClusterBuilder(namespace, "shared-name").
+				WithIdentity(azureClusterIdentity).
+				Build()
+			cluster := NewClusterBuilder(namespace, "shared-name").WithAzureCluster(azureCluster).Build()
+			// The ProviderConfig of a Cluster with the same name in another namespace.
+			otherProviderConfig := controllers.NewProviderConfig(req.Name)
+			otherProviderConfig.Object["spec"] = map[string]any{
+				"credentials": map[string]any{
+					"source": "UserAssignedManagedIdentity",
+				},
+				"clientID": "other",
+				"tenantID": azureClusterIdentity.Spec.TenantID,
+			}
+
+			CreateObjects(ctx, otherProviderConfig, azureClusterIdentity, azureCluster, cluster)
+
+			r, err := controllers.NewProviderConfigReconciler(k8sClient, recorder, renamingOptions)
+			Expect(err).To(BeNil())
+
+			_, err = r.Reconcile(ctx, req)
+			Expect(err).To(BeNil())
+
+			GetObjects(ctx, otherProviderConfig)
+			Expect(otherProviderConfig.GetLabels()).To(BeEmpty())
+		})
+
+		It("keeps the ProviderConfig of earlier versions unless its deletion is opted in", func(ctx context.Context) {
+			req := Request(namespace, "legacy-kept")
+
+			azureClusterIdentity := NewAzureClusterIdentityBuilder(namespace, "legacy-kept").
+				WithTenantID("123").
+				WithClientID("456").
+				Build()
+			azureCluster := NewAzureClusterBuilder(namespace, "legacy-kept").
+				WithIdentity(azureClusterIdentity).
+				Build()
+			cluster := NewClusterBuilder(namespace, "legacy-kept").WithAzureCluster(azureCluster).Build()
+			legacyProviderConfig := controllers.NewProviderConfig(req.Name)
+			legacyProviderConfig.SetLabels(map[string]string{
+				controllers.ProviderConfigClusterNameLabel:      req.Name,
+				controllers.ProviderConfigClusterNamespaceLabel: req.Namespace,
+			})
+			legacyProviderConfig.Object["spec"] = map[string]any{
+				"credentials": map[string]any{
+					"source": "UserAssignedManagedIdentity",
+				},
+			}
+
+			CreateObjects(ctx, legacyProviderConfig, azureClusterIdentity, azureCluster, cluster)
+
+			r, err := controllers.NewProviderConfigReconciler(k8sClient, recorder, &controllers.ProviderConfigReconcilerOptions{
+				NameTemplate: "{{ .Namespace }}-{{ .Name }}",
+			})
+			Expect(err).To(BeNil())
+
+			_, err = r.Reconcile(ctx, req)
+			Expect(err).To(BeNil())
+
+			Expect(Get(controllers.NewProviderConfig(renamedProviderConfigName(req)))()).To(Succeed())
+			Consistently(Get(legacyProviderConfig)).Should(Succeed())
+		})
+
+		It("does not adopt the unlabelled ProviderConfig of earlier versions when another Cluster has the same name", func(ctx context.Context) {
+			req := Request(namespace, "same-name")
+
+			azureClusterIdentity := NewAzureClusterIdentityBuilder(namespace, "same-name").
+				WithTenantID("123").
+				WithClientID("456").
+				Build()
+			azureCluster := NewAzureClusterBuilder(namespace, "same-name").
+				WithIdentity(azureClusterIdentity).
+				Build()
+			cluster := NewClusterBuilder(namespace, "same-name").WithAzureCluster(azureCluster).Build()
+			otherNamespace := &corev1.Namespace{}
+			otherNamespace.Name = "other-" + namespace
+			otherCluster := NewClusterBuilder(otherNamespace.Name, "same-name").WithDummyReferences().Build()
+			// The ProviderConfig as it was created by earlier versions, shared by both Clusters.
+			legacyProviderConfig := controllers.NewProviderConfig(req.Name)
+			legacyProviderConfig.Object["spec"] = map[string]any{
+				"credentials": map[string]any{
+					"source": "UserAssignedManagedIdentity",
+				},
+				"clientID":       azureClusterIdentity.Spec.ClientID,
+				"subscriptionID": azureCluster.Spec.SubscriptionID,
+				"tenantID":       azureClusterIdentity.Spec.TenantID,
+			}
+
+			CreateObjects(ctx, otherNamespace, legacyProviderConfig, azureClusterIdentity, azureCluster, cluster, otherCluster)
+			DeferCleanup(func(ctx context.Context) { DeleteObjects(ctx, otherNamespace) })
+
+			r, err := controllers.NewProviderConfigReconciler(k8sClient, recorder, renamingOptions)
+			Expect(err).To(BeNil())
+
+			_, err = r.Reconcile(ctx, req)
+			Expect(err).To(BeNil())
+
+			Expect(Get(controllers.NewProviderConfig(renamedProviderConfigName(req)))()).To(Succeed())
+			GetObjects(ctx, legacyProviderConfig)
+			Expect(legacyProviderConfig.GetLabels()).To(BeEmpty())
+		})
+
+		It("creates a namespaced ProviderConfig owned by the Cluster in namespaced mode", func(ctx context.Context) {
+			req := Request(namespace, "namespaced")
+
+			azureClusterIdentity := NewAzureClusterIdentityBuilder(namespace, "namespaced").
+				WithTenantID("123").
+				WithClientID("456").
+				Build()
+			azureCluster := NewAzureClusterBuilder(namespace, "namespaced").
+				WithIdentity(azureClusterIdentity).
+				Build()
+			cluster := NewClusterBuilder(namespace, "namespaced").WithAzureCluster(azureCluster).Build()
+
+			CreateObjects(ctx, azureClusterIdentity, azureCluster, cluster)
+
+			r, err := controllers.NewProviderConfigReconciler(k8sClient, recorder, &controllers.ProviderConfigReconcilerOptions{
+				Mode: controllers.ProviderConfigModeNamespaced,
+			})
+			Expect(err).To(BeNil())
+
+			_, err = r.Reconcile(ctx, req)
+			Expect(err).To(BeNil())
+
+			got := controllers.NewNamespacedProviderConfig(req.Namespace, req.Name)
+			GetObjects(ctx, got)
+			Expect(got.GetLabels()).To(HaveKeyWithValue(controllers.ProviderConfigClusterNamespaceLabel, req.Namespace))
+			Expect(got.GetOwnerReferences()).To(ConsistOf(HaveField("UID", cluster.UID)))
+			Expect(got.Object["spec"]).To(HaveKeyWithValue("clientID", azureClusterIdentity.Spec.ClientID))
+			Expect(Get(controllers.NewProviderConfig(providerConfigName(req)))()).ToNot(Succeed())
+		})
+
+		It("creates Azure AD ProviderConfigs alongside the Azure ProviderConfigs", func(ctx context.Context) {
+			req := Request(namespace, "azuread")
+
+			azureClusterIdentity := NewAzureClusterIdentityBuilder(namespace, "azuread").
+				WithTenantID("123").
+				WithClientID("456").
+				Build()
+			azureCluster := NewAzureClusterBuilder(namespace, "azuread").
+				WithIdentity(azureClusterIdentity).
+				Build()
+			cluster := NewClusterBuilder(namespace, "azuread").WithAzureCluster(azureCluster).Build()
+
+			CreateObjects(ctx, azureClusterIdentity, azureCluster, cluster)
+
+			r, err := controllers.NewProviderConfigReconciler(k8sClient, recorder, &controllers.ProviderConfigReconcilerOptions{
+				Mode:    controllers.ProviderConfigModeBoth,
+				AzureAD: true,
+			})
+			Expect(err).To(BeNil())
+
+			_, err = r.Reconcile(ctx, req)
+			Expect(err).To(BeNil())
+
+			wantSpec := map[string]any{
+				"credentials": map[string]any{
+					"source": "UserAssignedManagedIdentity",
+				},
+				"clientID": azureClusterIdentity.Spec.ClientID,
+				"tenantID": azureClusterIdentity.Spec.TenantID,
+			}
+
+			got := controllers.NewAzureADProviderConfig(providerConfigName(req))
+			GetObjects(ctx, got)
+			Expect(got.GetLabels()).To(HaveKeyWithValue(controllers.ProviderConfigClusterNameLabel, req.Name))
+			Expect(got.Object["spec"]).To(Equal(wantSpec))
+
+			gotNamespaced := controllers.NewNamespacedAzureADProviderConfig(req.Namespace, req.Name)
+			GetObjects(ctx, gotNamespaced)
+			Expect(gotNamespaced.GetOwnerReferences()).To(ConsistOf(HaveField("UID", cluster.UID)))
+			Expect(gotNamespaced.Object["spec"]).To(Equal(wantSpec))
+
+			Expect(k8sClient.Delete(ctx, cluster)).To(Succeed())
+			_, err = r.Reconcile(ctx, req)
+			Expect(err).To(BeNil())
+			Expect(Get(controllers.NewAzureADProviderConfig(providerConfigName(req)))()).ToNot(Succeed())
+		})
+
+		It("creates a ProviderConfig with a credentials Secret for a service principal", func(ctx context.Context) {
+			req := Request(namespace, "serviceprincipal")
+
+			clientSecret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: "serviceprincipal-client-secret"},
+				Data:       map[string][]byte{"clientSecret": []byte("s3cr3t")},
+			}
+			azureClusterIdentity := NewAzureClusterIdentityBuilder(namespace, "serviceprincipal").
+				WithType(capz.ManualServicePrincipal).
+				WithTenantID("123").
+				WithClientID("456").
+				WithClientSecret(namespace, clientSecret.Name).
+				Build()
+			azureCluster := NewAzureClusterBuilder(namespace, "serviceprincipal").
+				WithIdentity(azureClusterIdentity).
+				Build()
+			cluster := NewClusterBuilder(namespace, "serviceprincipal").WithAzureCluster(azureCluster).Build()
+
+			CreateObjects(ctx, clientSecret, azureClusterIdentity, azureCluster, cluster)
+
+			r, err := controllers.NewProviderConfigReconciler(k8sClient, recorder, &controllers.ProviderConfigReconcilerOptions{
+				Mode: controllers.ProviderConfigModeClusterScoped,
+			})
+			Expect(err).To(BeNil())
+
+			_, err = r.Reconcile(ctx, req)
+			Expect(err).To(BeNil())
+
+			credentialsSecret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: controllers.CredentialsSecretName(req.Name)},
+			}
+			GetObjects(ctx, credentialsSecret)
+			Expect(credentialsSecret.GetOwnerReferences()).To(ConsistOf(HaveField("UID", cluster.UID)))
+			Expect(credentialsSecret.Data).To(HaveKey(controllers.CredentialsSecretKey))
+			var credentials map[string]string
+			Expect(json.Unmarshal(credentialsSecret.Data[controllers.CredentialsSecretKey], &credentials)).To(Succeed())
+			Expect(credentials).To(Equal(map[string]string{
+				"clientId":       "456",
+				"clientSecret":   "s3cr3t",
+				"subscriptionId": azureCluster.Spec.SubscriptionID,
+				"tenantId":       "123",
+			}))
+
+			got := controllers.NewProviderConfig(providerConfigName(req))
+			GetObjects(ctx, got)
+			Expect(got.Object["spec"]).To(Equal(map[string]any{
+				"credentials": map[string]any{
+					"source": controllers.CredentialsSourceSecret,
+					"secretRef": map[string]any{
+						"namespace": namespace,
+						"name":      credentialsSecret.Name,
+						"key":       controllers.CredentialsSecretKey,
+					},
+				},
+			}))
+		})
+
+		DescribeTable("does not overwrite credentials Secrets that are not labelled with the Cluster", func(ctx context.Context, labels map[string]string) {
+			req := Request(namespace, "foreign-secret")
+
+			clientSecret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: "foreign-secret-client-secret"},
+				Data:       map[string][]byte{"clientSecret": []byte("s3cr3t")},
+			}
+			azureClusterIdentity := NewAzureClusterIdentityBuilder(namespace, "foreign-secret").
+				WithType(capz.ManualServicePrincipal).
+				WithTenantID("123").
+				WithClientID("456").
+				WithClientSecret(namespace, clientSecret.Name).
+				Build()
+			azureCluster := NewAzureClusterBuilder(namespace, "foreign-secret").
+				WithIdentity(azureClusterIdentity).
+				Build()
+			cluster := NewClusterBuilder(namespace, "foreign-secret").WithAzureCluster(azureCluster).Build()
+			otherSecret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: namespace,
+					Name:      controllers.CredentialsSecretName(req.Name),
+					Labels:    labels,
+				},
+				Data: map[string][]byte{controllers.CredentialsSecretKey: []byte("other")},
+			}
+
+			CreateObjects(ctx, otherSecret, clientSecret, azureClusterIdentity, azureCluster, cluster)
+
+			r, err := controllers.NewProviderConfigReconciler(k8sClient, recorder, &controllers.ProviderConfigReconcilerOptions{
+				Mode: controllers.ProviderConfigModeClusterScoped,
+			})
+			Expect(err).To(BeNil())
+
+			_, err = r.Reconcile(ctx, req)
+			Expect(err).To(MatchError(controllers.ErrCredentialsSecretNotOwned))
+
+			GetObjects(ctx, otherSecret)
+			Expect(otherSecret.Data).To(HaveKeyWithValue(controllers.CredentialsSecretKey, []byte("other")))
+		},
+			Entry("unlabelled", nil),
+			Entry("labelled with another Cluster", map[string]string{
+				controllers.ProviderConfigClusterNameLabel:      "other",
+				controllers.ProviderConfigClusterNamespaceLabel: "other-namespace",
+			}),
+		)
+
+		It("creates a ProviderConfig with the OIDCTokenFile source for workload identity", func(ctx context.Context) {
+			req := Request(namespace, "oidc")
+
+			azureClusterIdentity := NewAzureClusterIdentityBuilder(namespace, "oidc").
+				WithTenantID("123").
+				WithClientID("456").
+				Build()
+			azureCluster := NewAzureClusterBuilder(namespace, "oidc").
+				WithIdentity(azureClusterIdentity).
+				Build()
+			cluster := NewClusterBuilder(namespace, "oidc").WithAzureCluster(azureCluster).Build()
+
+			CreateObjects(ctx, azureClusterIdentity, azureCluster, cluster)
+
+			r, err := controllers.NewProviderConfigReconciler(k8sClient, recorder, &controllers.ProviderConfigReconcilerOptions{
+				Mode:                   controllers.ProviderConfigModeClusterScoped,
+				WorkloadIdentitySource: controllers.CredentialsSourceOIDCTokenFile,
+			})
+			Expect(err).To(BeNil())
+
+			_, err = r.Reconcile(ctx, req)
+			Expect(err).To(BeNil())
+
+			got := controllers.NewProviderConfig(providerConfigName(req))
+			GetObjects(ctx, got)
+			Expect(got.Object["spec"]).To(HaveKeyWithValue("credentials", map[string]any{
+				"source": controllers.CredentialsSourceOIDCTokenFile,
+			}))
+			Expect(got.Object["spec"]).To(HaveKeyWithValue("clientID", "456"))
 		})
 
 		It("returns error when identityRef is unset", func(ctx context.Context) {
@@ -75,18 +577,28 @@ var _ = Describe("CrossplaneProviderConfigReconciler", func() {
 
 			CreateObjects(ctx, azureCluster, cluster)
 
-			r, err := controllers.NewProviderConfigReconciler(k8sClient)
+			r, err := controllers.NewProviderConfigReconciler(k8sClient, recorder, nil)
 			Expect(err).To(BeNil())
 
 			req := Request(namespace, "foo")
 			_, err = r.Reconcile(context.Background(), req)
 			Expect(err).To(MatchError(controllers.ErrIdentityRefUnset))
+
+			GetObjects(ctx, cluster)
+			Expect(conditions.Get(cluster, controllers.ProviderConfigReadyCondition)).To(And(
+				HaveField("Status", metav1.ConditionFalse),
+				HaveField("Reason", controllers.IdentityMissingReason),
+			))
+			Expect(fakeRecorder.Events).To(Receive(And(
+				HavePrefix(corev1.EventTypeWarning),
+				ContainSubstring(controllers.IdentityMissingReason),
+			)))
 		})
 	})
 
 	Describe("Reconciling AzureASOManagedCluster", func() {
 		It("creates a ProviderConfig", func(ctx context.Context) {
-			req := Request(namespace, "foo")
+			req := Request(namespace, "aso")
 
 			secret := NewAzureASOCredentialsSecretBuilder(req.Namespace, req.Name).
 				WithTenantID("123").
@@ -100,7 +612,11 @@ var _ = Describe("CrossplaneProviderConfigReconciler", func() {
 				WithAzureASOManagedControlPlane(azureAsoControlPlane).
 				Build()
 
-			want := controllers.NewProviderConfig(req.Name)
+			want := controllers.NewProviderConfig(providerConfigName(req))
+			want.SetLabels(map[string]string{
+				controllers.ProviderConfigClusterNameLabel:      req.Name,
+				controllers.ProviderConfigClusterNamespaceLabel: req.Namespace,
+			})
 			want.Object["spec"] = map[string]any{
 				"credentials": map[string]any{
 					"source": "UserAssignedManagedIdentity",
@@ -112,13 +628,13 @@ var _ = Describe("CrossplaneProviderConfigReconciler", func() {
 
 			CreateObjects(ctx, secret, azureAsoControlPlane, cluster)
 
-			r, err := controllers.NewProviderConfigReconciler(k8sClient)
+			r, err := controllers.NewProviderConfigReconciler(k8sClient, recorder, nil)
 			Expect(err).To(BeNil())
 
 			_, err = r.Reconcile(ctx, req)
 			Expect(err).To(BeNil())
 
-			got := controllers.NewProviderConfig(req.Name)
+			got := controllers.NewProviderConfig(providerConfigName(req))
 			GetObjects(ctx, got)
 			Expect(got).To(EqualObject(want, IgnorePaths{
 				"metadata.creationTimestamp",
@@ -128,6 +644,450 @@ var _ = Describe("CrossplaneProviderConfigReconciler", func() {
 				"metadata.uid",
 			}))
 		})
+
+		It("discovers the credential Secret when the first resource is not annotated", func(ctx context.Context) {
+			req := Request(namespace, "asodiscovery")
+
+			secret := NewAzureASOCredentialsSecretBuilder(req.Namespace, req.Name).
+				WithTenantID("123").
+				WithClientID("456").
+				WithSubscriptionID("789").
+				WithClientSecret("s3cr3t").
+				Build()
+			resourceGroup := new(unstructured.Unstructured)
+			resourceGroup.SetGroupVersionKind(schema.GroupVersionKind{
+				Group:   "resources.azure.com",
+				Version: "v1api20200601",
+				Kind:    "ResourceGroup",
+			})
+			resourceGroup.SetName(req.Name)
+			azureAsoControlPlane := NewAzureASOManagedControlPlaneBuilder(req.Namespace, req.Name).
+				WithResource(resourceGroup).
+				WithCredentialSecret(secret).
+				Build()
+			cluster := NewClusterBuilder(req.Namespace, req.Name).
+				WithAzureASOManagedControlPlane(azureAsoControlPlane).
+				Build()
+
+			CreateObjects(ctx, secret, azureAsoControlPlane, cluster)
+
+			r, err := controllers.NewProviderConfigReconciler(k8sClient, recorder, &controllers.ProviderConfigReconcilerOptions{
+				Mode: controllers.ProviderConfigModeClusterScoped,
+			})
+			Expect(err).To(BeNil())
+
+			_, err = r.Reconcile(ctx, req)
+			Expect(err).To(BeNil())
+
+			credentialsSecret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: controllers.CredentialsSecretName(req.Name)},
+			}
+			GetObjects(ctx, credentialsSecret)
+			var credentials map[string]string
+			Expect(json.Unmarshal(credentialsSecret.Data[controllers.CredentialsSecretKey], &credentials)).To(Succeed())
+			Expect(credentials).To(HaveKeyWithValue("clientSecret", "s3cr3t"))
+
+			got := controllers.NewProviderConfig(providerConfigName(req))
+			GetObjects(ctx, got)
+			Expect(got.Object["spec"]).To(HaveKeyWithValue("credentials", HaveKeyWithValue("source", controllers.CredentialsSourceSecret)))
+		})
+
+		It("prefers the credential Secret of the ManagedCluster over the one of other resources", func(ctx context.Context) {
+			req := Request(namespace, "asopreference")
+
+			secret := NewAzureASOCredentialsSecretBuilder(req.Namespace, req.Name).
+				WithTenantID("123").
+				WithClientID("456").
+				WithSubscriptionID("789").
+				WithClientSecret("s3cr3t").
+				Build()
+			otherSecret := NewAzureASOCredentialsSecretBuilder(req.Namespace, "other").
+				WithTenantID("123").
+				WithClientID("other").
+				WithSubscriptionID("789").
+				WithClientSecret("other").
+				Build()
+			identity := new(unstructured.Unstructured)
+			identity.SetGroupVersionKind(schema.GroupVersionKind{
+				Group:   "managedidentity.azure.com",
+				Version: "v1api20230131",
+				Kind:    "UserAssignedIdentity",
+			})
+			identity.SetName(req.Name)
+			identity.SetAnnotations(map[string]string{
+				"serviceoperator.azure.com/credential-from": otherSecret.Name,
+			})
+			azureAsoControlPlane := NewAzureASOManagedControlPlaneBuilder(req.Namespace, req.Name).
+				WithResource(identity).
+				WithCredentialSecret(secret).
+				Build()
+			cluster := NewClusterBuilder(req.Namespace, req.Name).
+				WithAzureASOManagedControlPlane(azureAsoControlPlane).
+				Build()
+
+			CreateObjects(ctx, secret, otherSecret, azureAsoControlPlane, cluster)
+
+			r, err := controllers.NewProviderConfigReconciler(k8sClient, recorder, &controllers.ProviderConfigReconcilerOptions{
+				Mode: controllers.ProviderConfigModeClusterScoped,
+			})
+			Expect(err).To(BeNil())
+
+			_, err = r.Reconcile(ctx, req)
+			Expect(err).To(BeNil())
+
+			credentialsSecret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: controllers.CredentialsSecretName(req.Name)},
+			}
+			GetObjects(ctx, credentialsSecret)
+			var credentials map[string]string
+			Expect(json.Unmarshal(credentialsSecret.Data[controllers.CredentialsSecretKey], &credentials)).To(Succeed())
+			Expect(credentials).To(HaveKeyWithValue("clientId", "456"))
+		})
+
+		It("falls back to the aso-credential Secret of the namespace", func(ctx context.Context) {
+			req := Request(namespace, "asonamespace")
+
+			secret := NewAzureASOCredentialsSecretBuilder(req.Namespace, controllers.ASONamespaceCredentialSecretName).
+				WithTenantID("123").
+				WithClientID("456").
+				WithSubscriptionID("789").
+				WithAuthMode("podidentity").
+				Build()
+			azureAsoControlPlane := NewAzureASOManagedControlPlaneBuilder(req.Namespace, req.Name).Build()
+			azureAsoControlPlane.Spec.Resources = nil
+			cluster := NewClusterBuilder(req.Namespace, req.Name).
+				WithAzureASOManagedControlPlane(azureAsoControlPlane).
+				Build()
+
+			CreateObjects(ctx, secret, azureAsoControlPlane, cluster)
+
+			r, err := controllers.NewProviderConfigReconciler(k8sClient, recorder, &controllers.ProviderConfigReconcilerOptions{
+				Mode: controllers.ProviderConfigModeClusterScoped,
+			})
+			Expect(err).To(BeNil())
+
+			_, err = r.Reconcile(ctx, req)
+			Expect(err).To(BeNil())
+
+			got := controllers.NewProviderConfig(providerConfigName(req))
+			GetObjects(ctx, got)
+			Expect(got.Object["spec"]).To(HaveKeyWithValue("credentials", map[string]any{
+				"source": controllers.CredentialsSourceUserAssignedManagedIdentity,
+			}))
+			Expect(got.Object["spec"]).To(HaveKeyWithValue("clientID", "456"))
+
+			Expect(r.ClusterRequestsFor(ctx, secret)).To(Equal([]reconcile.Request{req}))
+		})
+	})
+
+	Describe("Generating ProviderConfigs of other providers", func() {
+		kubeconfig := func(server string) []byte {
+			return []byte(`apiVersion: v1
+kind: Config
+clusters:
+- name: cluster
+  cluster:
+    server: ` + server + `
+contexts:
+- name: admin@cluster
+  context:
+    cluster: cluster
+    user: admin
+current-context: admin@cluster
+users:
+- name: admin
+  user:
+    token: secret
+`)
+		}
+
+		kubeconfigSecret := func(namespace, clusterName string) *corev1.Secret {
+			return &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: namespace,
+					Name:      clusterName + "-kubeconfig",
+				},
+				Data: map[string][]byte{
+					"value": kubeconfig("https://api." + clusterName + ".example.com:443"),
+				},
+			}
+		}
+
+		It("creates a provider-kubernetes ProviderConfig with the kubeconfig of the Cluster", func(ctx context.Context) {
+			req := Request(namespace, "kubernetes")
+
+			azureClusterIdentity := NewAzureClusterIdentityBuilder(namespace, "kubernetes").
+				WithTenantID("123").
+				WithClientID("456").
+				Build()
+			azureCluster := NewAzureClusterBuilder(namespace, "kubernetes").
+				WithIdentity(azureClusterIdentity).
+				Build()
+			cluster := NewClusterBuilder(namespace, "kubernetes").WithAzureCluster(azureCluster).Build()
+
+			CreateObjects(ctx, azureClusterIdentity, azureCluster, cluster, kubeconfigSecret(namespace, "kubernetes"))
+
+			generator, err := controllers.NewProviderConfigGenerator(k8sClient, controllers.ProviderConfigGeneratorKubernetes)
+			Expect(err).To(BeNil())
+			r, err := controllers.NewProviderConfigReconciler(k8sClient, recorder, &controllers.ProviderConfigReconcilerOptions{
+				Generators: []controllers.ProviderConfigGenerator{generator},
+			})
+			Expect(err).To(BeNil())
+
+			_, err = r.Reconcile(ctx, req)
+			Expect(err).To(BeNil())
+
+			got := generator.NewProviderConfig(providerConfigName(req))
+			GetObjects(ctx, got)
+			Expect(got.GetLabels()).To(HaveKeyWithValue(controllers.ProviderConfigClusterNameLabel, req.Name))
+			Expect(got.Object["spec"]).To(Equal(map[string]any{
+				"credentials": map[string]any{
+					"source": "Secret",
+					"secretRef": map[string]any{
+						"namespace": req.Namespace,
+						"name":      "kubernetes-kubeconfig",
+						"key":       "value",
+					},
+				},
+			}))
+
+			Expect(r.ClusterRequestsFor(ctx, kubeconfigSecret(namespace, "kubernetes"))).To(Equal([]reconcile.Request{req}))
+		})
+
+		It("creates a kubeconfig with the private endpoint IP for an internal API server", func(ctx context.Context) {
+			req := Request(namespace, "internal")
+
+			azureClusterIdentity := NewAzureClusterIdentityBuilder(namespace, "internal").
+				WithTenantID("123").
+				WithClientID("456").
+				Build()
+			azureCluster := NewAzureClusterBuilder(namespace, "internal").
+				WithIdentity(azureClusterIdentity).
+				WithAPILoadBalancerType(capz.Internal).
+				Build()
+			azureCluster.Annotations = map[string]string{
+				privatelinks.AzurePrivateEndpointOperatorApiServerAnnotation: "10.0.0.4",
+			}
+			cluster := NewClusterBuilder(namespace, "internal").WithAzureCluster(azureCluster).Build()
+
+			CreateObjects(ctx, azureClusterIdentity, azureCluster, cluster, kubeconfigSecret(namespace, "internal"))
+
+			generator, err := controllers.NewProviderConfigGenerator(k8sClient, controllers.ProviderConfigGeneratorHelm)
+			Expect(err).To(BeNil())
+			r, err := controllers.NewProviderConfigReconciler(k8sClient, recorder, &controllers.ProviderConfigReconcilerOptions{
+				Generators: []controllers.ProviderConfigGenerator{generator},
+			})
+			Expect(err).To(BeNil())
+
+			_, err = r.Reconcile(ctx, req)
+			Expect(err).To(BeNil())
+
+			got := generator.NewProviderConfig(providerConfigName(req))
+			GetObjects(ctx, got)
+			Expect(got.Object["spec"]).To(HaveKeyWithValue("credentials", HaveKeyWithValue("secretRef", map[string]any{
+				"namespace": req.Namespace,
+				"name":      controllers.PrivateKubeconfigSecretName(req.Name),
+				"key":       controllers.KubeconfigSecretKey,
+			})))
+
+			privateKubeconfigSecret := &corev1.Secret{}
+			privateKubeconfigSecret.Namespace, privateKubeconfigSecret.Name = req.Namespace, controllers.PrivateKubeconfigSecretName(req.Name)
+			GetObjects(ctx, privateKubeconfigSecret)
+			Expect(privateKubeconfigSecret.GetOwnerReferences()).To(ConsistOf(HaveField("UID", cluster.UID)))
+			privateKubeconfig, err := clientcmd.Load(privateKubeconfigSecret.Data[controllers.KubeconfigSecretKey])
+			Expect(err).To(BeNil())
+			Expect(privateKubeconfig.Clusters).To(HaveKeyWithValue("cluster", And(
+				HaveField("Server", "https://10.0.0.4:443"),
+				HaveField("TLSServerName", "api.internal.example.com"),
+			)))
+		})
+
+		It("fails to create a generator that is not supported", func() {
+			_, err := controllers.NewProviderConfigGenerator(k8sClient, "terraform")
+			Expect(err).NotTo(BeNil())
+		})
+	})
+
+	Describe("Managing federated identity credentials", func() {
+		var credentialsClient *mock_azure.MockFederatedIdentityCredentialsClient
+		var reconciler *controllers.ProviderConfigReconciler
+
+		wantProperties := armmsi.FederatedIdentityCredentialProperties{
+			Audiences: []*string{to.Ptr("api://AzureADTokenExchange")},
+			Issuer:    to.Ptr("https://oidc.example.com"),
+			Subject:   to.Ptr("system:serviceaccount:crossplane-system:provider-azure"),
+		}
+
+		BeforeEach(func() {
+			credentialsClient = mock_azure.NewMockFederatedIdentityCredentialsClient(gomock.NewController(GinkgoT()))
+
+			var err error
+			reconciler, err = controllers.NewProviderConfigReconciler(k8sClient, recorder, &controllers.ProviderConfigReconcilerOptions{
+				FederatedIdentityCredential: controllers.FederatedIdentityCredentialOptions{
+					Issuer:  "https://oidc.example.com",
+					Subject: "system:serviceaccount:crossplane-system:provider-azure",
+				},
+				FederatedIdentityCredentialsClientCreator: func(context.Context, client.Client, *capz.AzureCluster) (azure.FederatedIdentityCredentialsClient, error) {
+					return credentialsClient, nil
+				},
+			})
+			Expect(err).To(BeNil())
+		})
+
+		// The client IDs are unique per test, as the AzureClusters of all namespaces are checked
+		// before a credential is deleted.
+		createCluster := func(ctx context.Context, name, clientID string) *capi.Cluster {
+			azureClusterIdentity := NewAzureClusterIdentityBuilder(namespace, name).
+				WithTenantID("123").
+				WithClientID(clientID).
+				Build()
+			azureCluster := NewAzureClusterBuilder(namespace, name).
+				WithIdentity(azureClusterIdentity).
+				Build()
+			cluster := NewClusterBuilder(namespace, name).WithAzureCluster(azureCluster).Build()
+			CreateObjects(ctx, azureClusterIdentity, azureCluster, cluster)
+			return cluster
+		}
+
+		It("fails to create a reconciler without subject", func() {
+			_, err := controllers.NewProviderConfigReconciler(k8sClient, recorder, &controllers.ProviderConfigReconcilerOptions{
+				FederatedIdentityCredential: controllers.FederatedIdentityCredentialOptions{
+					Issuer: "https://oidc.example.com",
+				},
+			})
+			Expect(err).NotTo(BeNil())
+		})
+
+		It("creates the federated identity credential and deletes it with the Cluster", func(ctx context.Context) {
+			req := Request(namespace, "federated")
+			clientID := namespace
+			cluster := createCluster(ctx, "federated", clientID)
+
+			credentialsClient.EXPECT().CreateOrUpdate(gomock.Any(), clientID, "crossplane", wantProperties).Return(nil)
+
+			_, err := reconciler.Reconcile(ctx, req)
+			Expect(err).To(BeNil())
+
+			GetObjects(ctx, cluster)
+			Expect(cluster.Finalizers).To(ContainElement(controllers.FederatedIdentityCredentialFinalizer))
+
+			credentialsClient.EXPECT().Delete(gomock.Any(), clientID, "crossplane").Return(nil)
+
+			Expect(k8sClient.Delete(ctx, cluster)).To(Succeed())
+			_, err = reconciler.Reconcile(ctx, req)
+			Expect(err).To(BeNil())
+
+			Expect(Get(cluster)()).NotTo(Succeed())
+		})
+
+		It("keeps the federated identity credential while another Cluster uses the identity", func(ctx context.Context) {
+			req := Request(namespace, "shared-1")
+			clientID := namespace
+			cluster := createCluster(ctx, "shared-1", clientID)
+			createCluster(ctx, "shared-2", clientID)
+
+			credentialsClient.EXPECT().CreateOrUpdate(gomock.Any(), clientID, "crossplane", wantProperties).Return(nil)
+
+			_, err := reconciler.Reconcile(ctx, req)
+			Expect(err).To(BeNil())
+
+			Expect(k8sClient.Delete(ctx, cluster)).To(Succeed())
+			_, err = reconciler.Reconcile(ctx, req)
+			Expect(err).To(BeNil())
+
+			Expect(Get(cluster)()).NotTo(Succeed())
+		})
+
+		It("releases the Cluster when Azure rejects the deletion of the federated identity credential", func(ctx context.Context) {
+			req := Request(namespace, "rejected")
+			clientID := namespace
+			cluster := createCluster(ctx, "rejected", clientID)
+
+			credentialsClient.EXPECT().CreateOrUpdate(gomock.Any(), clientID, "crossplane", wantProperties).Return(nil)
+
+			_, err := reconciler.Reconcile(ctx, req)
+			Expect(err).To(BeNil())
+
+			credentialsClient.EXPECT().Delete(gomock.Any(), clientID, "crossplane").Return(&azcore.ResponseError{
+				StatusCode: 409,
+				ErrorCode:  "QuotaExceeded",
+			})
+
+			Expect(k8sClient.Delete(ctx, cluster)).To(Succeed())
+			_, err = reconciler.Reconcile(ctx, req)
+			Expect(err).To(BeNil())
+
+			Expect(Get(cluster)()).NotTo(Succeed())
+			Eventually(fakeRecorder.Events).Should(Receive(ContainSubstring("was not deleted")))
+		})
+
+		It("retries the deletion of the federated identity credential when Azure is unavailable", func(ctx context.Context) {
+			req := Request(namespace, "unavailable")
+			clientID := namespace
+			cluster := createCluster(ctx, "unavailable", clientID)
+
+			credentialsClient.EXPECT().CreateOrUpdate(gomock.Any(), clientID, "crossplane", wantProperties).Return(nil)
+
+			_, err := reconciler.Reconcile(ctx, req)
+			Expect(err).To(BeNil())
+
+			credentialsClient.EXPECT().Delete(gomock.Any(), clientID, "crossplane").Return(&azcore.ResponseError{
+				StatusCode: 503,
+			})
+
+			Expect(k8sClient.Delete(ctx, cluster)).To(Succeed())
+			_, err = reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(BeNil())
+
+			GetObjects(ctx, cluster)
+			Expect(cluster.Finalizers).To(ContainElement(controllers.FederatedIdentityCredentialFinalizer))
+		})
+
+		It("does not write the federated identity credential to Azure in dry-run mode", func(ctx context.Context) {
+			req := Request(namespace, "dry-run")
+			createCluster(ctx, "dry-run", namespace)
+
+			// The mock has no expectations, so any write to Azure fails the test.
+			creator, err := dryrun.NewFederatedIdentityCredentialsClientCreator(func(context.Context, client.Client, *capz.AzureCluster) (azure.FederatedIdentityCredentialsClient, error) {
+				return credentialsClient, nil
+			}, recorder)
+			Expect(err).To(BeNil())
+			dryRunReconciler, err := controllers.NewProviderConfigReconciler(k8sClient, recorder, &controllers.ProviderConfigReconcilerOptions{
+				FederatedIdentityCredential: controllers.FederatedIdentityCredentialOptions{
+					Issuer:  "https://oidc.example.com",
+					Subject: "system:serviceaccount:crossplane-system:provider-azure",
+				},
+				FederatedIdentityCredentialsClientCreator: creator,
+			})
+			Expect(err).To(BeNil())
+
+			_, err = dryRunReconciler.Reconcile(ctx, req)
+			Expect(err).To(BeNil())
+
+			Eventually(fakeRecorder.Events).Should(Receive(ContainSubstring("Dry run: would Create FederatedIdentityCredential")))
+		})
+	})
+
+	Describe("Reconciling Cluster with finalizer of earlier versions", func() {
+		It("removes the finalizer", func(ctx context.Context) {
+			name := "finalized-cluster"
+			req := Request(namespace, name)
+
+			cluster := NewClusterBuilder(namespace, name).
+				WithFinalizers(controllers.ProviderConfigControllerFinalizer).
+				WithDummyReferences().
+				Build()
+			CreateObjects(ctx, cluster)
+
+			r, err := controllers.NewProviderConfigReconciler(k8sClient, recorder, nil)
+			Expect(err).To(BeNil())
+
+			_, err = r.Reconcile(ctx, req)
+			Expect(err).To(BeNil())
+
+			GetObjects(ctx, cluster)
+			Expect(cluster.Finalizers).ToNot(ContainElement(controllers.ProviderConfigControllerFinalizer))
+		})
 	})
 
 	Describe("Reconciling unsupported configuration", func() {
@@ -138,7 +1098,7 @@ var _ = Describe("CrossplaneProviderConfigReconciler", func() {
 			cluster := NewClusterBuilder(namespace, name).WithDummyReferences().Build()
 			CreateObjects(ctx, cluster)
 
-			r, err := controllers.NewProviderConfigReconciler(k8sClient)
+			r, err := controllers.NewProviderConfigReconciler(k8sClient, recorder, nil)
 			Expect(err).To(BeNil())
 
 			_, err = r.Reconcile(ctx, req)
@@ -147,6 +1107,66 @@ var _ = Describe("CrossplaneProviderConfigReconciler", func() {
 			GetObjects(ctx, cluster)
 			Expect(cluster.Finalizers).ToNot(ContainElement(controllers.ProviderConfigControllerFinalizer))
 		})
+
+		It("reports the unsupported infrastructure on the Cluster", func(ctx context.Context) {
+			name := "unsupported-infrastructure"
+			req := Request(namespace, name)
+
+			cluster := NewClusterBuilder(namespace, name).WithDummyReferences().Build()
+			CreateObjects(ctx, cluster)
+
+			r, err := controllers.NewProviderConfigReconciler(k8sClient, recorder, nil)
+			Expect(err).To(BeNil())
+
+			_, err = r.Reconcile(ctx, req)
+			Expect(err).To(BeNil())
+
+			GetObjects(ctx, cluster)
+			Expect(conditions.Get(cluster, controllers.ProviderConfigReadyCondition)).To(And(
+				HaveField("Status", metav1.ConditionFalse),
+				HaveField("Reason", controllers.UnsupportedInfrastructureReason),
+			))
+		})
+	})
+
+	Describe("Mapping objects to Clusters", func() {
+		It("maps an AzureCluster and its identity to the Cluster", func(ctx context.Context) {
+			azureClusterIdentity := NewAzureClusterIdentityBuilder(namespace, "mapped").Build()
+			azureCluster := NewAzureClusterBuilder(namespace, "mapped").WithIdentity(azureClusterIdentity).Build()
+			cluster := NewClusterBuilder(namespace, "mapped").WithAzureCluster(azureCluster).Build()
+			otherAzureCluster := NewAzureClusterBuilder(namespace, "unrelated").Build()
+			otherCluster := NewClusterBuilder(namespace, "unrelated").WithAzureCluster(otherAzureCluster).Build()
+
+			CreateObjects(ctx, azureClusterIdentity, azureCluster, cluster, otherAzureCluster, otherCluster)
+
+			r, err := controllers.NewProviderConfigReconciler(k8sClient, recorder, nil)
+			Expect(err).To(BeNil())
+
+			want := []reconcile.Request{Request(namespace, "mapped")}
+			Expect(r.ClusterRequestsFor(ctx, azureCluster)).To(Equal(want))
+			Expect(r.ClusterRequestsFor(ctx, azureClusterIdentity)).To(Equal(want))
+		})
+
+		It("maps an AzureASOManagedControlPlane and its credential Secret to the Cluster", func(ctx context.Context) {
+			secret := NewAzureASOCredentialsSecretBuilder(namespace, "mapped").Build()
+			otherSecret := NewAzureASOCredentialsSecretBuilder(namespace, "unrelated").Build()
+			azureAsoControlPlane := NewAzureASOManagedControlPlaneBuilder(namespace, "mapped").
+				WithCredentialSecret(secret).
+				Build()
+			cluster := NewClusterBuilder(namespace, "mapped").
+				WithAzureASOManagedControlPlane(azureAsoControlPlane).
+				Build()
+
+			CreateObjects(ctx, secret, otherSecret, azureAsoControlPlane, cluster)
+
+			r, err := controllers.NewProviderConfigReconciler(k8sClient, recorder, nil)
+			Expect(err).To(BeNil())
+
+			want := []reconcile.Request{Request(namespace, "mapped")}
+			Expect(r.ClusterRequestsFor(ctx, azureAsoControlPlane)).To(Equal(want))
+			Expect(r.ClusterRequestsFor(ctx, secret)).To(Equal(want))
+			Expect(r.ClusterRequestsFor(ctx, otherSecret)).To(BeEmpty())
+		})
 	})
 
 	Describe("Deleting Cluster", func() {
@@ -154,7 +1174,7 @@ var _ = Describe("CrossplaneProviderConfigReconciler", func() {
 			name := "deleted-cluster"
 			req := Request(namespace, name)
 
-			r, err := controllers.NewProviderConfigReconciler(k8sClient)
+			r, err := controllers.NewProviderConfigReconciler(k8sClient, recorder, nil)
 			Expect(err).To(BeNil())
 
 			_, err = r.Reconcile(ctx, req)
@@ -175,7 +1195,7 @@ var _ = Describe("CrossplaneProviderConfigReconciler", func() {
 			cluster := NewClusterBuilder(namespace, req.Name).
 				WithAzureCluster(azureCluster).
 				Build()
-			providerConfig := controllers.NewProviderConfig(req.Name)
+			providerConfig := controllers.NewProviderConfig(providerConfigName(req))
 			providerConfig.Object["spec"] = map[string]any{
 				"credentials": map[string]any{
 					"source": "UserAssignedManagedIdentity",
@@ -187,23 +1207,51 @@ var _ = Describe("CrossplaneProviderConfigReconciler", func() {
 
 			CreateObjects(ctx, providerConfig, azureClusterIdentity, azureCluster, cluster)
 
-			r, err := controllers.NewProviderConfigReconciler(k8sClient)
+			r, err := controllers.NewProviderConfigReconciler(k8sClient, recorder, nil)
 			Expect(err).To(BeNil())
 
-			// Reconcile a first time to apply the finalizer.
+			// Reconcile a first time to label the ProviderConfig with the Cluster.
 			_, err = r.Reconcile(ctx, req)
 			Expect(err).To(BeNil())
 
-			// Cluster should be in deleting state, and the reconciler should pick that up.
 			DeleteObjects(ctx, cluster)
 
-			// Reconciler should detect the Cluster is deleting, and remove the ProviderConfig.
+			// Reconciler should detect the Cluster is gone, and remove the ProviderConfig.
 			_, err = r.Reconcile(ctx, req)
 			Expect(err).To(BeNil())
 
 			Eventually(Get(providerConfig)).ShouldNot(Succeed())
 		})
 
+		It("deletes the unlabelled ProviderConfig of earlier versions", func(ctx context.Context) {
+			name := "legacy-deleting-cluster"
+			req := Request(namespace, name)
+
+			cluster := NewClusterBuilder(namespace, req.Name).
+				WithFinalizers(controllers.ProviderConfigControllerFinalizer).
+				WithDummyReferences().
+				Build()
+			legacyProviderConfig := controllers.NewProviderConfig(req.Name)
+			legacyProviderConfig.Object["spec"] = map[string]any{
+				"credentials": map[string]any{
+					"source": "UserAssignedManagedIdentity",
+				},
+			}
+
+			CreateObjects(ctx, legacyProviderConfig, cluster)
+			DeleteObjects(ctx, cluster)
+			Eventually(Get(cluster)).Should(Succeed())
+
+			r, err := controllers.NewProviderConfigReconciler(k8sClient, recorder, nil)
+			Expect(err).To(BeNil())
+
+			_, err = r.Reconcile(ctx, req)
+			Expect(err).To(BeNil())
+
+			Eventually(Get(legacyProviderConfig)).ShouldNot(Succeed())
+			Eventually(Get(cluster)).ShouldNot(Succeed())
+		})
+
 		It("removes finalizer if ProviderConfig does not exist", func(ctx context.Context) {
 			name := "deleting-cluster"
 			req := Request(namespace, name)
@@ -219,7 +1267,7 @@ var _ = Describe("CrossplaneProviderConfigReconciler", func() {
 			// Ensure that the Cluster is actually present, because we will later assert that it is not.
 			Eventually(Get(cluster)).Should(Succeed())
 
-			r, err := controllers.NewProviderConfigReconciler(k8sClient)
+			r, err := controllers.NewProviderConfigReconciler(k8sClient, recorder, nil)
 			Expect(err).To(BeNil())
 
 			_, err = r.Reconcile(ctx, req)
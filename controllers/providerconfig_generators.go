@@ -0,0 +1,220 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/clientcmd"
+	capz "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	capi "sigs.k8s.io/cluster-api/api/core/v1beta2"
+	"sigs.k8s.io/cluster-api/util/secret"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/privatelinks"
+)
+
+const (
+	// ProviderConfigGeneratorKubernetes generates provider-kubernetes ProviderConfigs.
+	ProviderConfigGeneratorKubernetes = "kubernetes"
+	// ProviderConfigGeneratorHelm generates provider-helm ProviderConfigs.
+	ProviderConfigGeneratorHelm = "helm"
+
+	// KubeconfigSecretKey is the key of the kubeconfig in the kubeconfig Secrets that are generated
+	// for Clusters with an internal API server load balancer.
+	KubeconfigSecretKey = "kubeconfig"
+)
+
+// ProviderConfigGenerator generates the ProviderConfig of another Crossplane provider than Azure
+// for a Cluster. The ProviderConfigReconciler creates the ProviderConfigs with the same name as the
+// cluster-scoped Azure ProviderConfig, labels them with the Cluster, and deletes them with the
+// Cluster.
+type ProviderConfigGenerator interface {
+	// NewProviderConfig returns an [unstructured.Unstructured] prepared for use as a ProviderConfig
+	// of the generator.
+	NewProviderConfig(name string) *unstructured.Unstructured
+	// Generate sets the spec of the ProviderConfig of the Cluster. It returns false when the
+	// ProviderConfig can't be generated yet, e.g. because the kubeconfig of the Cluster does not
+	// exist yet.
+	Generate(ctx context.Context, cluster *capi.Cluster, providerConfig *unstructured.Unstructured) (bool, error)
+}
+
+// NewProviderConfigGenerator returns the ProviderConfigGenerator with the name:
+// ProviderConfigGeneratorKubernetes or ProviderConfigGeneratorHelm.
+func NewProviderConfigGenerator(client client.Client, name string) (ProviderConfigGenerator, error) {
+	if client == nil {
+		return nil, errors.New("client may not be nil")
+	}
+
+	switch name {
+	case ProviderConfigGeneratorKubernetes:
+		return &kubeconfigProviderConfigGenerator{
+			client: client,
+			gvk: schema.GroupVersionKind{
+				Group:   "kubernetes.crossplane.io",
+				Version: "v1alpha1",
+				Kind:    "ProviderConfig",
+			},
+		}, nil
+	case ProviderConfigGeneratorHelm:
+		return &kubeconfigProviderConfigGenerator{
+			client: client,
+			gvk: schema.GroupVersionKind{
+				Group:   "helm.crossplane.io",
+				Version: "v1beta1",
+				Kind:    "ProviderConfig",
+			},
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported provider config generator %q", name)
+	}
+}
+
+// kubeconfigProviderConfigGenerator generates ProviderConfigs of providers that deploy into the
+// workload cluster with its CAPI kubeconfig, i.e. provider-kubernetes and provider-helm.
+type kubeconfigProviderConfigGenerator struct {
+	client client.Client
+	gvk    schema.GroupVersionKind
+}
+
+func (g *kubeconfigProviderConfigGenerator) NewProviderConfig(name string) *unstructured.Unstructured {
+	providerConfig := new(unstructured.Unstructured)
+	providerConfig.SetGroupVersionKind(g.gvk)
+	providerConfig.SetName(name)
+	return providerConfig
+}
+
+// Generate references the CAPI kubeconfig Secret of the Cluster. The API server of workload
+// clusters with an internal load balancer is only reachable from the management cluster through
+// the private endpoint, so for them a kubeconfig Secret with the private endpoint IP is generated
+// and referenced instead.
+func (g *kubeconfigProviderConfigGenerator) Generate(ctx context.Context, cluster *capi.Cluster, providerConfig *unstructured.Unstructured) (bool, error) {
+	kubeconfigSecret := new(corev1.Secret)
+	err := g.client.Get(ctx, types.NamespacedName{
+		Namespace: cluster.Namespace,
+		Name:      secret.Name(cluster.Name, secret.Kubeconfig),
+	}, kubeconfigSecret)
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	secretName, secretKey := kubeconfigSecret.Name, secret.KubeconfigDataName
+
+	privateEndpointIP, internal, err := g.privateEndpointIP(ctx, cluster)
+	if err != nil {
+		return false, err
+	}
+	if internal {
+		if privateEndpointIP == "" {
+			// The private endpoint of the management cluster is not ready yet.
+			return false, nil
+		}
+
+		secretName, secretKey = PrivateKubeconfigSecretName(cluster.Name), KubeconfigSecretKey
+		if err = g.reconcilePrivateKubeconfigSecret(ctx, cluster, kubeconfigSecret, privateEndpointIP); err != nil {
+			return false, err
+		}
+	}
+
+	providerConfig.Object["spec"] = map[string]any{
+		"credentials": map[string]any{
+			"source": CredentialsSourceSecret,
+			"secretRef": map[string]any{
+				"namespace": cluster.Namespace,
+				"name":      secretName,
+				"key":       secretKey,
+			},
+		},
+	}
+	return true, nil
+}
+
+// privateEndpointIP returns whether the Cluster is an AzureCluster with an internal API server
+// load balancer, and the IP of its private endpoint in the management cluster.
+func (g *kubeconfigProviderConfigGenerator) privateEndpointIP(ctx context.Context, cluster *capi.Cluster) (string, bool, error) {
+	if cluster.Spec.InfrastructureRef.Kind != capz.AzureClusterKind {
+		return "", false, nil
+	}
+
+	azureCluster := new(capz.AzureCluster)
+	err := g.client.Get(ctx, types.NamespacedName{
+		Namespace: cluster.Namespace,
+		Name:      cluster.Spec.InfrastructureRef.Name,
+	}, azureCluster)
+	if err != nil {
+		return "", false, err
+	}
+
+	apiServerLB := azureCluster.Spec.NetworkSpec.APIServerLB
+	if apiServerLB == nil || apiServerLB.Type != capz.Internal {
+		return "", false, nil
+	}
+
+	return azureCluster.Annotations[privatelinks.AzurePrivateEndpointOperatorApiServerAnnotation], true, nil
+}
+
+// reconcilePrivateKubeconfigSecret creates or updates the kubeconfig Secret with the private
+// endpoint IP. The original host name is kept as TLS server name, so that the API server
+// certificate is still verified. It is owned by the Cluster, so it is garbage collected with the
+// Cluster.
+func (g *kubeconfigProviderConfigGenerator) reconcilePrivateKubeconfigSecret(ctx context.Context, cluster *capi.Cluster, kubeconfigSecret *corev1.Secret, privateEndpointIP string) error {
+	kubeconfig, err := clientcmd.Load(kubeconfigSecret.Data[secret.KubeconfigDataName])
+	if err != nil {
+		return fmt.Errorf("failed to load kubeconfig of cluster %s: %w", cluster.Name, err)
+	}
+	for _, kubeconfigCluster := range kubeconfig.Clusters {
+		server, err := url.Parse(kubeconfigCluster.Server)
+		if err != nil {
+			return fmt.Errorf("failed to parse API server URL of cluster %s: %w", cluster.Name, err)
+		}
+		port := server.Port()
+		if port == "" {
+			port = "443"
+		}
+		if kubeconfigCluster.TLSServerName == "" {
+			kubeconfigCluster.TLSServerName = server.Hostname()
+		}
+		server.Host = net.JoinHostPort(privateEndpointIP, port)
+		kubeconfigCluster.Server = server.String()
+	}
+	data, err := clientcmd.Write(*kubeconfig)
+	if err != nil {
+		return err
+	}
+
+	privateKubeconfigSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: cluster.Namespace,
+			Name:      PrivateKubeconfigSecretName(cluster.Name),
+		},
+	}
+	_, err = controllerutil.CreateOrPatch(ctx, g.client, privateKubeconfigSecret, func() error {
+		if privateKubeconfigSecret.Labels == nil {
+			privateKubeconfigSecret.Labels = map[string]string{}
+		}
+		privateKubeconfigSecret.Labels[ProviderConfigClusterNameLabel] = cluster.Name
+		privateKubeconfigSecret.Labels[ProviderConfigClusterNamespaceLabel] = cluster.Namespace
+		privateKubeconfigSecret.Data = map[string][]byte{
+			KubeconfigSecretKey: data,
+		}
+		return controllerutil.SetOwnerReference(cluster, privateKubeconfigSecret, g.client.Scheme())
+	})
+	return err
+}
+
+// PrivateKubeconfigSecretName returns the name of the kubeconfig Secret with the private endpoint
+// IP of the Cluster.
+func PrivateKubeconfigSecretName(clusterName string) string {
+	return clusterName + "-crossplane-kubeconfig"
+}
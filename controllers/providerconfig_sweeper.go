@@ -0,0 +1,118 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metaerr "k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	capi "sigs.k8s.io/cluster-api/api/core/v1beta2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/giantswarm/microerror"
+
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/errors"
+)
+
+// ProviderConfigSweeper periodically deletes the ProviderConfigs whose Cluster does not exist
+// anymore, e.g. because it was force-deleted while the operator was not running, so that no
+// dangling Crossplane credentials are left behind.
+//
+// Only ProviderConfigs that are labelled with their Cluster are considered, so ProviderConfigs
+// that were not generated by the operator are never deleted. Besides the Azure and Azure AD
+// ProviderConfigs, the ProviderConfigs of the generators are swept.
+type ProviderConfigSweeper struct {
+	client     client.Client
+	interval   time.Duration
+	generators []ProviderConfigGenerator
+}
+
+func NewProviderConfigSweeper(client client.Client, interval time.Duration, generators ...ProviderConfigGenerator) (*ProviderConfigSweeper, error) {
+	if client == nil {
+		return nil, microerror.Maskf(errors.InvalidConfigError, "client must be set")
+	}
+	if interval <= 0 {
+		return nil, microerror.Maskf(errors.InvalidConfigError, "interval must be positive")
+	}
+
+	return &ProviderConfigSweeper{
+		client:     client,
+		interval:   interval,
+		generators: generators,
+	}, nil
+}
+
+// Start runs the sweep every interval until the context is cancelled. It implements
+// manager.Runnable.
+func (s *ProviderConfigSweeper) Start(ctx context.Context) error {
+	logger := log.FromContext(ctx).WithName("providerconfig-sweeper")
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		if err := s.Sweep(log.IntoContext(ctx, logger)); err != nil {
+			logger.Error(err, "Failed to sweep orphaned ProviderConfigs")
+		}
+	}, s.interval)
+
+	return nil
+}
+
+// NeedLeaderElection makes only the leader run the sweep, as it deletes ProviderConfigs.
+func (s *ProviderConfigSweeper) NeedLeaderElection() bool {
+	return true
+}
+
+// Sweep deletes the ProviderConfigs whose Cluster does not exist anymore.
+func (s *ProviderConfigSweeper) Sweep(ctx context.Context) error {
+	providerConfigs := []*unstructured.Unstructured{NewProviderConfig(""), NewAzureADProviderConfig("")}
+	for _, generator := range s.generators {
+		providerConfigs = append(providerConfigs, generator.NewProviderConfig(""))
+	}
+
+	for _, providerConfig := range providerConfigs {
+		if err := s.sweep(ctx, providerConfig.GroupVersionKind()); err != nil {
+			return microerror.Mask(err)
+		}
+	}
+
+	return nil
+}
+
+// sweep deletes the ProviderConfigs of the kind whose Cluster does not exist anymore.
+func (s *ProviderConfigSweeper) sweep(ctx context.Context, gvk schema.GroupVersionKind) error {
+	logger := log.FromContext(ctx)
+
+	providerConfigs := new(unstructured.UnstructuredList)
+	providerConfigs.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+	err := s.client.List(ctx, providerConfigs, client.HasLabels{ProviderConfigClusterNamespaceLabel, ProviderConfigClusterNameLabel})
+	if metaerr.IsNoMatchError(err) {
+		// The provider is not installed, so there is nothing to sweep.
+		return nil
+	} else if err != nil {
+		return microerror.Mask(err)
+	}
+
+	for i := range providerConfigs.Items {
+		providerConfig := &providerConfigs.Items[i]
+		clusterName, ok := ProviderConfigCluster(providerConfig)
+		if !ok {
+			continue
+		}
+
+		err = s.client.Get(ctx, clusterName, new(capi.Cluster))
+		if err == nil {
+			continue
+		} else if !apierrors.IsNotFound(err) {
+			return microerror.Mask(err)
+		}
+
+		logger.Info("Deleting ProviderConfig of deleted cluster", "kind", gvk, "providerConfig", providerConfig.GetName(), "cluster", clusterName)
+		if err = s.client.Delete(ctx, providerConfig); client.IgnoreNotFound(err) != nil {
+			return microerror.Mask(err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,100 @@
+package controllers_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	capi "sigs.k8s.io/cluster-api/api/core/v1beta2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/giantswarm/azure-private-endpoint-operator/controllers"
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/errors"
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/testhelpers"
+)
+
+var _ = Describe("ProviderConfigSweeper", func() {
+	var fakeClient client.Client
+	var sweeper *controllers.ProviderConfigSweeper
+	var generator controllers.ProviderConfigGenerator
+
+	providerConfigOf := func(clusterNamespace, clusterName string) *unstructured.Unstructured {
+		providerConfig := controllers.NewProviderConfig(clusterName)
+		providerConfig.SetLabels(map[string]string{
+			controllers.ProviderConfigClusterNameLabel:      clusterName,
+			controllers.ProviderConfigClusterNamespaceLabel: clusterNamespace,
+		})
+		return providerConfig
+	}
+
+	BeforeEach(func() {
+		providerConfigGVK := controllers.NewProviderConfig("").GroupVersionKind()
+		scheme := runtime.NewScheme()
+		Expect(capi.AddToScheme(scheme)).To(Succeed())
+		scheme.AddKnownTypeWithName(providerConfigGVK, &unstructured.Unstructured{})
+		scheme.AddKnownTypeWithName(providerConfigGVK.GroupVersion().WithKind("ProviderConfigList"), &unstructured.UnstructuredList{})
+		azureADGVK := controllers.NewAzureADProviderConfig("").GroupVersionKind()
+		scheme.AddKnownTypeWithName(azureADGVK, &unstructured.Unstructured{})
+		scheme.AddKnownTypeWithName(azureADGVK.GroupVersion().WithKind("ProviderConfigList"), &unstructured.UnstructuredList{})
+		azureADProviderConfig := controllers.NewAzureADProviderConfig("deleted")
+		azureADProviderConfig.SetLabels(providerConfigOf("org-acme", "deleted").GetLabels())
+
+		var err error
+		generator, err = controllers.NewProviderConfigGenerator(fake.NewClientBuilder().Build(), controllers.ProviderConfigGeneratorKubernetes)
+		Expect(err).NotTo(HaveOccurred())
+		generatedGVK := generator.NewProviderConfig("").GroupVersionKind()
+		scheme.AddKnownTypeWithName(generatedGVK, &unstructured.Unstructured{})
+		scheme.AddKnownTypeWithName(generatedGVK.GroupVersion().WithKind("ProviderConfigList"), &unstructured.UnstructuredList{})
+		generatedProviderConfig := generator.NewProviderConfig("deleted")
+		generatedProviderConfig.SetLabels(providerConfigOf("org-acme", "deleted").GetLabels())
+
+		unmanagedProviderConfig := controllers.NewProviderConfig("default")
+		fakeClient = fake.NewClientBuilder().
+			WithScheme(scheme).
+			WithObjects(
+				testhelpers.NewClusterBuilder("org-acme", "existing").Build(),
+				providerConfigOf("org-acme", "existing"),
+				providerConfigOf("org-acme", "deleted"),
+				unmanagedProviderConfig,
+				generatedProviderConfig,
+				azureADProviderConfig,
+			).
+			Build()
+
+		sweeper, err = controllers.NewProviderConfigSweeper(fakeClient, time.Minute, generator)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("fails to create sweeper without interval", func() {
+		_, err := controllers.NewProviderConfigSweeper(fakeClient, 0)
+		Expect(errors.IsInvalidConfig(err)).To(BeTrue())
+	})
+
+	It("deletes only ProviderConfigs whose Cluster is gone", func(ctx context.Context) {
+		Expect(sweeper.Sweep(ctx)).To(Succeed())
+
+		err := fakeClient.Get(ctx, client.ObjectKey{Name: "deleted"}, controllers.NewProviderConfig("deleted"))
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		Expect(fakeClient.Get(ctx, client.ObjectKey{Name: "existing"}, controllers.NewProviderConfig("existing"))).To(Succeed())
+		Expect(fakeClient.Get(ctx, client.ObjectKey{Name: "default"}, controllers.NewProviderConfig("default"))).To(Succeed())
+	})
+
+	It("deletes the ProviderConfigs of the generators whose Cluster is gone", func(ctx context.Context) {
+		Expect(sweeper.Sweep(ctx)).To(Succeed())
+
+		err := fakeClient.Get(ctx, client.ObjectKey{Name: "deleted"}, generator.NewProviderConfig("deleted"))
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("deletes the Azure AD ProviderConfigs whose Cluster is gone", func(ctx context.Context) {
+		Expect(sweeper.Sweep(ctx)).To(Succeed())
+
+		err := fakeClient.Get(ctx, client.ObjectKey{Name: "deleted"}, controllers.NewAzureADProviderConfig("deleted"))
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+})
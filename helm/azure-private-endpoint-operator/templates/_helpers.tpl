@@ -59,3 +59,7 @@ giantswarm
 {{- define "resource.networkPolicy.name" -}}
 {{- include "resource.default.name" . -}}-network-policy
 {{- end -}}
+
+{{- define "resource.webhook.name" -}}
+{{- include "resource.default.name" . -}}-webhook
+{{- end -}}
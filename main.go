@@ -24,6 +24,7 @@ import (
 
 	"go.uber.org/zap/zapcore"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
@@ -34,13 +35,20 @@ import (
 	capi "sigs.k8s.io/cluster-api/api/core/v1beta2"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 	webhookserver "sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	"github.com/giantswarm/azure-private-endpoint-operator/controllers"
 	"github.com/giantswarm/azure-private-endpoint-operator/pkg/azure"
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/drift"
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/dryrun"
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/events"
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/metrics"
+	"github.com/giantswarm/azure-private-endpoint-operator/webhooks"
 	//+kubebuilder:scaffold:imports
 )
 
@@ -59,6 +67,17 @@ func init() {
 }
 
 func main() {
+	// The plan and audit subcommands run once instead of starting the operator, see runPlan and
+	// runAudit.
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "plan":
+			os.Exit(runPlan(os.Args[2:]))
+		case "audit":
+			os.Exit(runAudit(os.Args[2:]))
+		}
+	}
+
 	var (
 		metricsAddr                string
 		enableLeaderElection       bool
@@ -67,6 +86,26 @@ func main() {
 		managementClusterNamespace string
 		azureClusterGates          ConditionSliceVar
 		syncPeriod                 time.Duration
+		retryInitialDelay          time.Duration
+		retryMaxDelay              time.Duration
+		fallbackCredential         string
+		resourceManagerEndpoint    string
+		dryRun                     bool
+		enableWebhooks             bool
+		webhookPort                int
+		privateLinkSubscriptions   string
+		driftDetectionInterval     time.Duration
+		driftTriggerReconcile      bool
+		providerConfigSweepPeriod  time.Duration
+		providerConfigName         string
+		providerConfigDeleteLegacy bool
+		providerConfigMode         string
+		workloadIdentitySource     string
+		asoGlobalCredentialSecret  types.NamespacedName
+		providerConfigGenerators   string
+		providerConfigAzureAD      bool
+		federatedCredential        controllers.FederatedIdentityCredentialOptions
+		controlPlaneKinds          string
 	)
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080",
 		"The address the metric endpoint binds to.")
@@ -81,8 +120,59 @@ func main() {
 		"The namespace where the management cluster AzureCluster CR is deployed")
 	flag.Var(&azureClusterGates, "azure-cluster-gates",
 		"Status conditions on the workload AzureCluster CR that must be true before the control plane starts reconciling")
+	flag.StringVar(&controlPlaneKinds, "control-plane-kinds", controllers.KubeadmControlPlaneKind.String(),
+		"Comma-separated list of the control plane kinds (Kind.group) that are paused until the azure-cluster-gates of their infrastructure cluster are met, each optionally followed by the kind of the infrastructure clusters that carry the conditions (Kind.group=Kind.group, defaults to AzureCluster). Control planes of other infrastructure clusters are not paused. The CRDs of the kinds must be installed")
 	flag.DurationVar(&syncPeriod, "sync-period", 5*time.Minute,
 		"The minimum interval at which watched resources are reconciled (e.g. 15m)")
+	flag.DurationVar(&retryInitialDelay, "retry-initial-delay", controllers.DefaultRetryInitialDelay,
+		"The delay before the first retry when a workload cluster is not ready yet, e.g. when its private links are still being created. The delay doubles with every retry of the same cluster")
+	flag.DurationVar(&retryMaxDelay, "retry-max-delay", controllers.DefaultRetryMaxDelay,
+		"The maximum delay between retries when a workload cluster is not ready yet")
+	flag.StringVar(&fallbackCredential, "fallback-credential", azure.FallbackCredentialNone,
+		"The credential that is used for AzureClusters without an identityRef: 'none' or 'default' (Azure default credential of the operator, e.g. from workload identity)")
+	flag.StringVar(&resourceManagerEndpoint, "azure-resource-manager-endpoint", "",
+		"Custom Azure Resource Manager endpoint that replaces the endpoint of the Azure cloud of every AzureCluster (e.g. https://localhost:8443/ for a local ARM stand-in)")
+	flag.BoolVar(&dryRun, "dry-run", false,
+		"Log the changes the operator would make, and emit them as events and metrics, without persisting them. "+
+			"Webhooks are not served, and the events of the controllers are emitted as DryRun events. "+
+			"Disable leader election to run side-by-side with the active operator")
+	flag.BoolVar(&enableWebhooks, "enable-webhooks", false,
+		"Enable the validating webhook for AzureClusters. The webhook server needs a serving certificate in /tmp/k8s-webhook-server/serving-certs")
+	flag.IntVar(&webhookPort, "webhook-port", 9443,
+		"The port the webhook server binds to.")
+	flag.StringVar(&privateLinkSubscriptions, "default-private-link-subscriptions", "",
+		"Add the management cluster subscription to the private links of private workload clusters with a defaulting webhook. "+
+			"Use Allowed to add it to allowedSubscriptions, or AutoApproved to add it to allowedSubscriptions and autoApprovedSubscriptions. "+
+			"Requires -enable-webhooks")
+	flag.DurationVar(&driftDetectionInterval, "drift-detection-interval", 10*time.Minute,
+		"The interval at which the private endpoints in the AzureCluster specs are compared with the private endpoints in Azure. 0 disables drift detection")
+	flag.BoolVar(&driftTriggerReconcile, "drift-detection-trigger-reconcile", false,
+		"Annotate AzureClusters whose private endpoints are missing in Azure, so that CAPZ reconciles them and creates the private endpoints again")
+	flag.DurationVar(&providerConfigSweepPeriod, "provider-config-sweep-interval", 10*time.Minute,
+		"The interval at which Crossplane ProviderConfigs whose Cluster does not exist anymore are deleted. 0 disables the sweep")
+	flag.StringVar(&providerConfigName, "provider-config-name-template", controllers.DefaultProviderConfigNameTemplate,
+		"The Go template for the names of the Crossplane ProviderConfigs, executed with the .Namespace and the .Name of the Cluster")
+	flag.BoolVar(&providerConfigDeleteLegacy, "provider-config-delete-legacy", false,
+		"Delete the Crossplane ProviderConfigs named after the Cluster by earlier versions when -provider-config-name-template names them differently. "+
+			"Only enable this once no managed resources reference them anymore")
+	flag.StringVar(&workloadIdentitySource, "provider-config-workload-identity-source", controllers.CredentialsSourceUserAssignedManagedIdentity,
+		"The credentials source of the Crossplane ProviderConfigs of Clusters with workload identity: UserAssignedManagedIdentity or OIDCTokenFile")
+	flag.StringVar(&asoGlobalCredentialSecret.Namespace, "aso-global-credential-secret-namespace", controllers.DefaultASOGlobalCredentialSecret.Namespace,
+		"The namespace of the global credential Secret of Azure Service Operator, which is used for ASO clusters without credential-from annotation and aso-credential Secret")
+	flag.StringVar(&asoGlobalCredentialSecret.Name, "aso-global-credential-secret-name", controllers.DefaultASOGlobalCredentialSecret.Name,
+		"The name of the global credential Secret of Azure Service Operator")
+	flag.StringVar(&providerConfigMode, "provider-config-mode", string(controllers.ProviderConfigModeAuto),
+		"The Crossplane ProviderConfigs to create: ClusterScoped, Namespaced (Crossplane v2, in the namespace of the Cluster), Both, or Auto to create the ones whose CRDs are installed")
+	flag.BoolVar(&providerConfigAzureAD, "provider-config-azuread", false,
+		"Create Crossplane Azure AD (azuread.upbound.io) ProviderConfigs with the identity of the Cluster, alongside the Azure ProviderConfigs")
+	flag.StringVar(&federatedCredential.Issuer, "federated-identity-credential-issuer", "",
+		"The OIDC issuer URL of the management cluster. When set, federated identity credentials that trust the Crossplane provider are created on the workload identities of the AzureClusters")
+	flag.StringVar(&federatedCredential.Subject, "federated-identity-credential-subject", "",
+		"The subject of the federated identity credentials, e.g. system:serviceaccount:crossplane-system:provider-azure")
+	flag.StringVar(&federatedCredential.Name, "federated-identity-credential-name", controllers.DefaultFederatedIdentityCredentialName,
+		"The name of the federated identity credentials")
+	flag.StringVar(&providerConfigGenerators, "provider-config-generators", "",
+		"Comma-separated list of the Crossplane providers whose ProviderConfigs are generated in addition to the Azure ones, with the kubeconfig of the workload cluster: kubernetes, helm")
 	opts := zap.Options{
 		Development: false,
 		TimeEncoder: zapcore.ISO8601TimeEncoder,
@@ -99,7 +189,7 @@ func main() {
 		},
 		WebhookServer: webhookserver.NewServer(
 			webhookserver.Options{
-				Port: 9443,
+				Port: webhookPort,
 			},
 		),
 		HealthProbeBindAddress: probeAddr,
@@ -127,7 +217,56 @@ func main() {
 		Namespace: managementClusterNamespace,
 		Name:      managementClusterName,
 	}
-	azureClusterReconciler, err := controllers.NewAzureClusterReconciler(mgr.GetClient(), azure.NewPrivateEndpointClient, mcNamespacedName, controllers.Options{})
+	recorder, err := events.NewRecorder(mgr.GetEventRecorder("azure-private-endpoint-operator"), events.DefaultDeduplicationWindow)
+	if err != nil {
+		setupLog.Error(err, "unable to create event recorder")
+		os.Exit(1)
+	}
+
+	// In dry-run mode, the controllers write through a client that only logs the changes, so that
+	// nothing is persisted, and their events, which report these changes, are emitted as DryRun
+	// events.
+	var k8sClient client.Client = mgr.GetClient()
+	controllerRecorder := recorder
+	if dryRun {
+		k8sClient, err = dryrun.NewClient(k8sClient, recorder)
+		if err != nil {
+			setupLog.Error(err, "unable to create dry-run client")
+			os.Exit(1)
+		}
+		dryRunEventRecorder, err := dryrun.NewEventRecorder(mgr.GetEventRecorder("azure-private-endpoint-operator"))
+		if err != nil {
+			setupLog.Error(err, "unable to create dry-run event recorder")
+			os.Exit(1)
+		}
+		controllerRecorder, err = events.NewRecorder(dryRunEventRecorder, events.DefaultDeduplicationWindow)
+		if err != nil {
+			setupLog.Error(err, "unable to create event recorder")
+			os.Exit(1)
+		}
+		setupLog.Info("Running in dry-run mode, changes are not persisted", "leaderElection", enableLeaderElection)
+	}
+
+	azureFallbackCredential, err := azure.NewFallbackCredentialCreator(fallbackCredential)
+	if err != nil {
+		setupLog.Error(err, "unable to create fallback Azure credential")
+		os.Exit(1)
+	}
+
+	// Azure credentials and clients are shared by all controllers, so that Azure AD tokens are reused.
+	azureClientCache := azure.NewClientCache(azure.ClientCacheOptions{
+		FallbackCredential:      azureFallbackCredential,
+		ResourceManagerEndpoint: resourceManagerEndpoint,
+	})
+	if err = azureClientCache.SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to set up Azure client cache")
+		os.Exit(1)
+	}
+
+	azureClusterReconciler, err := controllers.NewAzureClusterReconciler(k8sClient, azureClientCache.NewPrivateEndpointClient, controllerRecorder, mcNamespacedName, controllers.Options{
+		RetryInitialDelay: retryInitialDelay,
+		RetryMaxDelay:     retryMaxDelay,
+	})
 	if err != nil {
 		setupLog.Error(err, "unable to create new AzureClusterReconciler")
 		os.Exit(1)
@@ -137,19 +276,84 @@ func main() {
 		os.Exit(1)
 	}
 
-	kubeadmControlPlaneReconciler, err := controllers.NewKubeadmControlPlaneReconciler(mgr.GetClient(), mcNamespacedName, &controllers.KubeadmControlPlaneReconcilerOptions{
-		AzureClusterGates: azureClusterGates,
-	})
+	privateEndpointsCollector, err := metrics.NewPrivateEndpointsCollector(mgr.GetClient(), mcNamespacedName)
 	if err != nil {
-		setupLog.Error(err, "unable to create new KubeadmControlPlaneReconciler")
+		setupLog.Error(err, "unable to create private endpoints metrics collector")
 		os.Exit(1)
 	}
-	if err = kubeadmControlPlaneReconciler.SetupWithManager(mgr); err != nil {
-		setupLog.Error(err, "unable to create controller", "controller", "KubeadmControlPlane")
-		os.Exit(1)
+	ctrlmetrics.Registry.MustRegister(privateEndpointsCollector)
+
+	if driftDetectionInterval > 0 {
+		driftDetector, err := drift.NewDetector(k8sClient, azureClientCache.NewPrivateEndpointClient, controllerRecorder, drift.Options{
+			Interval:         driftDetectionInterval,
+			TriggerReconcile: driftTriggerReconcile,
+		})
+		if err != nil {
+			setupLog.Error(err, "unable to create private endpoint drift detector")
+			os.Exit(1)
+		}
+		if err = mgr.Add(driftDetector); err != nil {
+			setupLog.Error(err, "unable to add private endpoint drift detector")
+			os.Exit(1)
+		}
 	}
 
-	providerConfigReconciler, err := controllers.NewProviderConfigReconciler(mgr.GetClient())
+	for _, kind := range strings.Split(controlPlaneKinds, ",") {
+		if kind = strings.TrimSpace(kind); kind == "" {
+			continue
+		}
+		controlPlane, infraCluster, _ := strings.Cut(kind, "=")
+		controlPlaneKind := schema.ParseGroupKind(strings.TrimSpace(controlPlane))
+		controlPlaneReconciler, err := controllers.NewControlPlaneReconciler(k8sClient, mcNamespacedName, &controllers.ControlPlaneReconcilerOptions{
+			AzureClusterGates:         azureClusterGates,
+			ControlPlaneKind:          controlPlaneKind,
+			InfrastructureClusterKind: schema.ParseGroupKind(strings.TrimSpace(infraCluster)),
+		})
+		if err != nil {
+			setupLog.Error(err, "unable to create new ControlPlaneReconciler", "kind", kind)
+			os.Exit(1)
+		}
+		if err = controlPlaneReconciler.SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", controlPlaneKind.Kind)
+			os.Exit(1)
+		}
+	}
+
+	var generators []controllers.ProviderConfigGenerator
+	for _, name := range strings.Split(providerConfigGenerators, ",") {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		generator, err := controllers.NewProviderConfigGenerator(k8sClient, name)
+		if err != nil {
+			setupLog.Error(err, "unable to create ProviderConfig generator")
+			os.Exit(1)
+		}
+		generators = append(generators, generator)
+	}
+
+	// The federated identity credentials are the only objects that are written to Azure directly,
+	// so they need their own dry-run client.
+	federatedIdentityCredentialsClientCreator := azure.FederatedIdentityCredentialsClientCreator(azureClientCache.NewFederatedIdentityCredentialsClient)
+	if dryRun {
+		federatedIdentityCredentialsClientCreator, err = dryrun.NewFederatedIdentityCredentialsClientCreator(federatedIdentityCredentialsClientCreator, recorder)
+		if err != nil {
+			setupLog.Error(err, "unable to create dry-run federated identity credentials client")
+			os.Exit(1)
+		}
+	}
+
+	providerConfigReconciler, err := controllers.NewProviderConfigReconciler(k8sClient, controllerRecorder, &controllers.ProviderConfigReconcilerOptions{
+		NameTemplate:                providerConfigName,
+		DeleteLegacyProviderConfigs: providerConfigDeleteLegacy,
+		Mode:                        controllers.ProviderConfigMode(providerConfigMode),
+		WorkloadIdentitySource:      workloadIdentitySource,
+		ASOGlobalCredentialSecret:   asoGlobalCredentialSecret,
+		Generators:                  generators,
+		AzureAD:                     providerConfigAzureAD,
+		FederatedIdentityCredential: federatedCredential,
+		FederatedIdentityCredentialsClientCreator: federatedIdentityCredentialsClientCreator,
+	})
 	if err != nil {
 		setupLog.Error(err, "unable to create new ProviderConfigReconciler")
 		os.Exit(1)
@@ -159,6 +363,47 @@ func main() {
 		os.Exit(1)
 	}
 
+	if providerConfigSweepPeriod > 0 {
+		providerConfigSweeper, err := controllers.NewProviderConfigSweeper(k8sClient, providerConfigSweepPeriod, generators...)
+		if err != nil {
+			setupLog.Error(err, "unable to create ProviderConfig sweeper")
+			os.Exit(1)
+		}
+		if err = mgr.Add(providerConfigSweeper); err != nil {
+			setupLog.Error(err, "unable to add ProviderConfig sweeper")
+			os.Exit(1)
+		}
+	}
+
+	// Webhooks would mutate and reject AzureClusters on the API server, so they are not served in
+	// dry-run mode.
+	if enableWebhooks && dryRun {
+		setupLog.Info("Not serving webhooks in dry-run mode")
+	} else if enableWebhooks {
+		azureClusterValidator, err := webhooks.NewAzureClusterValidator(mgr.GetClient(), mcNamespacedName)
+		if err != nil {
+			setupLog.Error(err, "unable to create new AzureClusterValidator")
+			os.Exit(1)
+		}
+		if err = azureClusterValidator.SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "AzureCluster")
+			os.Exit(1)
+		}
+
+		if privateLinkSubscriptions != "" {
+			azureClusterDefaulter, err := webhooks.NewAzureClusterDefaulter(mgr.GetClient(), mcNamespacedName,
+				webhooks.PrivateLinkSubscriptions(privateLinkSubscriptions))
+			if err != nil {
+				setupLog.Error(err, "unable to create new AzureClusterDefaulter")
+				os.Exit(1)
+			}
+			if err = azureClusterDefaulter.SetupWebhookWithManager(mgr); err != nil {
+				setupLog.Error(err, "unable to create webhook", "webhook", "AzureCluster")
+				os.Exit(1)
+			}
+		}
+	}
+
 	//+kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
@@ -0,0 +1,372 @@
+package audit
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v9"
+	"k8s.io/apimachinery/pkg/types"
+	k8sevents "k8s.io/client-go/tools/events"
+	capz "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/giantswarm/microerror"
+
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/azure"
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/errors"
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/events"
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/privateendpoints"
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/privatelinks"
+)
+
+type FindingType string
+
+const (
+	// FindingMissing is a desired private endpoint that is not in the AzureCluster spec or does
+	// not exist in Azure.
+	FindingMissing FindingType = "Missing"
+	// FindingOrphaned is a private endpoint to a private link service that is in the AzureCluster
+	// spec or exists in Azure, but is not desired.
+	FindingOrphaned FindingType = "Orphaned"
+	// FindingPendingApproval is a private endpoint whose connection to the private link service
+	// has not been approved yet.
+	FindingPendingApproval FindingType = "PendingApproval"
+	// FindingIPMismatch is a private endpoint whose IP address in Azure is not the IP address in
+	// the annotation of the workload AzureCluster.
+	FindingIPMismatch FindingType = "IPMismatch"
+	// FindingAuditFailed is a cluster or a private endpoint that could not be audited, e.g.
+	// because the Azure credentials of the cluster are not valid.
+	FindingAuditFailed FindingType = "AuditFailed"
+
+	SourceSpec  = "spec"
+	SourceAzure = "azure"
+
+	privateLinkServiceConnectionStatusPending = "Pending"
+)
+
+// Finding is a difference between the desired private endpoints, the private endpoints in the
+// AzureCluster specs, and the private endpoints that exist in Azure.
+type Finding struct {
+	Type FindingType `json:"type"`
+	// Cluster is the AzureCluster in whose resource group the private endpoint is, as
+	// <namespace>/<name>.
+	Cluster string `json:"cluster"`
+	// WorkloadCluster is the workload AzureCluster that the private endpoint is desired for.
+	WorkloadCluster string `json:"workloadCluster,omitempty"`
+	PrivateEndpoint string `json:"privateEndpoint,omitempty"`
+	// Source is where the private endpoint was found, "spec" or "azure".
+	Source  string `json:"source,omitempty"`
+	Message string `json:"message"`
+}
+
+// Report is the result of an audit.
+type Report struct {
+	Findings []Finding `json:"findings"`
+}
+
+// Auditor compares the private endpoints that the operator wants for all AzureClusters with the
+// private endpoints in the AzureCluster specs and the private endpoints that exist in Azure.
+//
+// Only private endpoints that connect to private link services are audited, as the operator does
+// not manage any other private endpoints.
+type Auditor struct {
+	client                        client.Client
+	privateEndpointsClientCreator azure.PrivateEndpointsClientCreator
+	managementClusterName         types.NamespacedName
+	recorder                      *events.Recorder
+}
+
+// desiredPrivateEndpoint is a private endpoint that the operator wants for a workload cluster.
+type desiredPrivateEndpoint struct {
+	spec            capz.PrivateEndpointSpec
+	workloadCluster *capz.AzureCluster
+	// ipAnnotation is the annotation of the workload AzureCluster that has the IP address of the
+	// private endpoint.
+	ipAnnotation string
+}
+
+func NewAuditor(client client.Client, privateEndpointsClientCreator azure.PrivateEndpointsClientCreator, managementClusterName types.NamespacedName) (*Auditor, error) {
+	if client == nil {
+		return nil, microerror.Maskf(errors.InvalidConfigError, "client must be set")
+	}
+	if privateEndpointsClientCreator == nil {
+		return nil, microerror.Maskf(errors.InvalidConfigError, "privateEndpointsClientCreator must be set")
+	}
+	if managementClusterName.Name == "" || managementClusterName.Namespace == "" {
+		return nil, microerror.Maskf(errors.InvalidConfigError, "managementClusterName must be set")
+	}
+
+	// The services are only used to compute the desired private endpoints, so the events they
+	// would emit when changing private endpoints are discarded.
+	recorder, err := events.NewRecorder(&k8sevents.FakeRecorder{}, 0)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return &Auditor{
+		client:                        client,
+		privateEndpointsClientCreator: privateEndpointsClientCreator,
+		managementClusterName:         managementClusterName,
+		recorder:                      recorder,
+	}, nil
+}
+
+// Audit audits the private endpoints of the management cluster and all workload clusters. A
+// cluster that can not be audited is reported as a finding, so that the other clusters are still
+// audited.
+func (a *Auditor) Audit(ctx context.Context) (*Report, error) {
+	var managementAzureCluster capz.AzureCluster
+	if err := a.client.Get(ctx, a.managementClusterName, &managementAzureCluster); err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	var azureClusters capz.AzureClusterList
+	if err := a.client.List(ctx, &azureClusters); err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	report := &Report{}
+
+	mcPrivateEndpointsScope, err := a.newPrivateEndpointsScope(ctx, &managementAzureCluster)
+	if err != nil {
+		report.Findings = append(report.Findings, auditFailed(&managementAzureCluster, err))
+		return report, nil
+	}
+
+	mcDesiredPrivateEndpoints := map[string]desiredPrivateEndpoint{}
+	// The private endpoints to the private link services of workload clusters whose desired
+	// private endpoints could not be evaluated may still be needed, so they are not orphaned.
+	unevaluatedPrivateLinkServiceIDs := map[string]bool{}
+	for i := range azureClusters.Items {
+		workloadAzureCluster := &azureClusters.Items[i]
+		if client.ObjectKeyFromObject(workloadAzureCluster) == a.managementClusterName {
+			continue
+		}
+
+		// The private endpoints of workload clusters that are being deleted are removed, so
+		// they are not desired anymore.
+		if !workloadAzureCluster.DeletionTimestamp.IsZero() {
+			continue
+		}
+
+		if workloadAzureCluster.Spec.NetworkSpec.APIServerLB.Type == capz.Internal {
+			specs, err := a.desiredMcToWcApiPrivateEndpoints(mcPrivateEndpointsScope, workloadAzureCluster)
+			if err != nil {
+				report.Findings = append(report.Findings, auditFailed(workloadAzureCluster, err))
+				for _, privateLinkServiceID := range workloadClusterPrivateLinkServiceIDs(workloadAzureCluster) {
+					unevaluatedPrivateLinkServiceIDs[privateLinkServiceID] = true
+				}
+				continue
+			}
+			for _, spec := range specs {
+				mcDesiredPrivateEndpoints[spec.Name] = desiredPrivateEndpoint{
+					spec:            spec,
+					workloadCluster: workloadAzureCluster,
+					ipAnnotation:    privatelinks.AzurePrivateEndpointOperatorApiServerAnnotation,
+				}
+			}
+		}
+
+		// Workload clusters only have private endpoints to the MC ingress when the MC is private.
+		if managementAzureCluster.Spec.NetworkSpec.APIServerLB.Type != capz.Internal {
+			continue
+		}
+
+		wcPrivateEndpointsScope, err := a.newPrivateEndpointsScope(ctx, workloadAzureCluster)
+		if err != nil {
+			report.Findings = append(report.Findings, auditFailed(workloadAzureCluster, err))
+			continue
+		}
+		wcDesiredPrivateEndpoints := map[string]desiredPrivateEndpoint{}
+		for _, spec := range privateendpoints.WcToMcIngressPrivateEndpointSpecs(*workloadAzureCluster, managementAzureCluster) {
+			wcDesiredPrivateEndpoints[spec.Name] = desiredPrivateEndpoint{
+				spec:            spec,
+				workloadCluster: workloadAzureCluster,
+				ipAnnotation:    privatelinks.AzurePrivateEndpointOperatorMcIngressAnnotation,
+			}
+		}
+		report.Findings = append(report.Findings, a.auditCluster(ctx, wcPrivateEndpointsScope, wcDesiredPrivateEndpoints, nil)...)
+	}
+	report.Findings = append(report.Findings, a.auditCluster(ctx, mcPrivateEndpointsScope, mcDesiredPrivateEndpoints, unevaluatedPrivateLinkServiceIDs)...)
+
+	slices.SortStableFunc(report.Findings, func(a, b Finding) int {
+		return cmp.Or(
+			cmp.Compare(a.Cluster, b.Cluster),
+			cmp.Compare(a.PrivateEndpoint, b.PrivateEndpoint),
+			cmp.Compare(a.Type, b.Type),
+			cmp.Compare(a.Source, b.Source))
+	})
+
+	return report, nil
+}
+
+func (a *Auditor) newPrivateEndpointsScope(ctx context.Context, azureCluster *capz.AzureCluster) (privateendpoints.Scope, error) {
+	privateEndpointsClient, err := a.privateEndpointsClientCreator(ctx, a.client, azureCluster)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	scope, err := privateendpoints.NewScope(ctx, azureCluster, a.client, privateEndpointsClient)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return scope, nil
+}
+
+func (a *Auditor) desiredMcToWcApiPrivateEndpoints(mcPrivateEndpointsScope privateendpoints.Scope, workloadAzureCluster *capz.AzureCluster) ([]capz.PrivateEndpointSpec, error) {
+	privateLinksScope, err := privatelinks.NewScope(workloadAzureCluster, a.client)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	service, err := privateendpoints.NewService(mcPrivateEndpointsScope, privateLinksScope, a.recorder)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return service.DesiredMcToWcApiPrivateEndpoints(), nil
+}
+
+// workloadClusterPrivateLinkServiceIDs returns the lowercased IDs of the private link services of the workload
+// cluster.
+func workloadClusterPrivateLinkServiceIDs(workloadAzureCluster *capz.AzureCluster) []string {
+	var resourceIDs []string
+	for _, privateLink := range workloadAzureCluster.Spec.NetworkSpec.APIServerLB.PrivateLinks {
+		resourceIDs = append(resourceIDs, strings.ToLower(fmt.Sprintf(
+			"/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Network/privateLinkServices/%s",
+			workloadAzureCluster.Spec.SubscriptionID,
+			workloadAzureCluster.Spec.ResourceGroup,
+			privateLink.Name)))
+	}
+	return resourceIDs
+}
+
+// auditCluster compares the desired private endpoints in the resource group of the cluster with
+// the private endpoints in the spec of the cluster and in Azure. Private endpoints to the
+// unevaluated private link services are not reported as orphaned.
+func (a *Auditor) auditCluster(ctx context.Context, scope privateendpoints.Scope, desired map[string]desiredPrivateEndpoint, unevaluatedPrivateLinkServiceIDs map[string]bool) []Finding {
+	cluster := scope.GetClusterName()
+
+	azurePrivateEndpoints, err := scope.ListAzurePrivateEndpoints(ctx)
+	if err != nil {
+		return []Finding{auditFailed(scope.GetAzureCluster(), err)}
+	}
+	azurePrivateEndpointsByName := map[string]*armnetwork.PrivateEndpoint{}
+	for _, privateEndpoint := range azurePrivateEndpoints {
+		if privateEndpoint != nil && privateEndpoint.Name != nil {
+			azurePrivateEndpointsByName[*privateEndpoint.Name] = privateEndpoint
+		}
+	}
+
+	var findings []Finding
+	for name, desiredPrivateEndpoint := range desired {
+		workloadCluster := client.ObjectKeyFromObject(desiredPrivateEndpoint.workloadCluster)
+		newFinding := func(findingType FindingType, source, messageFmt string, args ...any) Finding {
+			return Finding{
+				Type:            findingType,
+				Cluster:         cluster.String(),
+				WorkloadCluster: workloadCluster.String(),
+				PrivateEndpoint: name,
+				Source:          source,
+				Message:         fmt.Sprintf(messageFmt, args...),
+			}
+		}
+
+		if !scope.ContainsPrivateEndpointSpec(desiredPrivateEndpoint.spec) {
+			findings = append(findings, newFinding(FindingMissing, SourceSpec,
+				"private endpoint is not in the spec of AzureCluster %s", cluster))
+		}
+
+		azurePrivateEndpoint, ok := azurePrivateEndpointsByName[name]
+		if !ok {
+			findings = append(findings, newFinding(FindingMissing, SourceAzure,
+				"private endpoint does not exist in resource group %s", scope.GetResourceGroup()))
+			continue
+		}
+
+		for _, connection := range privateendpoints.PrivateLinkServiceConnections(azurePrivateEndpoint) {
+			if connection.Properties == nil || connection.Properties.PrivateLinkServiceConnectionState == nil ||
+				connection.Properties.PrivateLinkServiceConnectionState.Status == nil {
+				continue
+			}
+			if *connection.Properties.PrivateLinkServiceConnectionState.Status == privateLinkServiceConnectionStatusPending {
+				findings = append(findings, newFinding(FindingPendingApproval, SourceAzure,
+					"connection to private link service %s is waiting for approval", stringValue(connection.Properties.PrivateLinkServiceID)))
+			}
+		}
+
+		ip, err := scope.GetPrivateEndpointIPAddress(ctx, name)
+		if err != nil {
+			findings = append(findings, newFinding(FindingAuditFailed, SourceAzure,
+				"failed to get private endpoint IP address: %s", microerror.Pretty(err, false)))
+			continue
+		}
+		annotatedIP := net.ParseIP(desiredPrivateEndpoint.workloadCluster.GetAnnotations()[desiredPrivateEndpoint.ipAnnotation])
+		if annotatedIP == nil {
+			findings = append(findings, newFinding(FindingIPMismatch, SourceAzure,
+				"private endpoint has IP address %s, but annotation %s is not set on AzureCluster %s",
+				ip, desiredPrivateEndpoint.ipAnnotation, workloadCluster))
+		} else if !annotatedIP.Equal(ip) {
+			findings = append(findings, newFinding(FindingIPMismatch, SourceAzure,
+				"private endpoint has IP address %s, but annotation %s on AzureCluster %s is %s",
+				ip, desiredPrivateEndpoint.ipAnnotation, workloadCluster, annotatedIP))
+		}
+	}
+
+	isOrphaned := func(name string, privateLinkServiceIDs []string) bool {
+		if _, ok := desired[name]; ok || !privateendpoints.ConnectsToPrivateLinkService(privateLinkServiceIDs) {
+			return false
+		}
+		return !slices.ContainsFunc(privateLinkServiceIDs, func(privateLinkServiceID string) bool {
+			return unevaluatedPrivateLinkServiceIDs[strings.ToLower(privateLinkServiceID)]
+		})
+	}
+
+	for _, spec := range scope.GetPrivateEndpoints() {
+		privateLinkServiceIDs := privateendpoints.SpecConnectedResourceIDs(spec)
+		if isOrphaned(spec.Name, privateLinkServiceIDs) {
+			findings = append(findings, Finding{
+				Type:            FindingOrphaned,
+				Cluster:         cluster.String(),
+				PrivateEndpoint: spec.Name,
+				Source:          SourceSpec,
+				Message:         fmt.Sprintf("private endpoint to %s is in the spec of AzureCluster %s, but it is not needed by any workload cluster", strings.Join(privateLinkServiceIDs, ", "), cluster),
+			})
+		}
+	}
+
+	for name, azurePrivateEndpoint := range azurePrivateEndpointsByName {
+		privateLinkServiceIDs := privateendpoints.ConnectedResourceIDs(azurePrivateEndpoint)
+		if isOrphaned(name, privateLinkServiceIDs) {
+			findings = append(findings, Finding{
+				Type:            FindingOrphaned,
+				Cluster:         cluster.String(),
+				PrivateEndpoint: name,
+				Source:          SourceAzure,
+				Message:         fmt.Sprintf("private endpoint to %s exists in resource group %s, but it is not needed by any workload cluster", strings.Join(privateLinkServiceIDs, ", "), scope.GetResourceGroup()),
+			})
+		}
+	}
+
+	return findings
+}
+
+func auditFailed(azureCluster *capz.AzureCluster, err error) Finding {
+	return Finding{
+		Type:    FindingAuditFailed,
+		Cluster: client.ObjectKeyFromObject(azureCluster).String(),
+		Message: microerror.Pretty(err, false),
+	}
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
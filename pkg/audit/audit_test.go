@@ -0,0 +1,223 @@
+package audit_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v9"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/mock/gomock"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	capz "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/audit"
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/azure"
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/azure/mock_azure"
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/errors"
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/privatelinks"
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/testhelpers"
+)
+
+const (
+	wcPrivateEndpointName      = "awesome-wc-privatelink-privateendpoint"
+	gatewayPrivateEndpointName = "awesome-wc-to-giant-gateway-privateendpoint"
+	orphanedPrivateEndpoint    = "deleted-wc-privatelink-privateendpoint"
+)
+
+var _ = Describe("Auditor", func() {
+	var gomockController *gomock.Controller
+	var mcPrivateEndpointsClient *mock_azure.MockPrivateEndpointsClient
+	var wcPrivateEndpointsClient *mock_azure.MockPrivateEndpointsClient
+	var managementAzureCluster *capz.AzureCluster
+	var workloadAzureCluster *capz.AzureCluster
+	var auditor *audit.Auditor
+
+	privateEndpointToPrivateLinkService := func(name, privateLinkServiceID, status string) *armnetwork.PrivateEndpoint {
+		return &armnetwork.PrivateEndpoint{
+			Name: to.Ptr(name),
+			Properties: &armnetwork.PrivateEndpointProperties{
+				ManualPrivateLinkServiceConnections: []*armnetwork.PrivateLinkServiceConnection{
+					{
+						Properties: &armnetwork.PrivateLinkServiceConnectionProperties{
+							PrivateLinkServiceID: to.Ptr(privateLinkServiceID),
+							PrivateLinkServiceConnectionState: &armnetwork.PrivateLinkServiceConnectionState{
+								Status: to.Ptr(status),
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	BeforeEach(func() {
+		gomockController = gomock.NewController(GinkgoT())
+		mcPrivateEndpointsClient = mock_azure.NewMockPrivateEndpointsClient(gomockController)
+		wcPrivateEndpointsClient = mock_azure.NewMockPrivateEndpointsClient(gomockController)
+
+		managementAzureCluster = testhelpers.NewAzureClusterBuilder("org-giantswarm", "giant").
+			WithSubscriptionID("mc-subscription").
+			WithResourceGroup("giant").
+			WithLocation("westeurope").
+			WithAPILoadBalancerType(capz.Internal).
+			WithSubnet("giant-node-subnet", capz.SubnetNode, capz.PrivateEndpoints{
+				{
+					Name: wcPrivateEndpointName,
+					PrivateLinkServiceConnections: []capz.PrivateLinkServiceConnection{
+						{PrivateLinkServiceID: "/subscriptions/wc-subscription/resourceGroups/awesome-wc/providers/Microsoft.Network/privateLinkServices/awesome-wc-privatelink"},
+					},
+				},
+				{
+					Name: orphanedPrivateEndpoint,
+					PrivateLinkServiceConnections: []capz.PrivateLinkServiceConnection{
+						{PrivateLinkServiceID: "/subscriptions/wc-subscription/resourceGroups/deleted-wc/providers/Microsoft.Network/privateLinkServices/deleted-wc-privatelink"},
+					},
+				},
+				{
+					Name: "storage-privateendpoint",
+					PrivateLinkServiceConnections: []capz.PrivateLinkServiceConnection{
+						{PrivateLinkServiceID: "/subscriptions/mc-subscription/resourceGroups/giant/providers/Microsoft.Storage/storageAccounts/giant"},
+					},
+				},
+			}).
+			Build()
+
+		workloadAzureCluster = testhelpers.NewAzureClusterBuilder("org-acme", "awesome-wc").
+			WithSubscriptionID("wc-subscription").
+			WithResourceGroup("awesome-wc").
+			WithLocation("westeurope").
+			WithAPILoadBalancerType(capz.Internal).
+			WithPrivateLink(capz.PrivateLink{
+				Name:                 "awesome-wc-privatelink",
+				AllowedSubscriptions: []*string{to.Ptr("mc-subscription")},
+			}).
+			WithSubnet("awesome-wc-node-subnet", capz.SubnetNode, nil).
+			Build()
+		workloadAzureCluster.Annotations = map[string]string{
+			privatelinks.AzurePrivateEndpointOperatorApiServerAnnotation: "10.0.0.5",
+		}
+
+		scheme := runtime.NewScheme()
+		Expect(capz.AddToScheme(scheme)).To(Succeed())
+		k8sClient := fake.NewClientBuilder().
+			WithScheme(scheme).
+			WithObjects(managementAzureCluster, workloadAzureCluster).
+			Build()
+
+		privateEndpointsClientCreator := func(_ context.Context, _ client.Client, azureCluster *capz.AzureCluster) (azure.PrivateEndpointsClient, error) {
+			if azureCluster.Name == managementAzureCluster.Name {
+				return mcPrivateEndpointsClient, nil
+			}
+			return wcPrivateEndpointsClient, nil
+		}
+
+		var err error
+		auditor, err = audit.NewAuditor(k8sClient, privateEndpointsClientCreator, client.ObjectKeyFromObject(managementAzureCluster))
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("fails to create auditor when the management cluster name is not set", func() {
+		_, err := audit.NewAuditor(fake.NewClientBuilder().Build(), azure.NewPrivateEndpointClient, types.NamespacedName{})
+		Expect(errors.IsInvalidConfig(err)).To(BeTrue())
+	})
+
+	It("reports missing, orphaned, pending approval and IP mismatch findings", func(ctx context.Context) {
+		testhelpers.SetupPrivateEndpointClientToListPrivateEndpoints(mcPrivateEndpointsClient, "giant",
+			privateEndpointToPrivateLinkService(wcPrivateEndpointName,
+				"/subscriptions/wc-subscription/resourceGroups/awesome-wc/providers/Microsoft.Network/privateLinkServices/awesome-wc-privatelink",
+				"Pending"),
+			privateEndpointToPrivateLinkService("unknown-privateendpoint",
+				"/subscriptions/other-subscription/resourceGroups/other/providers/Microsoft.Network/privateLinkServices/other-privatelink",
+				"Approved"))
+		testhelpers.SetupPrivateEndpointClientToReturnPrivateIp(mcPrivateEndpointsClient, "giant", wcPrivateEndpointName, "10.0.0.6")
+		testhelpers.SetupPrivateEndpointClientToListPrivateEndpoints(wcPrivateEndpointsClient, "awesome-wc")
+
+		report, err := auditor.Audit(ctx)
+		Expect(err).NotTo(HaveOccurred())
+
+		type finding struct {
+			Type            audit.FindingType
+			Cluster         string
+			PrivateEndpoint string
+			Source          string
+		}
+		var findings []finding
+		for _, f := range report.Findings {
+			findings = append(findings, finding{f.Type, f.Cluster, f.PrivateEndpoint, f.Source})
+		}
+		Expect(findings).To(Equal([]finding{
+			{audit.FindingMissing, "org-acme/awesome-wc", gatewayPrivateEndpointName, audit.SourceAzure},
+			{audit.FindingMissing, "org-acme/awesome-wc", gatewayPrivateEndpointName, audit.SourceSpec},
+			{audit.FindingIPMismatch, "org-giantswarm/giant", wcPrivateEndpointName, audit.SourceAzure},
+			{audit.FindingPendingApproval, "org-giantswarm/giant", wcPrivateEndpointName, audit.SourceAzure},
+			{audit.FindingOrphaned, "org-giantswarm/giant", orphanedPrivateEndpoint, audit.SourceSpec},
+			{audit.FindingOrphaned, "org-giantswarm/giant", "unknown-privateendpoint", audit.SourceAzure},
+		}))
+	})
+
+	It("reports private endpoints whose IP address can not be read as not audited", func(ctx context.Context) {
+		testhelpers.SetupPrivateEndpointClientToListPrivateEndpoints(mcPrivateEndpointsClient, "giant",
+			privateEndpointToPrivateLinkService(wcPrivateEndpointName,
+				"/subscriptions/wc-subscription/resourceGroups/awesome-wc/providers/Microsoft.Network/privateLinkServices/awesome-wc-privatelink",
+				"Approved"))
+		testhelpers.SetupPrivateEndpointClientToReturnNotFound(mcPrivateEndpointsClient, "giant", wcPrivateEndpointName)
+		testhelpers.SetupPrivateEndpointClientToListPrivateEndpoints(wcPrivateEndpointsClient, "awesome-wc")
+
+		report, err := auditor.Audit(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(report.Findings).To(ContainElement(And(
+			HaveField("Type", audit.FindingAuditFailed),
+			HaveField("Cluster", "org-giantswarm/giant"),
+			HaveField("PrivateEndpoint", wcPrivateEndpointName))))
+		Expect(report.Findings).NotTo(ContainElement(HaveField("Type", audit.FindingIPMismatch)))
+	})
+
+	It("reports the clusters that can not be audited", func(ctx context.Context) {
+		testhelpers.SetupPrivateEndpointClientToListPrivateEndpoints(mcPrivateEndpointsClient, "giant")
+		workloadAzureCluster.Spec.NetworkSpec.Subnets = nil
+		auditor, err := audit.NewAuditor(
+			fake.NewClientBuilder().
+				WithScheme(func() *runtime.Scheme {
+					scheme := runtime.NewScheme()
+					Expect(capz.AddToScheme(scheme)).To(Succeed())
+					return scheme
+				}()).
+				WithObjects(managementAzureCluster, workloadAzureCluster).
+				Build(),
+			func(context.Context, client.Client, *capz.AzureCluster) (azure.PrivateEndpointsClient, error) {
+				return mcPrivateEndpointsClient, nil
+			},
+			client.ObjectKeyFromObject(managementAzureCluster))
+		Expect(err).NotTo(HaveOccurred())
+
+		report, err := auditor.Audit(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(report.Findings).To(ContainElement(And(
+			HaveField("Type", audit.FindingAuditFailed),
+			HaveField("Cluster", "org-acme/awesome-wc"))))
+	})
+
+	It("writes the report as JSON", func() {
+		report := audit.Report{Findings: []audit.Finding{{
+			Type:            audit.FindingMissing,
+			Cluster:         "org-giantswarm/giant",
+			WorkloadCluster: "org-acme/awesome-wc",
+			PrivateEndpoint: wcPrivateEndpointName,
+			Source:          audit.SourceAzure,
+			Message:         "private endpoint does not exist in resource group giant",
+		}}}
+
+		var output bytes.Buffer
+		Expect(report.WriteJSON(&output)).To(Succeed())
+
+		var decoded audit.Report
+		Expect(json.Unmarshal(output.Bytes(), &decoded)).To(Succeed())
+		Expect(decoded).To(Equal(report))
+	})
+})
@@ -0,0 +1,45 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/giantswarm/microerror"
+)
+
+// WriteTable writes the findings as a table with one finding per line.
+func (r *Report) WriteTable(w io.Writer) error {
+	if len(r.Findings) == 0 {
+		_, err := fmt.Fprintln(w, "No findings.")
+		return microerror.Mask(err)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "TYPE\tCLUSTER\tPRIVATE ENDPOINT\tSOURCE\tWORKLOAD CLUSTER\tMESSAGE")
+	for _, finding := range r.Findings {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			finding.Type,
+			finding.Cluster,
+			orDash(finding.PrivateEndpoint),
+			orDash(finding.Source),
+			orDash(finding.WorkloadCluster),
+			finding.Message)
+	}
+	return microerror.Mask(tw.Flush())
+}
+
+// WriteJSON writes the report as JSON.
+func (r *Report) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return microerror.Mask(encoder.Encode(r))
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,300 @@
+package azure
+
+import (
+	"context"
+	"sync"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/giantswarm/microerror"
+	"k8s.io/apimachinery/pkg/types"
+	toolscache "k8s.io/client-go/tools/cache"
+	capz "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ClientCacheOptions holds optional configuration for ClientCache.
+type ClientCacheOptions struct {
+	// FallbackCredential creates the credential for AzureClusters without an IdentityRef, once per
+	// Azure cloud. When it is nil, such AzureClusters fail with IdentityRefNotSetError.
+	FallbackCredential FallbackCredentialCreator
+	// ResourceManagerEndpoint replaces the ARM endpoint of the cloud of every AzureCluster, e.g. to
+	// use a local ARM stand-in in tests.
+	ResourceManagerEndpoint string
+	// Transport replaces the HTTP client of the Azure clients, e.g. to trust the certificate of a
+	// local ARM stand-in in tests.
+	Transport policy.Transporter
+}
+
+// ClientCache caches Azure credentials and clients per AzureClusterIdentity, so that the tokens
+// acquired by a credential are reused across reconciliations and controllers, instead of
+// requesting a new token from Azure AD every time a client is needed.
+//
+// A cached credential is invalidated when the AzureClusterIdentity or its client Secret change,
+// which is detected by comparing their resource versions, and evicted when the
+// AzureClusterIdentity is deleted. Every cached credential has its own context, which is cancelled
+// when it is invalidated or evicted, so that e.g. the credentials file of a
+// UserAssignedIdentityCredential is not watched forever.
+//
+// AzureClusters without an IdentityRef use the fallback credential of the operator, when it is set.
+type ClientCache struct {
+	mu         sync.Mutex
+	options    ClientCacheOptions
+	identities map[identityCacheKey]*cachedIdentity
+}
+
+// identityCacheKey identifies the cached credential of an AzureClusterIdentity in an Azure cloud,
+// as the credentials for different clouds use different AAD authorities. The fallback credential
+// has an empty UID.
+type identityCacheKey struct {
+	uid              types.UID
+	azureEnvironment string
+}
+
+type cachedIdentity struct {
+	// version is the resource version of the AzureClusterIdentity and of its client Secret, from
+	// which the credential was created.
+	version    string
+	credential azcore.TokenCredential
+	// cancel cancels the context of the credential. It is nil for the fallback credential, which
+	// is never evicted.
+	cancel                  context.CancelFunc
+	privateEndpointsClients map[string]PrivateEndpointsClient
+	// federatedIdentityCredentialsClients are cached with the resource IDs of the identities
+	// that they found.
+	federatedIdentityCredentialsClients map[string]FederatedIdentityCredentialsClient
+}
+
+func NewClientCache(options ClientCacheOptions) *ClientCache {
+	return &ClientCache{
+		options:    options,
+		identities: map[identityCacheKey]*cachedIdentity{},
+	}
+}
+
+// NewPrivateEndpointClient returns a private endpoints client for the subscription of the
+// AzureCluster that uses the cached credential of the AzureCluster's AzureClusterIdentity. It can
+// be used as PrivateEndpointsClientCreator.
+func (c *ClientCache) NewPrivateEndpointClient(ctx context.Context, client client.Client, azureCluster *capz.AzureCluster) (PrivateEndpointsClient, error) {
+	cloudConfig, err := cloudConfiguration(azureCluster.Spec.AzureEnvironment, c.options.ResourceManagerEndpoint)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	identity, err := c.getIdentityForCluster(ctx, client, azureCluster, cloudConfig)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	subscriptionID := azureCluster.Spec.SubscriptionID
+	if privateEndpointsClient, ok := identity.privateEndpointsClients[subscriptionID]; ok {
+		return privateEndpointsClient, nil
+	}
+
+	privateEndpointsClient, err := newPrivateEndpointsClient(subscriptionID, identity.credential, azcore.ClientOptions{
+		Cloud:     cloudConfig,
+		Transport: c.options.Transport,
+	})
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	identity.privateEndpointsClients[subscriptionID] = privateEndpointsClient
+
+	return privateEndpointsClient, nil
+}
+
+// NewFederatedIdentityCredentialsClient returns a federated identity credentials client for the
+// subscription of the AzureCluster that uses the cached credential of the AzureCluster's
+// AzureClusterIdentity. The client is cached as well, so that it finds the identities only once.
+// It can be used as FederatedIdentityCredentialsClientCreator.
+func (c *ClientCache) NewFederatedIdentityCredentialsClient(ctx context.Context, client client.Client, azureCluster *capz.AzureCluster) (FederatedIdentityCredentialsClient, error) {
+	cloudConfig, err := cloudConfiguration(azureCluster.Spec.AzureEnvironment, c.options.ResourceManagerEndpoint)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	identity, err := c.getIdentityForCluster(ctx, client, azureCluster, cloudConfig)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	subscriptionID := azureCluster.Spec.SubscriptionID
+	if credentialsClient, ok := identity.federatedIdentityCredentialsClients[subscriptionID]; ok {
+		return credentialsClient, nil
+	}
+
+	credentialsClient, err := newFederatedIdentityCredentialsClient(subscriptionID, identity.credential, azcore.ClientOptions{
+		Cloud:     cloudConfig,
+		Transport: c.options.Transport,
+	})
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	identity.federatedIdentityCredentialsClients[subscriptionID] = credentialsClient
+
+	return credentialsClient, nil
+}
+
+// GetCredential returns the cached credential for the AzureCluster, creating it when it is not
+// cached yet or when its AzureClusterIdentity or client Secret have changed.
+func (c *ClientCache) GetCredential(ctx context.Context, client client.Client, azureCluster *capz.AzureCluster) (azcore.TokenCredential, error) {
+	cloudConfig, err := cloudConfiguration(azureCluster.Spec.AzureEnvironment, c.options.ResourceManagerEndpoint)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	identity, err := c.getIdentityForCluster(ctx, client, azureCluster, cloudConfig)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return identity.credential, nil
+}
+
+func (c *ClientCache) getIdentityForCluster(ctx context.Context, client client.Client, azureCluster *capz.AzureCluster, cloudConfig cloud.Configuration) (*cachedIdentity, error) {
+	if azureCluster.Spec.IdentityRef == nil && c.options.FallbackCredential != nil {
+		identity, err := c.getFallbackIdentity(azureCluster.Spec.AzureEnvironment, cloudConfig)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+		return identity, nil
+	}
+
+	azureClusterIdentity, err := getAzureClusterIdentity(ctx, client, azureCluster)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	identity, err := c.getIdentity(ctx, client, azureClusterIdentity, azureCluster.Spec.AzureEnvironment, cloudConfig)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return identity, nil
+}
+
+// getFallbackIdentity returns the fallback credential with its cached clients for the Azure
+// environment.
+func (c *ClientCache) getFallbackIdentity(azureEnvironment string, cloudConfig cloud.Configuration) (*cachedIdentity, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := identityCacheKey{azureEnvironment: azureEnvironment}
+	if identity, ok := c.identities[key]; ok {
+		return identity, nil
+	}
+
+	credential, err := c.options.FallbackCredential(cloudConfig)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	identity := &cachedIdentity{
+		credential:                          credential,
+		privateEndpointsClients:             map[string]PrivateEndpointsClient{},
+		federatedIdentityCredentialsClients: map[string]FederatedIdentityCredentialsClient{},
+	}
+	c.identities[key] = identity
+
+	return identity, nil
+}
+
+func (c *ClientCache) getIdentity(ctx context.Context, client client.Client, azureClusterIdentity *capz.AzureClusterIdentity, azureEnvironment string, cloudConfig cloud.Configuration) (*cachedIdentity, error) {
+	secret, err := getClientSecret(ctx, client, azureClusterIdentity)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	version := azureClusterIdentity.ResourceVersion
+	if secret != nil {
+		version += "/" + secret.ResourceVersion
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := identityCacheKey{
+		uid:              azureClusterIdentity.UID,
+		azureEnvironment: azureEnvironment,
+	}
+	cached, ok := c.identities[key]
+	if ok && cached.version == version {
+		return cached, nil
+	}
+
+	// The credential outlives the reconciliation, until it is invalidated or evicted.
+	credentialCtx, cancel := context.WithCancel(context.Background())
+	credential, err := newCredentialWithSecret(credentialCtx, azureClusterIdentity, secret, cloudConfig)
+	if err != nil {
+		cancel()
+		return nil, microerror.Mask(err)
+	}
+	if ok {
+		cached.close()
+	}
+
+	identity := &cachedIdentity{
+		version:                             version,
+		credential:                          credential,
+		cancel:                              cancel,
+		privateEndpointsClients:             map[string]PrivateEndpointsClient{},
+		federatedIdentityCredentialsClients: map[string]FederatedIdentityCredentialsClient{},
+	}
+	c.identities[key] = identity
+
+	return identity, nil
+}
+
+// Evict removes the cached credentials and clients of the AzureClusterIdentity in all Azure clouds.
+func (c *ClientCache) Evict(uid types.UID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key, identity := range c.identities {
+		if key.uid == uid {
+			identity.close()
+			delete(c.identities, key)
+		}
+	}
+}
+
+// close cancels the context of the credential.
+func (i *cachedIdentity) close() {
+	if i.cancel != nil {
+		i.cancel()
+	}
+}
+
+// SetupWithManager evicts the cached credentials and clients of AzureClusterIdentities when they
+// are deleted, so that the credentials of deleted identities are not kept forever.
+func (c *ClientCache) SetupWithManager(mgr ctrl.Manager) error {
+	// The informer is only started with the manager, so getting it does not block.
+	informer, err := mgr.GetCache().GetInformer(context.Background(), &capz.AzureClusterIdentity{})
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	_, err = informer.AddEventHandler(toolscache.ResourceEventHandlerFuncs{
+		DeleteFunc: func(obj any) {
+			if tombstone, ok := obj.(toolscache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			if azureClusterIdentity, ok := obj.(*capz.AzureClusterIdentity); ok && azureClusterIdentity.UID != "" {
+				c.Evict(azureClusterIdentity.UID)
+			}
+		},
+	})
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
@@ -0,0 +1,244 @@
+package azure_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
+	azfake "github.com/Azure/azure-sdk-for-go/sdk/azcore/fake"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	capz "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/azure"
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/errors"
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/testhelpers"
+)
+
+var _ = Describe("ClientCache", func() {
+	var k8sClient client.Client
+	var azureClusterIdentity *capz.AzureClusterIdentity
+	var secret *corev1.Secret
+	var azureCluster *capz.AzureCluster
+	var clientCache *azure.ClientCache
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(capz.AddToScheme(scheme)).To(Succeed())
+
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "giantswarm",
+				Name:      "cluster-identity-secret",
+			},
+			Data: map[string][]byte{
+				"clientSecret": []byte("super-secret"),
+			},
+		}
+		azureClusterIdentity = testhelpers.NewAzureClusterIdentityBuilder("org-giantswarm", "cluster-identity").
+			WithType(capz.ManualServicePrincipal).
+			WithTenantID("tenant").
+			WithClientID("client").
+			WithClientSecret(secret.Namespace, secret.Name).
+			Build()
+		azureClusterIdentity.UID = "cluster-identity-uid"
+		azureCluster = testhelpers.NewAzureClusterBuilder("org-giantswarm", "awesome-wc").
+			WithSubscriptionID("1234").
+			WithIdentity(azureClusterIdentity).
+			Build()
+
+		k8sClient = fake.NewClientBuilder().
+			WithScheme(scheme).
+			WithObjects(secret, azureClusterIdentity, azureCluster).
+			Build()
+		clientCache = azure.NewClientCache(azure.ClientCacheOptions{})
+	})
+
+	It("reuses the client for the same identity and subscription", func(ctx context.Context) {
+		first, err := clientCache.NewPrivateEndpointClient(ctx, k8sClient, azureCluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		second, err := clientCache.NewPrivateEndpointClient(ctx, k8sClient, azureCluster)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(second).To(BeIdenticalTo(first))
+	})
+
+	It("creates a new client for a different subscription with the same credential", func(ctx context.Context) {
+		first, err := clientCache.NewPrivateEndpointClient(ctx, k8sClient, azureCluster)
+		Expect(err).NotTo(HaveOccurred())
+		credential, err := clientCache.GetCredential(ctx, k8sClient, azureCluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		azureCluster.Spec.SubscriptionID = "5678"
+		second, err := clientCache.NewPrivateEndpointClient(ctx, k8sClient, azureCluster)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(second).NotTo(BeIdenticalTo(first))
+
+		sameCredential, err := clientCache.GetCredential(ctx, k8sClient, azureCluster)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(sameCredential).To(BeIdenticalTo(credential))
+	})
+
+	It("creates a new credential when the client secret changes", func(ctx context.Context) {
+		first, err := clientCache.NewPrivateEndpointClient(ctx, k8sClient, azureCluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		secret.Data["clientSecret"] = []byte("rotated-secret")
+		Expect(k8sClient.Update(ctx, secret)).To(Succeed())
+
+		second, err := clientCache.NewPrivateEndpointClient(ctx, k8sClient, azureCluster)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(second).NotTo(BeIdenticalTo(first))
+	})
+
+	It("creates a new credential when the identity changes", func(ctx context.Context) {
+		first, err := clientCache.NewPrivateEndpointClient(ctx, k8sClient, azureCluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		azureClusterIdentity.Spec.ClientID = "other-client"
+		Expect(k8sClient.Update(ctx, azureClusterIdentity)).To(Succeed())
+
+		second, err := clientCache.NewPrivateEndpointClient(ctx, k8sClient, azureCluster)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(second).NotTo(BeIdenticalTo(first))
+	})
+
+	When("AzureCluster does not have an IdentityRef", func() {
+		BeforeEach(func() {
+			azureCluster = testhelpers.NewAzureClusterBuilder("org-giantswarm", "awesome-wc").
+				WithSubscriptionID("1234").
+				Build()
+		})
+
+		It("uses the fallback credential", func(ctx context.Context) {
+			clientCache = azure.NewClientCache(azure.ClientCacheOptions{
+				FallbackCredential: newFakeCredential,
+			})
+
+			first, err := clientCache.NewPrivateEndpointClient(ctx, k8sClient, azureCluster)
+			Expect(err).NotTo(HaveOccurred())
+
+			second, err := clientCache.NewPrivateEndpointClient(ctx, k8sClient, azureCluster)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(second).To(BeIdenticalTo(first))
+		})
+
+		It("creates the fallback credential for the Azure cloud of the AzureCluster", func(ctx context.Context) {
+			var cloudConfigs []cloud.Configuration
+			clientCache = azure.NewClientCache(azure.ClientCacheOptions{
+				FallbackCredential: func(cloudConfig cloud.Configuration) (azcore.TokenCredential, error) {
+					cloudConfigs = append(cloudConfigs, cloudConfig)
+					return &azfake.TokenCredential{}, nil
+				},
+			})
+
+			publicCloudCredential, err := clientCache.GetCredential(ctx, k8sClient, azureCluster)
+			Expect(err).NotTo(HaveOccurred())
+
+			azureCluster.Spec.AzureEnvironment = azure.ChinaCloudName
+			chinaCloudCredential, err := clientCache.GetCredential(ctx, k8sClient, azureCluster)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(chinaCloudCredential).NotTo(BeIdenticalTo(publicCloudCredential))
+
+			_, err = clientCache.GetCredential(ctx, k8sClient, azureCluster)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cloudConfigs).To(HaveLen(2))
+			Expect(cloudConfigs[0].ActiveDirectoryAuthorityHost).To(Equal(cloud.AzurePublic.ActiveDirectoryAuthorityHost))
+			Expect(cloudConfigs[1].ActiveDirectoryAuthorityHost).To(Equal(cloud.AzureChina.ActiveDirectoryAuthorityHost))
+		})
+
+		It("returns IdentityRefNotSetError without a fallback credential", func(ctx context.Context) {
+			_, err := clientCache.NewPrivateEndpointClient(ctx, k8sClient, azureCluster)
+			Expect(errors.IsIdentityRefNotSet(err)).To(BeTrue())
+		})
+	})
+
+	It("creates a new credential after the identity has been evicted", func(ctx context.Context) {
+		first, err := clientCache.NewPrivateEndpointClient(ctx, k8sClient, azureCluster)
+		Expect(err).NotTo(HaveOccurred())
+		credential, err := clientCache.GetCredential(ctx, k8sClient, azureCluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		clientCache.Evict(azureClusterIdentity.UID)
+
+		second, err := clientCache.NewPrivateEndpointClient(ctx, k8sClient, azureCluster)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(second).NotTo(BeIdenticalTo(first))
+		newCredential, err := clientCache.GetCredential(ctx, k8sClient, azureCluster)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(newCredential).NotTo(BeIdenticalTo(credential))
+	})
+
+	It("keeps the credentials of other identities when an identity is evicted", func(ctx context.Context) {
+		credential, err := clientCache.GetCredential(ctx, k8sClient, azureCluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		clientCache.Evict("other-identity-uid")
+
+		sameCredential, err := clientCache.GetCredential(ctx, k8sClient, azureCluster)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(sameCredential).To(BeIdenticalTo(credential))
+	})
+
+	It("creates a new credential for an AzureCluster in a different Azure cloud", func(ctx context.Context) {
+		publicCloudCredential, err := clientCache.GetCredential(ctx, k8sClient, azureCluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		azureCluster.Spec.AzureEnvironment = azure.ChinaCloudName
+		chinaCloudCredential, err := clientCache.GetCredential(ctx, k8sClient, azureCluster)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(chinaCloudCredential).NotTo(BeIdenticalTo(publicCloudCredential))
+	})
+
+	It("returns UnknownAzureEnvironmentError for an unknown Azure cloud", func(ctx context.Context) {
+		azureCluster.Spec.AzureEnvironment = "AzureMoonCloud"
+		_, err := clientCache.NewPrivateEndpointClient(ctx, k8sClient, azureCluster)
+		Expect(errors.IsUnknownAzureEnvironment(err)).To(BeTrue())
+	})
+
+	When("a custom ARM endpoint is set", func() {
+		var armServer *httptest.Server
+		var requestedPaths []string
+
+		BeforeEach(func() {
+			requestedPaths = nil
+			armServer = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requestedPaths = append(requestedPaths, r.URL.Path)
+				w.WriteHeader(http.StatusNotFound)
+			}))
+			DeferCleanup(armServer.Close)
+
+			clientCache = azure.NewClientCache(azure.ClientCacheOptions{
+				FallbackCredential:      newFakeCredential,
+				ResourceManagerEndpoint: armServer.URL,
+				Transport:               armServer.Client(),
+			})
+			azureCluster = testhelpers.NewAzureClusterBuilder("org-giantswarm", "awesome-wc").
+				WithSubscriptionID("1234").
+				Build()
+		})
+
+		It("sends the requests to the custom ARM endpoint", func(ctx context.Context) {
+			privateEndpointsClient, err := clientCache.NewPrivateEndpointClient(ctx, k8sClient, azureCluster)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = privateEndpointsClient.Get(ctx, "awesome-wc", "awesome-privateendpoint", nil)
+			Expect(errors.IsAzureResourceNotFound(err)).To(BeTrue())
+			Expect(requestedPaths).To(ConsistOf(
+				"/subscriptions/1234/resourceGroups/awesome-wc/providers/Microsoft.Network/privateEndpoints/awesome-privateendpoint"))
+		})
+	})
+})
+
+func newFakeCredential(cloud.Configuration) (azcore.TokenCredential, error) {
+	return &azfake.TokenCredential{}, nil
+}
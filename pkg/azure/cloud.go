@@ -0,0 +1,64 @@
+package azure
+
+import (
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
+	"github.com/giantswarm/microerror"
+
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/errors"
+)
+
+const (
+	// Azure environment names, as used in AzureCluster.Spec.AzureEnvironment.
+	PublicCloudName       = "AzurePublicCloud"
+	ChinaCloudName        = "AzureChinaCloud"
+	USGovernmentCloudName = "AzureUSGovernmentCloud"
+	GermanCloudName       = "AzureGermanCloud"
+)
+
+// cloudConfiguration returns the cloud configuration (ARM endpoint, AAD authority and token
+// audience) for the Azure environment of an AzureCluster, in the same way as CAPZ does it. An empty
+// environment is the public cloud.
+//
+// When resourceManagerEndpoint is set, it replaces the ARM endpoint of the cloud, e.g. to use a
+// local ARM stand-in in tests.
+func cloudConfiguration(azureEnvironment, resourceManagerEndpoint string) (cloud.Configuration, error) {
+	var cloudConfig cloud.Configuration
+	switch azureEnvironment {
+	case "", PublicCloudName:
+		cloudConfig = cloud.AzurePublic
+	case ChinaCloudName:
+		cloudConfig = cloud.AzureChina
+	case USGovernmentCloudName:
+		cloudConfig = cloud.AzureGovernment
+	case GermanCloudName:
+		// German cloud is not built into the Azure SDK.
+		cloudConfig = cloud.Configuration{
+			ActiveDirectoryAuthorityHost: "https://login.microsoftonline.de/",
+			Services: map[cloud.ServiceName]cloud.ServiceConfiguration{
+				cloud.ResourceManager: {
+					Audience: "https://management.microsoftazure.de/",
+					Endpoint: "https://management.microsoftazure.de/",
+				},
+			},
+		}
+	default:
+		return cloud.Configuration{}, microerror.Maskf(errors.UnknownAzureEnvironmentError,
+			"expected Azure environment %s, %s, %s or %s, got %s",
+			PublicCloudName,
+			ChinaCloudName,
+			USGovernmentCloudName,
+			GermanCloudName,
+			azureEnvironment)
+	}
+
+	if resourceManagerEndpoint != "" {
+		// Copy the services, so that the shared configurations of the Azure SDK are not modified.
+		resourceManager := cloudConfig.Services[cloud.ResourceManager]
+		resourceManager.Endpoint = resourceManagerEndpoint
+		cloudConfig.Services = map[cloud.ServiceName]cloud.ServiceConfiguration{
+			cloud.ResourceManager: resourceManager,
+		}
+	}
+
+	return cloudConfig, nil
+}
@@ -0,0 +1,286 @@
+package azure
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/msi-dataplane/pkg/dataplane"
+	"github.com/giantswarm/microerror"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	capz "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/errors"
+)
+
+const (
+	clientSecretKeyName = "clientSecret"
+
+	// FallbackCredentialNone disables the fallback credential, so AzureClusters without an
+	// IdentityRef can not be reconciled.
+	FallbackCredentialNone = "none"
+	// FallbackCredentialDefault uses azidentity.DefaultAzureCredential of the operator, which is
+	// configured with the standard AZURE_* environment variables, e.g. from Azure workload
+	// identity.
+	FallbackCredentialDefault = "default"
+)
+
+// FallbackCredentialCreator creates the operator-level credential for the cloud of an AzureCluster
+// without an IdentityRef.
+type FallbackCredentialCreator func(cloudConfig cloud.Configuration) (azcore.TokenCredential, error)
+
+// NewFallbackCredentialCreator returns the creator of the operator-level credential that is used
+// for AzureClusters without an IdentityRef. It returns nil for FallbackCredentialNone.
+func NewFallbackCredentialCreator(fallbackCredential string) (FallbackCredentialCreator, error) {
+	switch fallbackCredential {
+	case FallbackCredentialNone:
+		return nil, nil
+	case FallbackCredentialDefault:
+		return newDefaultCredential, nil
+	default:
+		return nil, microerror.Maskf(errors.InvalidConfigError,
+			"fallback credential must be %s or %s, got %s",
+			FallbackCredentialNone,
+			FallbackCredentialDefault,
+			fallbackCredential)
+	}
+}
+
+func newDefaultCredential(cloudConfig cloud.Configuration) (azcore.TokenCredential, error) {
+	cred, err := azidentity.NewDefaultAzureCredential(&azidentity.DefaultAzureCredentialOptions{
+		ClientOptions: azcore.ClientOptions{
+			Cloud: cloudConfig,
+		},
+	})
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	return cred, nil
+}
+
+func getAzureClusterIdentity(ctx context.Context, client client.Client, azureCluster *capz.AzureCluster) (*capz.AzureClusterIdentity, error) {
+	if azureCluster.Spec.IdentityRef == nil {
+		return nil, microerror.Maskf(errors.IdentityRefNotSetError,
+			"AzureCluster %s/%s does not have an IdentityRef",
+			azureCluster.Namespace,
+			azureCluster.Name)
+	}
+
+	azureClusterIdentity := &capz.AzureClusterIdentity{}
+	name := types.NamespacedName{
+		Namespace: azureCluster.Spec.IdentityRef.Namespace,
+		Name:      azureCluster.Spec.IdentityRef.Name,
+	}
+	err := client.Get(ctx, name, azureClusterIdentity)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	allowed, err := isClusterNamespaceAllowed(ctx, client, azureClusterIdentity.Spec.AllowedNamespaces, azureCluster.Namespace)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	if !allowed {
+		return nil, microerror.Maskf(errors.AzureClusterIdentityNotAllowedError,
+			"AzureClusterIdentity %s/%s does not allow AzureClusters from namespace %s",
+			azureClusterIdentity.Namespace,
+			azureClusterIdentity.Name,
+			azureCluster.Namespace)
+	}
+
+	return azureClusterIdentity, nil
+}
+
+// isClusterNamespaceAllowed checks if AzureClusters from the namespace are allowed to use the
+// identity, in the same way as CAPZ does it:
+//   - nil allowedNamespaces allows no namespaces,
+//   - empty allowedNamespaces allows all namespaces,
+//   - otherwise the namespace must be in the namespace list, or match the label selector, where
+//     an empty selector matches no namespaces.
+func isClusterNamespaceAllowed(ctx context.Context, k8sClient client.Client, allowedNamespaces *capz.AllowedNamespaces, namespace string) (bool, error) {
+	if allowedNamespaces == nil {
+		return false, nil
+	}
+
+	if reflect.DeepEqual(*allowedNamespaces, capz.AllowedNamespaces{}) {
+		return true, nil
+	}
+
+	if slices.Contains(allowedNamespaces.NamespaceList, namespace) {
+		return true, nil
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(allowedNamespaces.Selector)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+	if selector.Empty() {
+		return false, nil
+	}
+
+	var namespaces corev1.NamespaceList
+	err = k8sClient.List(ctx, &namespaces, client.MatchingLabelsSelector{Selector: selector})
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+	for _, n := range namespaces.Items {
+		if n.Name == namespace {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// hasClientSecret returns true when the identity type uses the Secret referenced by the
+// AzureClusterIdentity, i.e. for a service principal client secret, or for a service principal
+// certificate that is not read from CertPath.
+func hasClientSecret(azureClusterIdentity *capz.AzureClusterIdentity) bool {
+	switch azureClusterIdentity.Spec.Type {
+	case capz.ServicePrincipal, capz.ManualServicePrincipal:
+		return true
+	case capz.ServicePrincipalCertificate:
+		return azureClusterIdentity.Spec.CertPath == ""
+	default:
+		return false
+	}
+}
+
+// getClientSecret returns the Secret referenced by the AzureClusterIdentity, or nil when the
+// identity type does not use a Secret.
+func getClientSecret(ctx context.Context, client client.Client, azureClusterIdentity *capz.AzureClusterIdentity) (*corev1.Secret, error) {
+	if !hasClientSecret(azureClusterIdentity) {
+		return nil, nil
+	}
+
+	clientSecretName := types.NamespacedName{
+		Namespace: azureClusterIdentity.Spec.ClientSecret.Namespace,
+		Name:      azureClusterIdentity.Spec.ClientSecret.Name,
+	}
+	secret := &corev1.Secret{}
+	err := client.Get(ctx, clientSecretName, secret)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return secret, nil
+}
+
+func newCredential(ctx context.Context, client client.Client, azureClusterIdentity *capz.AzureClusterIdentity, cloudConfig cloud.Configuration) (azcore.TokenCredential, error) {
+	secret, err := getClientSecret(ctx, client, azureClusterIdentity)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return newCredentialWithSecret(ctx, azureClusterIdentity, secret, cloudConfig)
+}
+
+// newCredentialWithSecret creates the credential for the AzureClusterIdentity in the same way as
+// CAPZ does it for the identity type. The AAD authority is taken from the cloud configuration.
+// Credentials that watch their credentials file stop watching it when the context is done.
+func newCredentialWithSecret(ctx context.Context, azureClusterIdentity *capz.AzureClusterIdentity, secret *corev1.Secret, cloudConfig cloud.Configuration) (azcore.TokenCredential, error) {
+	var cred azcore.TokenCredential
+	var err error
+	clientOptions := azcore.ClientOptions{
+		Cloud: cloudConfig,
+	}
+
+	switch azureClusterIdentity.Spec.Type {
+	case capz.UserAssignedMSI:
+		cred, err = azidentity.NewManagedIdentityCredential(&azidentity.ManagedIdentityCredentialOptions{
+			ClientOptions: clientOptions,
+			ID:            azidentity.ClientID(azureClusterIdentity.Spec.ClientID),
+		})
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	case capz.ServicePrincipal, capz.ManualServicePrincipal:
+		cred, err = azidentity.NewClientSecretCredential(
+			azureClusterIdentity.Spec.TenantID,
+			azureClusterIdentity.Spec.ClientID,
+			string(secret.Data[clientSecretKeyName]),
+			&azidentity.ClientSecretCredentialOptions{
+				ClientOptions: clientOptions,
+			})
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	case capz.ServicePrincipalCertificate:
+		// The certificate is either read from CertPath, or from the client secret key of the
+		// Secret, and it can be PEM or PFX encoded.
+		var certificate []byte
+		if azureClusterIdentity.Spec.CertPath != "" {
+			certificate, err = os.ReadFile(azureClusterIdentity.Spec.CertPath)
+			if err != nil {
+				return nil, microerror.Mask(err)
+			}
+		} else {
+			certificate = secret.Data[clientSecretKeyName]
+		}
+
+		certs, key, err := azidentity.ParseCertificates(certificate, nil)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+		cred, err = azidentity.NewClientCertificateCredential(
+			azureClusterIdentity.Spec.TenantID,
+			azureClusterIdentity.Spec.ClientID,
+			certs,
+			key,
+			&azidentity.ClientCertificateCredentialOptions{
+				ClientOptions: clientOptions,
+			})
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	case capz.WorkloadIdentity:
+		cred, err = azidentity.NewWorkloadIdentityCredential(&azidentity.WorkloadIdentityCredentialOptions{
+			ClientOptions: clientOptions,
+			ClientID:      azureClusterIdentity.Spec.ClientID,
+			TenantID:      azureClusterIdentity.Spec.TenantID,
+		})
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	case capz.UserAssignedIdentityCredential:
+		// The credential reloads the credentials file when it changes, until the context is done.
+		cred, err = dataplane.NewUserAssignedIdentityCredential(
+			ctx,
+			azureClusterIdentity.Spec.UserAssignedIdentityCredentialsPath,
+			dataplane.WithClientOpts(azcore.ClientOptions{
+				Cloud: parseCloudType(azureClusterIdentity.Spec.UserAssignedIdentityCredentialsCloudType),
+			}))
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	default:
+		return nil, microerror.Maskf(errors.UnsupportedIdentityTypeError,
+			"AzureClusterIdentity %s/%s has unsupported type %q",
+			azureClusterIdentity.Namespace,
+			azureClusterIdentity.Name,
+			azureClusterIdentity.Spec.Type)
+	}
+
+	return cred, nil
+}
+
+// parseCloudType returns the cloud configuration for the UserAssignedIdentityCredentialsCloudType
+// of the AzureClusterIdentity, defaulting to the public cloud like CAPZ does.
+func parseCloudType(cloudType string) cloud.Configuration {
+	switch strings.ToUpper(cloudType) {
+	case "CHINA":
+		return cloud.AzureChina
+	case "USGOVERNMENT":
+		return cloud.AzureGovernment
+	default:
+		return cloud.AzurePublic
+	}
+}
@@ -0,0 +1,228 @@
+package azure
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/msi/armmsi"
+	"github.com/giantswarm/microerror"
+	capz "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/errors"
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/metrics"
+)
+
+type FederatedIdentityCredentialsClientCreator func(context.Context, client.Client, *capz.AzureCluster) (FederatedIdentityCredentialsClient, error)
+
+// FederatedIdentityCredentialsClient manages the federated identity credentials of the
+// user-assigned identities in the subscription of an AzureCluster. The identities are found by
+// their client ID, as that is all the AzureClusterIdentity knows about them.
+type FederatedIdentityCredentialsClient interface {
+	// CreateOrUpdate creates the federated identity credential with the name on the user-assigned
+	// identity with the client ID, or updates it when its issuer, subject or audiences differ.
+	CreateOrUpdate(ctx context.Context, identityClientID string, name string, properties armmsi.FederatedIdentityCredentialProperties) error
+	// Delete deletes the federated identity credential with the name from the user-assigned
+	// identity with the client ID. It succeeds when the credential does not exist.
+	Delete(ctx context.Context, identityClientID string, name string) error
+}
+
+// NewFederatedIdentityCredentialsClient creates a new federated identity credentials client with a
+// new credential for the AzureClusterIdentity of the AzureCluster. Use
+// ClientCache.NewFederatedIdentityCredentialsClient to reuse the credentials and their tokens
+// across reconciliations.
+func NewFederatedIdentityCredentialsClient(ctx context.Context, client client.Client, azureCluster *capz.AzureCluster) (FederatedIdentityCredentialsClient, error) {
+	azureClusterIdentity, err := getAzureClusterIdentity(ctx, client, azureCluster)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	cloudConfig, err := cloudConfiguration(azureCluster.Spec.AzureEnvironment, "")
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	cred, err := newCredential(ctx, client, azureClusterIdentity, cloudConfig)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return newFederatedIdentityCredentialsClient(azureCluster.Spec.SubscriptionID, cred, azcore.ClientOptions{
+		Cloud: cloudConfig,
+	})
+}
+
+func newFederatedIdentityCredentialsClient(subscriptionID string, cred azcore.TokenCredential, clientOptions azcore.ClientOptions) (FederatedIdentityCredentialsClient, error) {
+	options := &arm.ClientOptions{
+		ClientOptions: clientOptions,
+	}
+	identitiesClient, err := armmsi.NewUserAssignedIdentitiesClient(subscriptionID, cred, options)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	credentialsClient, err := armmsi.NewFederatedIdentityCredentialsClient(subscriptionID, cred, options)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return &federatedIdentityCredentialsClient{
+		identitiesClient:  identitiesClient,
+		credentialsClient: credentialsClient,
+		identityIDs:       map[string]*arm.ResourceID{},
+	}, nil
+}
+
+// federatedIdentityCredentialsClient records the duration and the status code of every Azure API
+// request, like the instrumented private endpoints client. It caches the resource IDs of the
+// identities, as finding them lists all identities of the subscription.
+type federatedIdentityCredentialsClient struct {
+	identitiesClient  *armmsi.UserAssignedIdentitiesClient
+	credentialsClient *armmsi.FederatedIdentityCredentialsClient
+
+	mu          sync.Mutex
+	identityIDs map[string]*arm.ResourceID
+}
+
+func (c *federatedIdentityCredentialsClient) CreateOrUpdate(ctx context.Context, identityClientID string, name string, properties armmsi.FederatedIdentityCredentialProperties) error {
+	identityID, err := c.findIdentity(ctx, identityClientID)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	start := time.Now()
+	current, err := c.credentialsClient.Get(ctx, identityID.ResourceGroupName, identityID.Name, name, nil)
+	metrics.ObserveAzureAPIRequest("FederatedIdentityCredentials.Get", start, err)
+	if err == nil && equalFederatedIdentityCredentialProperties(current.Properties, &properties) {
+		return nil
+	} else if err != nil && !errors.IsAzureResourceNotFound(err) {
+		return microerror.Mask(err)
+	}
+
+	start = time.Now()
+	_, err = c.credentialsClient.CreateOrUpdate(ctx, identityID.ResourceGroupName, identityID.Name, name, armmsi.FederatedIdentityCredential{
+		Properties: &properties,
+	}, nil)
+	metrics.ObserveAzureAPIRequest("FederatedIdentityCredentials.CreateOrUpdate", start, err)
+	if errors.IsAzureResourceNotFound(err) {
+		// The identity has been deleted, so it is found again the next time.
+		c.forgetIdentity(identityClientID)
+		return microerror.Mask(err)
+	} else if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
+
+// equalFederatedIdentityCredentialProperties returns whether the credentials have the same issuer,
+// subject and audiences.
+func equalFederatedIdentityCredentialProperties(a, b *armmsi.FederatedIdentityCredentialProperties) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	value := func(s *string) string {
+		if s == nil {
+			return ""
+		}
+		return *s
+	}
+	audiences := func(properties *armmsi.FederatedIdentityCredentialProperties) []string {
+		var audiences []string
+		for _, audience := range properties.Audiences {
+			if audience != nil {
+				audiences = append(audiences, *audience)
+			}
+		}
+		slices.Sort(audiences)
+		return audiences
+	}
+
+	return value(a.Issuer) == value(b.Issuer) &&
+		value(a.Subject) == value(b.Subject) &&
+		slices.Equal(audiences(a), audiences(b))
+}
+
+func (c *federatedIdentityCredentialsClient) Delete(ctx context.Context, identityClientID string, name string) error {
+	identityID, err := c.findIdentity(ctx, identityClientID)
+	if errors.IsUserAssignedIdentityNotFound(err) {
+		// The credentials of deleted identities are deleted with them.
+		return nil
+	} else if err != nil {
+		return microerror.Mask(err)
+	}
+
+	start := time.Now()
+	_, err = c.credentialsClient.Delete(ctx, identityID.ResourceGroupName, identityID.Name, name, nil)
+	metrics.ObserveAzureAPIRequest("FederatedIdentityCredentials.Delete", start, err)
+	if err != nil && !errors.IsAzureResourceNotFound(err) {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
+
+// findIdentity returns the resource ID of the user-assigned identity with the client ID in the
+// subscription. It is only looked up the first time, as the client ID of an identity never
+// changes.
+func (c *federatedIdentityCredentialsClient) findIdentity(ctx context.Context, clientID string) (*arm.ResourceID, error) {
+	key := strings.ToLower(clientID)
+	c.mu.Lock()
+	identityID, ok := c.identityIDs[key]
+	c.mu.Unlock()
+	if ok {
+		return identityID, nil
+	}
+
+	identityID, err := c.listIdentity(ctx, clientID)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	c.mu.Lock()
+	c.identityIDs[key] = identityID
+	c.mu.Unlock()
+
+	return identityID, nil
+}
+
+func (c *federatedIdentityCredentialsClient) forgetIdentity(clientID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.identityIDs, strings.ToLower(clientID))
+}
+
+// listIdentity lists the user-assigned identities in the subscription to find the one with the
+// client ID.
+func (c *federatedIdentityCredentialsClient) listIdentity(ctx context.Context, clientID string) (*arm.ResourceID, error) {
+	pager := c.identitiesClient.NewListBySubscriptionPager(nil)
+	for pager.More() {
+		start := time.Now()
+		page, err := pager.NextPage(ctx)
+		metrics.ObserveAzureAPIRequest("UserAssignedIdentities.ListBySubscription", start, err)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		for _, identity := range page.Value {
+			if identity.ID == nil || identity.Properties == nil || identity.Properties.ClientID == nil {
+				continue
+			}
+			if strings.EqualFold(*identity.Properties.ClientID, clientID) {
+				identityID, err := arm.ParseResourceID(*identity.ID)
+				if err != nil {
+					return nil, microerror.Mask(err)
+				}
+				return identityID, nil
+			}
+		}
+	}
+
+	return nil, microerror.Maskf(errors.UserAssignedIdentityNotFoundError, "user-assigned identity with client ID %s not found", clientID)
+}
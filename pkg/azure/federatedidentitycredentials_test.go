@@ -0,0 +1,158 @@
+package azure_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/msi/armmsi"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/runtime"
+	capz "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/azure"
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/errors"
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/testhelpers"
+)
+
+var _ = Describe("FederatedIdentityCredentialsClient", func() {
+	const identityID = "/subscriptions/1234/resourceGroups/identities/providers/Microsoft.ManagedIdentity/userAssignedIdentities/crossplane"
+
+	properties := armmsi.FederatedIdentityCredentialProperties{
+		Audiences: []*string{to.Ptr("api://AzureADTokenExchange")},
+		Issuer:    to.Ptr("https://oidc.example.com"),
+		Subject:   to.Ptr("system:serviceaccount:crossplane-system:provider-azure"),
+	}
+
+	var clientCache *azure.ClientCache
+	var azureCluster *capz.AzureCluster
+	var k8sClient client.Client
+	var credentialsClient azure.FederatedIdentityCredentialsClient
+	var requests []string
+	// existingCredential is the credential in Azure, or nil when it does not exist.
+	var existingCredential *armmsi.FederatedIdentityCredential
+
+	BeforeEach(func(ctx context.Context) {
+		requests = nil
+		existingCredential = nil
+		armServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests = append(requests, r.Method+" "+r.URL.Path)
+			switch {
+			case r.Method == http.MethodGet && strings.Contains(r.URL.Path, "/federatedIdentityCredentials/"):
+				if existingCredential == nil {
+					w.WriteHeader(http.StatusNotFound)
+					_, _ = w.Write([]byte(`{"error":{"code":"NotFound"}}`))
+					return
+				}
+				Expect(json.NewEncoder(w).Encode(existingCredential)).To(Succeed())
+			case r.Method == http.MethodGet:
+				Expect(json.NewEncoder(w).Encode(armmsi.UserAssignedIdentitiesListResult{
+					Value: []*armmsi.Identity{{
+						ID: to.Ptr(identityID),
+						Properties: &armmsi.UserAssignedIdentityProperties{
+							ClientID: to.Ptr("client"),
+						},
+					}},
+				})).To(Succeed())
+			case r.Method == http.MethodPut:
+				w.WriteHeader(http.StatusCreated)
+				_, _ = w.Write([]byte("{}"))
+			default:
+				w.WriteHeader(http.StatusOK)
+			}
+		}))
+		DeferCleanup(armServer.Close)
+
+		scheme := runtime.NewScheme()
+		Expect(capz.AddToScheme(scheme)).To(Succeed())
+		clientCache = azure.NewClientCache(azure.ClientCacheOptions{
+			FallbackCredential:      newFakeCredential,
+			ResourceManagerEndpoint: armServer.URL,
+			Transport:               armServer.Client(),
+		})
+		azureCluster = testhelpers.NewAzureClusterBuilder("org-giantswarm", "awesome-wc").
+			WithSubscriptionID("1234").
+			Build()
+
+		k8sClient = fake.NewClientBuilder().WithScheme(scheme).Build()
+
+		var err error
+		credentialsClient, err = clientCache.NewFederatedIdentityCredentialsClient(ctx, k8sClient, azureCluster)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("creates the credential on the identity with the client ID", func(ctx context.Context) {
+		err := credentialsClient.CreateOrUpdate(ctx, "client", "crossplane", properties)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(requests).To(Equal([]string{
+			"GET /subscriptions/1234/providers/Microsoft.ManagedIdentity/userAssignedIdentities",
+			"GET " + identityID + "/federatedIdentityCredentials/crossplane",
+			"PUT " + identityID + "/federatedIdentityCredentials/crossplane",
+		}))
+	})
+
+	It("does not update the credential when it is up to date", func(ctx context.Context) {
+		existingCredential = &armmsi.FederatedIdentityCredential{
+			Properties: &armmsi.FederatedIdentityCredentialProperties{
+				Audiences: properties.Audiences,
+				Issuer:    properties.Issuer,
+				Subject:   properties.Subject,
+			},
+		}
+
+		err := credentialsClient.CreateOrUpdate(ctx, "client", "crossplane", properties)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(requests).NotTo(ContainElement(HavePrefix("PUT")))
+	})
+
+	It("updates the credential when its subject differs", func(ctx context.Context) {
+		existingCredential = &armmsi.FederatedIdentityCredential{
+			Properties: &armmsi.FederatedIdentityCredentialProperties{
+				Audiences: properties.Audiences,
+				Issuer:    properties.Issuer,
+				Subject:   to.Ptr("system:serviceaccount:crossplane-system:other"),
+			},
+		}
+
+		err := credentialsClient.CreateOrUpdate(ctx, "client", "crossplane", properties)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(requests).To(ContainElement("PUT " + identityID + "/federatedIdentityCredentials/crossplane"))
+	})
+
+	It("finds the identity with the client ID only once", func(ctx context.Context) {
+		Expect(credentialsClient.CreateOrUpdate(ctx, "client", "crossplane", properties)).To(Succeed())
+
+		credentialsClient, err := clientCache.NewFederatedIdentityCredentialsClient(ctx, k8sClient, azureCluster)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(credentialsClient.CreateOrUpdate(ctx, "client", "crossplane", properties)).To(Succeed())
+
+		var listRequests []string
+		for _, request := range requests {
+			if strings.HasSuffix(request, "/userAssignedIdentities") {
+				listRequests = append(listRequests, request)
+			}
+		}
+		Expect(listRequests).To(HaveLen(1))
+	})
+
+	It("returns UserAssignedIdentityNotFoundError when no identity has the client ID", func(ctx context.Context) {
+		err := credentialsClient.CreateOrUpdate(ctx, "unknown", "crossplane", armmsi.FederatedIdentityCredentialProperties{})
+		Expect(errors.IsUserAssignedIdentityNotFound(err)).To(BeTrue())
+	})
+
+	It("deletes the credential from the identity with the client ID", func(ctx context.Context) {
+		Expect(credentialsClient.Delete(ctx, "client", "crossplane")).To(Succeed())
+		Expect(requests).To(ContainElement("DELETE " + identityID + "/federatedIdentityCredentials/crossplane"))
+	})
+
+	It("succeeds deleting the credential of an identity that does not exist", func(ctx context.Context) {
+		Expect(credentialsClient.Delete(ctx, "unknown", "crossplane")).To(Succeed())
+		Expect(requests).NotTo(ContainElement(HavePrefix("DELETE")))
+	})
+})
@@ -0,0 +1,46 @@
+package azure
+
+import (
+	"context"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v9"
+
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/metrics"
+)
+
+// instrumentedPrivateEndpointsClient records the duration and the status code of every Azure API
+// request that is made with the wrapped client.
+type instrumentedPrivateEndpointsClient struct {
+	client PrivateEndpointsClient
+}
+
+func newInstrumentedPrivateEndpointsClient(client PrivateEndpointsClient) PrivateEndpointsClient {
+	return &instrumentedPrivateEndpointsClient{
+		client: client,
+	}
+}
+
+func (c *instrumentedPrivateEndpointsClient) Get(ctx context.Context, resourceGroupName string, privateEndpointName string, options *armnetwork.PrivateEndpointsClientGetOptions) (armnetwork.PrivateEndpointsClientGetResponse, error) {
+	start := time.Now()
+	response, err := c.client.Get(ctx, resourceGroupName, privateEndpointName, options)
+	metrics.ObserveAzureAPIRequest("PrivateEndpoints.Get", start, err)
+	return response, err
+}
+
+// NewListPager returns a pager that records every page request like a single Azure API request.
+func (c *instrumentedPrivateEndpointsClient) NewListPager(resourceGroupName string, options *armnetwork.PrivateEndpointsClientListOptions) *runtime.Pager[armnetwork.PrivateEndpointsClientListResponse] {
+	pager := c.client.NewListPager(resourceGroupName, options)
+	return runtime.NewPager(runtime.PagingHandler[armnetwork.PrivateEndpointsClientListResponse]{
+		More: func(armnetwork.PrivateEndpointsClientListResponse) bool {
+			return pager.More()
+		},
+		Fetcher: func(ctx context.Context, _ *armnetwork.PrivateEndpointsClientListResponse) (armnetwork.PrivateEndpointsClientListResponse, error) {
+			start := time.Now()
+			response, err := pager.NextPage(ctx)
+			metrics.ObserveAzureAPIRequest("PrivateEndpoints.List", start, err)
+			return response, err
+		},
+	})
+}
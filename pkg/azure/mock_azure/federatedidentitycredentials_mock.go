@@ -0,0 +1,70 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ../federatedidentitycredentials.go
+//
+// Generated by this command:
+//
+//	mockgen -destination federatedidentitycredentials_mock.go -package mock_azure -source ../federatedidentitycredentials.go FederatedIdentityCredentialsClient
+//
+
+// Package mock_azure is a generated GoMock package.
+package mock_azure
+
+import (
+	context "context"
+	reflect "reflect"
+
+	armmsi "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/msi/armmsi"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockFederatedIdentityCredentialsClient is a mock of FederatedIdentityCredentialsClient interface.
+type MockFederatedIdentityCredentialsClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockFederatedIdentityCredentialsClientMockRecorder
+	isgomock struct{}
+}
+
+// MockFederatedIdentityCredentialsClientMockRecorder is the mock recorder for MockFederatedIdentityCredentialsClient.
+type MockFederatedIdentityCredentialsClientMockRecorder struct {
+	mock *MockFederatedIdentityCredentialsClient
+}
+
+// NewMockFederatedIdentityCredentialsClient creates a new mock instance.
+func NewMockFederatedIdentityCredentialsClient(ctrl *gomock.Controller) *MockFederatedIdentityCredentialsClient {
+	mock := &MockFederatedIdentityCredentialsClient{ctrl: ctrl}
+	mock.recorder = &MockFederatedIdentityCredentialsClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFederatedIdentityCredentialsClient) EXPECT() *MockFederatedIdentityCredentialsClientMockRecorder {
+	return m.recorder
+}
+
+// CreateOrUpdate mocks base method.
+func (m *MockFederatedIdentityCredentialsClient) CreateOrUpdate(ctx context.Context, identityClientID, name string, properties armmsi.FederatedIdentityCredentialProperties) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOrUpdate", ctx, identityClientID, name, properties)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateOrUpdate indicates an expected call of CreateOrUpdate.
+func (mr *MockFederatedIdentityCredentialsClientMockRecorder) CreateOrUpdate(ctx, identityClientID, name, properties any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOrUpdate", reflect.TypeOf((*MockFederatedIdentityCredentialsClient)(nil).CreateOrUpdate), ctx, identityClientID, name, properties)
+}
+
+// Delete mocks base method.
+func (m *MockFederatedIdentityCredentialsClient) Delete(ctx context.Context, identityClientID, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, identityClientID, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockFederatedIdentityCredentialsClientMockRecorder) Delete(ctx, identityClientID, name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockFederatedIdentityCredentialsClient)(nil).Delete), ctx, identityClientID, name)
+}
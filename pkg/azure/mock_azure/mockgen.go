@@ -1,5 +1,6 @@
 package mock_azure
 
-// Run go generate to regenerate this mock.
+// Run go generate to regenerate these mocks.
 //
 //go:generate ../../../bin/mockgen -destination privateendpoints_mock.go -package mock_azure -source ../privateendpoints.go PrivateEndpointsClient -imports armnetwork=github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v2
+//go:generate ../../../bin/mockgen -destination federatedidentitycredentials_mock.go -package mock_azure -source ../federatedidentitycredentials.go FederatedIdentityCredentialsClient
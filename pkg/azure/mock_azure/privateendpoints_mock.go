@@ -13,6 +13,7 @@ import (
 	context "context"
 	reflect "reflect"
 
+	runtime "github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
 	armnetwork "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v9"
 	gomock "go.uber.org/mock/gomock"
 )
@@ -55,3 +56,17 @@ func (mr *MockPrivateEndpointsClientMockRecorder) Get(ctx, resourceGroupName, pr
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockPrivateEndpointsClient)(nil).Get), ctx, resourceGroupName, privateEndpointName, options)
 }
+
+// NewListPager mocks base method.
+func (m *MockPrivateEndpointsClient) NewListPager(resourceGroupName string, options *armnetwork.PrivateEndpointsClientListOptions) *runtime.Pager[armnetwork.PrivateEndpointsClientListResponse] {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NewListPager", resourceGroupName, options)
+	ret0, _ := ret[0].(*runtime.Pager[armnetwork.PrivateEndpointsClientListResponse])
+	return ret0
+}
+
+// NewListPager indicates an expected call of NewListPager.
+func (mr *MockPrivateEndpointsClientMockRecorder) NewListPager(resourceGroupName, options any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewListPager", reflect.TypeOf((*MockPrivateEndpointsClient)(nil).NewListPager), resourceGroupName, options)
+}
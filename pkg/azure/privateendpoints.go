@@ -4,11 +4,10 @@ import (
 	"context"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v9"
 	"github.com/giantswarm/microerror"
-	corev1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/types"
 	capz "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -17,67 +16,40 @@ type PrivateEndpointsClientCreator func(context.Context, client.Client, *capz.Az
 
 type PrivateEndpointsClient interface {
 	Get(ctx context.Context, resourceGroupName string, privateEndpointName string, options *armnetwork.PrivateEndpointsClientGetOptions) (armnetwork.PrivateEndpointsClientGetResponse, error)
+	NewListPager(resourceGroupName string, options *armnetwork.PrivateEndpointsClientListOptions) *runtime.Pager[armnetwork.PrivateEndpointsClientListResponse]
 }
 
-const (
-	clientSecretKeyName = "clientSecret"
-)
-
+// NewPrivateEndpointClient creates a new private endpoints client with a new credential for the
+// AzureClusterIdentity of the AzureCluster. Use ClientCache.NewPrivateEndpointClient to reuse the
+// credentials and their tokens across reconciliations.
 func NewPrivateEndpointClient(ctx context.Context, client client.Client, azureCluster *capz.AzureCluster) (PrivateEndpointsClient, error) {
-	var cred azcore.TokenCredential
-	var err error
-
-	azureClusterIdentity := &capz.AzureClusterIdentity{}
-	name := types.NamespacedName{
-		Namespace: azureCluster.Spec.IdentityRef.Namespace,
-		Name:      azureCluster.Spec.IdentityRef.Name,
-	}
-	err = client.Get(ctx, name, azureClusterIdentity)
+	azureClusterIdentity, err := getAzureClusterIdentity(ctx, client, azureCluster)
 	if err != nil {
 		return nil, microerror.Mask(err)
 	}
 
-	switch azureClusterIdentity.Spec.Type {
-	case capz.UserAssignedMSI:
-		cred, err = azidentity.NewManagedIdentityCredential(&azidentity.ManagedIdentityCredentialOptions{
-			ID: azidentity.ClientID(azureClusterIdentity.Spec.ClientID),
-		})
-		if err != nil {
-			return nil, microerror.Mask(err)
-		}
-	case capz.ManualServicePrincipal:
-		clientSecretName := types.NamespacedName{
-			Namespace: azureClusterIdentity.Spec.ClientSecret.Namespace,
-			Name:      azureClusterIdentity.Spec.ClientSecret.Name,
-		}
-		secret := &corev1.Secret{}
-		err = client.Get(ctx, clientSecretName, secret)
-		if err != nil {
-			return nil, microerror.Mask(err)
-		}
+	cloudConfig, err := cloudConfiguration(azureCluster.Spec.AzureEnvironment, "")
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
 
-		cred, err = azidentity.NewClientSecretCredential(
-			azureClusterIdentity.Spec.TenantID,
-			azureClusterIdentity.Spec.ClientID,
-			string(secret.Data[clientSecretKeyName]),
-			nil)
-		if err != nil {
-			return nil, microerror.Mask(err)
-		}
-	case capz.WorkloadIdentity:
-		cred, err = azidentity.NewWorkloadIdentityCredential(&azidentity.WorkloadIdentityCredentialOptions{
-			ClientID: azureClusterIdentity.Spec.ClientID,
-			TenantID: azureClusterIdentity.Spec.TenantID,
-		})
-		if err != nil {
-			return nil, microerror.Mask(err)
-		}
+	cred, err := newCredential(ctx, client, azureClusterIdentity, cloudConfig)
+	if err != nil {
+		return nil, microerror.Mask(err)
 	}
 
-	privateEndpointsClient, err := armnetwork.NewPrivateEndpointsClient(azureCluster.Spec.SubscriptionID, cred, nil)
+	return newPrivateEndpointsClient(azureCluster.Spec.SubscriptionID, cred, azcore.ClientOptions{
+		Cloud: cloudConfig,
+	})
+}
+
+func newPrivateEndpointsClient(subscriptionID string, cred azcore.TokenCredential, clientOptions azcore.ClientOptions) (PrivateEndpointsClient, error) {
+	privateEndpointsClient, err := armnetwork.NewPrivateEndpointsClient(subscriptionID, cred, &arm.ClientOptions{
+		ClientOptions: clientOptions,
+	})
 	if err != nil {
 		return nil, microerror.Mask(err)
 	}
 
-	return privateEndpointsClient, nil
+	return newInstrumentedPrivateEndpointsClient(privateEndpointsClient), nil
 }
@@ -0,0 +1,235 @@
+package azure_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	capz "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/azure"
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/errors"
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/testhelpers"
+)
+
+var _ = Describe("NewPrivateEndpointClient", func() {
+	var k8sClient client.Client
+	var identityType capz.IdentityType
+	var clientSecret []byte
+	var allowedNamespaces *capz.AllowedNamespaces
+	var azureCluster *capz.AzureCluster
+
+	BeforeEach(func() {
+		identityType = capz.ManualServicePrincipal
+		clientSecret = []byte("super-secret")
+		allowedNamespaces = &capz.AllowedNamespaces{}
+	})
+
+	JustBeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(capz.AddToScheme(scheme)).To(Succeed())
+
+		namespace := &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "org-awesome",
+				Labels: map[string]string{
+					"giantswarm.io/organization": "awesome",
+				},
+			},
+		}
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "giantswarm",
+				Name:      "cluster-identity-secret",
+			},
+			Data: map[string][]byte{
+				"clientSecret": clientSecret,
+			},
+		}
+		azureClusterIdentity := testhelpers.NewAzureClusterIdentityBuilder("org-giantswarm", "cluster-identity").
+			WithType(identityType).
+			WithTenantID("tenant").
+			WithClientID("client").
+			WithClientSecret(secret.Namespace, secret.Name).
+			WithAllowedNamespaces(allowedNamespaces).
+			Build()
+		azureCluster = testhelpers.NewAzureClusterBuilder("org-awesome", "awesome-wc").
+			WithSubscriptionID("1234").
+			WithIdentity(azureClusterIdentity).
+			Build()
+
+		k8sClient = fake.NewClientBuilder().
+			WithScheme(scheme).
+			WithObjects(namespace, secret, azureClusterIdentity, azureCluster).
+			Build()
+	})
+
+	When("AzureClusterIdentity allows all namespaces", func() {
+		It("creates the client", func(ctx context.Context) {
+			_, err := azure.NewPrivateEndpointClient(ctx, k8sClient, azureCluster)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	When("AzureClusterIdentity allowed namespaces are not set", func() {
+		BeforeEach(func() {
+			allowedNamespaces = nil
+		})
+
+		It("returns AzureClusterIdentityNotAllowedError", func(ctx context.Context) {
+			_, err := azure.NewPrivateEndpointClient(ctx, k8sClient, azureCluster)
+			Expect(errors.IsAzureClusterIdentityNotAllowed(err)).To(BeTrue())
+		})
+	})
+
+	When("AzureCluster namespace is in the allowed namespace list", func() {
+		BeforeEach(func() {
+			allowedNamespaces = &capz.AllowedNamespaces{
+				NamespaceList: []string{"org-giantswarm", "org-awesome"},
+			}
+		})
+
+		It("creates the client", func(ctx context.Context) {
+			_, err := azure.NewPrivateEndpointClient(ctx, k8sClient, azureCluster)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	When("AzureCluster namespace is not in the allowed namespace list", func() {
+		BeforeEach(func() {
+			allowedNamespaces = &capz.AllowedNamespaces{
+				NamespaceList: []string{"org-giantswarm"},
+			}
+		})
+
+		It("returns AzureClusterIdentityNotAllowedError", func(ctx context.Context) {
+			_, err := azure.NewPrivateEndpointClient(ctx, k8sClient, azureCluster)
+			Expect(errors.IsAzureClusterIdentityNotAllowed(err)).To(BeTrue())
+		})
+	})
+
+	When("AzureCluster namespace matches the allowed namespaces selector", func() {
+		BeforeEach(func() {
+			allowedNamespaces = &capz.AllowedNamespaces{
+				Selector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{
+						"giantswarm.io/organization": "awesome",
+					},
+				},
+			}
+		})
+
+		It("creates the client", func(ctx context.Context) {
+			_, err := azure.NewPrivateEndpointClient(ctx, k8sClient, azureCluster)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	When("AzureCluster namespace does not match the allowed namespaces selector", func() {
+		BeforeEach(func() {
+			allowedNamespaces = &capz.AllowedNamespaces{
+				Selector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{
+						"giantswarm.io/organization": "giantswarm",
+					},
+				},
+			}
+		})
+
+		It("returns AzureClusterIdentityNotAllowedError", func(ctx context.Context) {
+			_, err := azure.NewPrivateEndpointClient(ctx, k8sClient, azureCluster)
+			Expect(errors.IsAzureClusterIdentityNotAllowed(err)).To(BeTrue())
+		})
+	})
+
+	When("AzureClusterIdentity allowed namespaces selector is empty", func() {
+		BeforeEach(func() {
+			allowedNamespaces = &capz.AllowedNamespaces{
+				Selector: &metav1.LabelSelector{},
+			}
+		})
+
+		It("returns AzureClusterIdentityNotAllowedError", func(ctx context.Context) {
+			_, err := azure.NewPrivateEndpointClient(ctx, k8sClient, azureCluster)
+			Expect(errors.IsAzureClusterIdentityNotAllowed(err)).To(BeTrue())
+		})
+	})
+
+	When("AzureClusterIdentity type is ServicePrincipal", func() {
+		BeforeEach(func() {
+			identityType = capz.ServicePrincipal
+		})
+
+		It("creates the client", func(ctx context.Context) {
+			_, err := azure.NewPrivateEndpointClient(ctx, k8sClient, azureCluster)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	When("AzureClusterIdentity type is ServicePrincipalCertificate", func() {
+		BeforeEach(func() {
+			identityType = capz.ServicePrincipalCertificate
+			clientSecret = newPEMCertificate()
+		})
+
+		It("creates the client with the certificate from the client secret", func(ctx context.Context) {
+			_, err := azure.NewPrivateEndpointClient(ctx, k8sClient, azureCluster)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	When("AzureClusterIdentity type is ServicePrincipalCertificate with invalid certificate", func() {
+		BeforeEach(func() {
+			identityType = capz.ServicePrincipalCertificate
+		})
+
+		It("returns an error", func(ctx context.Context) {
+			_, err := azure.NewPrivateEndpointClient(ctx, k8sClient, azureCluster)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	When("AzureClusterIdentity type is not supported", func() {
+		BeforeEach(func() {
+			identityType = "UnknownIdentity"
+		})
+
+		It("returns UnsupportedIdentityTypeError", func(ctx context.Context) {
+			_, err := azure.NewPrivateEndpointClient(ctx, k8sClient, azureCluster)
+			Expect(errors.IsUnsupportedIdentityType(err)).To(BeTrue())
+		})
+	})
+})
+
+// newPEMCertificate returns a PEM encoded self-signed certificate with its private key.
+func newPEMCertificate() []byte {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	Expect(err).NotTo(HaveOccurred())
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "azure-private-endpoint-operator"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	certificate, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	Expect(err).NotTo(HaveOccurred())
+
+	result := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificate})
+	result = append(result, pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})...)
+	return result
+}
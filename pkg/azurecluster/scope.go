@@ -38,6 +38,11 @@ func (s *BaseScope) GetClusterName() types.NamespacedName {
 	}
 }
 
+// GetAzureCluster returns the AzureCluster that is wrapped by the scope, e.g. for emitting events.
+func (s *BaseScope) GetAzureCluster() *capz.AzureCluster {
+	return s.azureCluster
+}
+
 func (s *BaseScope) GetSubscriptionID() string {
 	return s.azureCluster.Spec.SubscriptionID
 }
@@ -62,6 +67,10 @@ func (s *BaseScope) IsConditionTrue(conditionType capi.ConditionType) bool {
 	return v1beta1conditions.IsTrue(s.azureCluster, conditionType)
 }
 
+func (s *BaseScope) GetAnnotation(annotation string) string {
+	return s.azureCluster.GetAnnotations()[annotation]
+}
+
 func (s *BaseScope) SetAnnotation(annotation, value string) {
 	annotations := s.azureCluster.GetAnnotations()
 	if annotations == nil {
@@ -75,13 +84,10 @@ func (s *BaseScope) GetConditions() capi.Conditions {
 	return s.azureCluster.GetConditions()
 }
 
+// SetCondition sets the condition on the AzureCluster, replacing an existing condition of the same
+// type.
 func (s *BaseScope) SetCondition(condition capi.Condition) {
-	conditions := s.azureCluster.GetConditions()
-	if conditions == nil {
-		conditions = make(capi.Conditions, 0)
-	}
-	conditions = append(conditions, condition)
-	s.azureCluster.SetConditions(conditions)
+	v1beta1conditions.Set(s.azureCluster, &condition)
 }
 
 func (s *BaseScope) Close(ctx context.Context) error {
@@ -0,0 +1,25 @@
+package azurecluster
+
+import (
+	capz "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+
+	"github.com/giantswarm/microerror"
+
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/errors"
+)
+
+// ValidateLBType checks if the load balancer type is either Internal or Public. Any
+// other load balancer type (e.g. potentially added in the future) is considered an error here.
+func ValidateLBType(azureCluster capz.AzureCluster) error {
+	if azureCluster.Spec.NetworkSpec.APIServerLB.Type != capz.Internal &&
+		azureCluster.Spec.NetworkSpec.APIServerLB.Type != capz.Public {
+		return microerror.Maskf(
+			errors.UnknownLoadBalancerTypeError,
+			"expected that load balancer type is %s or %s, got %s in cluster %s",
+			capz.Internal,
+			capz.Public,
+			azureCluster.Spec.NetworkSpec.APIServerLB.Type,
+			azureCluster.Name)
+	}
+	return nil
+}
@@ -0,0 +1,276 @@
+package drift
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	capz "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	capi "sigs.k8s.io/cluster-api/api/core/v1beta1"
+	v1beta1conditions "sigs.k8s.io/cluster-api/util/deprecated/v1beta1/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/giantswarm/microerror"
+
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/azure"
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/azurecluster"
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/errors"
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/events"
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/metrics"
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/privateendpoints"
+)
+
+const (
+	// ConditionGSPrivateEndpointsInSync is set to False on the AzureCluster when the private
+	// endpoints in its spec differ from the private endpoints that exist in Azure in its resource
+	// group.
+	ConditionGSPrivateEndpointsInSync capi.ConditionType = "GSPrivateEndpointsInSync"
+
+	PrivateEndpointsDriftedReason = "PrivateEndpointsDrifted"
+
+	// DriftDetectedAtAnnotation is set on the AzureCluster when a private endpoint in its spec is
+	// missing in Azure and Options.TriggerReconcile is set. Changing the AzureCluster makes CAPZ
+	// reconcile it, and so create the missing private endpoint again.
+	DriftDetectedAtAnnotation = "azure-private-endpoint-operator.giantswarm.io/drift-detected-at"
+
+	// DriftMissingInAzure is a private endpoint in the AzureCluster spec that does not exist in
+	// Azure, although CAPZ reports the private endpoints as ready, e.g. because it was deleted out
+	// of band.
+	DriftMissingInAzure = "missing_in_azure"
+	// DriftUnmanagedInAzure is a private endpoint to a private link service that exists in Azure in
+	// the resource group of the AzureCluster, but is not in its spec, e.g. because it was created
+	// manually.
+	DriftUnmanagedInAzure = "unmanaged_in_azure"
+)
+
+type Options struct {
+	// Interval is the time between two drift detections.
+	Interval time.Duration
+	// TriggerReconcile makes the detector annotate AzureClusters with private endpoints that are
+	// missing in Azure, so that CAPZ reconciles them.
+	TriggerReconcile bool
+}
+
+// Detector periodically compares the private endpoints in the AzureCluster specs with the private
+// endpoints that exist in Azure in the resource groups of the clusters, and reports the
+// differences with the GSPrivateEndpointsInSync condition, events and metrics.
+//
+// Only private endpoints that connect to private link services are compared, as the operator does
+// not manage any other private endpoints.
+type Detector struct {
+	client                        client.Client
+	privateEndpointsClientCreator azure.PrivateEndpointsClientCreator
+	recorder                      *events.Recorder
+	interval                      time.Duration
+	triggerReconcile              bool
+}
+
+// Drift is a private endpoint whose AzureCluster spec differs from Azure.
+type Drift struct {
+	Type            string
+	PrivateEndpoint string
+}
+
+func NewDetector(client client.Client, privateEndpointsClientCreator azure.PrivateEndpointsClientCreator, recorder *events.Recorder, options Options) (*Detector, error) {
+	if client == nil {
+		return nil, microerror.Maskf(errors.InvalidConfigError, "client must be set")
+	}
+	if privateEndpointsClientCreator == nil {
+		return nil, microerror.Maskf(errors.InvalidConfigError, "privateEndpointsClientCreator must be set")
+	}
+	if recorder == nil {
+		return nil, microerror.Maskf(errors.InvalidConfigError, "recorder must be set")
+	}
+	if options.Interval <= 0 {
+		return nil, microerror.Maskf(errors.InvalidConfigError, "options.Interval must be positive")
+	}
+
+	return &Detector{
+		client:                        client,
+		privateEndpointsClientCreator: privateEndpointsClientCreator,
+		recorder:                      recorder,
+		interval:                      options.Interval,
+		triggerReconcile:              options.TriggerReconcile,
+	}, nil
+}
+
+// Start runs the drift detection every interval until the context is cancelled. It implements
+// manager.Runnable.
+func (d *Detector) Start(ctx context.Context) error {
+	logger := log.FromContext(ctx).WithName("drift-detector")
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		if err := d.Detect(log.IntoContext(ctx, logger)); err != nil {
+			logger.Error(err, "Failed to detect private endpoint drift")
+			metrics.RecordReconcileError(metrics.ControllerDriftDetector, err)
+		}
+	}, d.interval)
+
+	return nil
+}
+
+// NeedLeaderElection makes only the leader run the drift detection, as it changes AzureClusters.
+func (d *Detector) NeedLeaderElection() bool {
+	return true
+}
+
+// Detect detects the drift of all AzureClusters. A cluster whose drift can not be detected, e.g.
+// because its Azure credentials are not valid, is logged and skipped, so that the other clusters
+// are still checked.
+func (d *Detector) Detect(ctx context.Context) error {
+	logger := log.FromContext(ctx)
+
+	var azureClusters capz.AzureClusterList
+	if err := d.client.List(ctx, &azureClusters); err != nil {
+		return microerror.Mask(err)
+	}
+
+	metrics.ResetPrivateEndpointDrift()
+	for i := range azureClusters.Items {
+		azureCluster := &azureClusters.Items[i]
+		if !azureCluster.DeletionTimestamp.IsZero() {
+			continue
+		}
+
+		if err := d.detectCluster(ctx, azureCluster); err != nil {
+			logger.Error(err, "Failed to detect private endpoint drift", "namespace", azureCluster.Namespace, "name", azureCluster.Name)
+			metrics.RecordReconcileError(metrics.ControllerDriftDetector, err)
+		}
+	}
+
+	return nil
+}
+
+func (d *Detector) detectCluster(ctx context.Context, azureCluster *capz.AzureCluster) error {
+	// Clusters without subnets can not have private endpoints.
+	if len(azureCluster.Spec.NetworkSpec.Subnets) == 0 {
+		return nil
+	}
+
+	privateEndpointsClient, err := d.privateEndpointsClientCreator(ctx, d.client, azureCluster)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	privateEndpointsScope, err := privateendpoints.NewScope(ctx, azureCluster, d.client, privateEndpointsClient)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	drifts, err := FindDrifts(ctx, privateEndpointsScope)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return microerror.Mask(d.report(ctx, azureCluster, drifts))
+}
+
+// FindDrifts compares the private endpoints to private link services in the spec of the cluster
+// with the private endpoints that exist in Azure in the resource group of the cluster.
+//
+// Private endpoints that are missing in Azure are only reported when CAPZ reports the private
+// endpoints as ready, so that private endpoints that CAPZ is still creating are not reported.
+func FindDrifts(ctx context.Context, scope privateendpoints.Scope) ([]Drift, error) {
+	azurePrivateEndpoints, err := scope.ListAzurePrivateEndpoints(ctx)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	azurePrivateEndpointNames := map[string]bool{}
+	var drifts []Drift
+	for _, azurePrivateEndpoint := range azurePrivateEndpoints {
+		if azurePrivateEndpoint == nil || azurePrivateEndpoint.Name == nil {
+			continue
+		}
+		azurePrivateEndpointNames[*azurePrivateEndpoint.Name] = true
+
+		if !privateendpoints.ConnectsToPrivateLinkService(privateendpoints.ConnectedResourceIDs(azurePrivateEndpoint)) {
+			continue
+		}
+		if !scope.ContainsPrivateEndpointSpec(capz.PrivateEndpointSpec{Name: *azurePrivateEndpoint.Name}) {
+			drifts = append(drifts, Drift{Type: DriftUnmanagedInAzure, PrivateEndpoint: *azurePrivateEndpoint.Name})
+		}
+	}
+
+	if v1beta1conditions.IsTrue(scope.GetAzureCluster(), capz.PrivateEndpointsReadyCondition) {
+		for _, spec := range scope.GetPrivateEndpoints() {
+			if !privateendpoints.ConnectsToPrivateLinkService(privateendpoints.SpecConnectedResourceIDs(spec)) {
+				continue
+			}
+			if !azurePrivateEndpointNames[spec.Name] {
+				drifts = append(drifts, Drift{Type: DriftMissingInAzure, PrivateEndpoint: spec.Name})
+			}
+		}
+	}
+
+	slices.SortFunc(drifts, func(a, b Drift) int {
+		return strings.Compare(a.Type+"/"+a.PrivateEndpoint, b.Type+"/"+b.PrivateEndpoint)
+	})
+
+	return drifts, nil
+}
+
+// report sets the metrics, the condition and the events of the cluster, and annotates it when
+// CAPZ should be triggered to create missing private endpoints.
+func (d *Detector) report(ctx context.Context, azureCluster *capz.AzureCluster, drifts []Drift) error {
+	clusterName := client.ObjectKeyFromObject(azureCluster)
+	counts := map[string]int{DriftMissingInAzure: 0, DriftUnmanagedInAzure: 0}
+	for _, drift := range drifts {
+		counts[drift.Type]++
+	}
+	for driftType, count := range counts {
+		metrics.SetPrivateEndpointDrift(clusterName, driftType, count)
+	}
+
+	scope, err := azurecluster.NewBaseScope(azureCluster, d.client)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	if len(drifts) == 0 {
+		// Only clear the condition when it was set before, so that it is not added to every cluster.
+		if v1beta1conditions.Has(scope, ConditionGSPrivateEndpointsInSync) &&
+			!v1beta1conditions.IsTrue(scope, ConditionGSPrivateEndpointsInSync) {
+			scope.SetCondition(capi.Condition{
+				Type:               ConditionGSPrivateEndpointsInSync,
+				Status:             corev1.ConditionTrue,
+				LastTransitionTime: metav1.Now(),
+			})
+			return microerror.Mask(scope.Close(ctx))
+		}
+		return nil
+	}
+
+	var messages []string
+	for _, drift := range drifts {
+		var message string
+		switch drift.Type {
+		case DriftMissingInAzure:
+			message = fmt.Sprintf("private endpoint %s is in the AzureCluster spec, but does not exist in resource group %s", drift.PrivateEndpoint, azureCluster.Spec.ResourceGroup)
+		case DriftUnmanagedInAzure:
+			message = fmt.Sprintf("private endpoint %s exists in resource group %s, but is not in the AzureCluster spec", drift.PrivateEndpoint, azureCluster.Spec.ResourceGroup)
+		}
+		messages = append(messages, message)
+		d.recorder.Warningf(azureCluster, events.ReasonPrivateEndpointDrift, events.ActionDetectDrift, "Private endpoint drift: %s", message)
+	}
+
+	scope.SetCondition(capi.Condition{
+		Type:               ConditionGSPrivateEndpointsInSync,
+		Status:             corev1.ConditionFalse,
+		Severity:           capi.ConditionSeverityWarning,
+		Reason:             PrivateEndpointsDriftedReason,
+		Message:            strings.Join(messages, "; "),
+		LastTransitionTime: metav1.Now(),
+	})
+
+	if d.triggerReconcile && counts[DriftMissingInAzure] > 0 {
+		log.FromContext(ctx).Info("Triggering CAPZ reconciliation of AzureCluster with missing private endpoints",
+			"namespace", azureCluster.Namespace, "name", azureCluster.Name)
+		scope.SetAnnotation(DriftDetectedAtAnnotation, time.Now().UTC().Format(time.RFC3339))
+	}
+
+	return microerror.Mask(scope.Close(ctx))
+}
@@ -0,0 +1,180 @@
+package drift_test
+
+import (
+	"context"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v9"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/mock/gomock"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8sevents "k8s.io/client-go/tools/events"
+	capz "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	capi "sigs.k8s.io/cluster-api/api/core/v1beta1"
+	v1beta1conditions "sigs.k8s.io/cluster-api/util/deprecated/v1beta1/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/azure"
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/azure/mock_azure"
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/drift"
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/errors"
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/events"
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/testhelpers"
+)
+
+const (
+	managedPrivateEndpointName   = "awesome-wc-privatelink-privateendpoint"
+	unmanagedPrivateEndpointName = "manual-privateendpoint"
+	storagePrivateEndpointName   = "storage-privateendpoint"
+	wcPrivateLinkServiceID       = "/subscriptions/wc-subscription/resourceGroups/awesome-wc/providers/Microsoft.Network/privateLinkServices/awesome-wc-privatelink"
+)
+
+var _ = Describe("Detector", func() {
+	var gomockController *gomock.Controller
+	var privateEndpointsClient *mock_azure.MockPrivateEndpointsClient
+	var fakeRecorder *k8sevents.FakeRecorder
+	var k8sClient client.Client
+	var managementAzureCluster *capz.AzureCluster
+	var detector *drift.Detector
+
+	privateEndpointTo := func(name, resourceID string) *armnetwork.PrivateEndpoint {
+		return &armnetwork.PrivateEndpoint{
+			Name: to.Ptr(name),
+			Properties: &armnetwork.PrivateEndpointProperties{
+				PrivateLinkServiceConnections: []*armnetwork.PrivateLinkServiceConnection{
+					{Properties: &armnetwork.PrivateLinkServiceConnectionProperties{PrivateLinkServiceID: to.Ptr(resourceID)}},
+				},
+			},
+		}
+	}
+
+	getManagementAzureCluster := func(ctx context.Context) *capz.AzureCluster {
+		var azureCluster capz.AzureCluster
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(managementAzureCluster), &azureCluster)).To(Succeed())
+		return &azureCluster
+	}
+
+	newDetector := func(triggerReconcile bool) *drift.Detector {
+		privateEndpointsClientCreator := func(_ context.Context, _ client.Client, _ *capz.AzureCluster) (azure.PrivateEndpointsClient, error) {
+			return privateEndpointsClient, nil
+		}
+		recorder, err := events.NewRecorder(fakeRecorder, events.DefaultDeduplicationWindow)
+		Expect(err).NotTo(HaveOccurred())
+		detector, err := drift.NewDetector(k8sClient, privateEndpointsClientCreator, recorder, drift.Options{
+			Interval:         time.Minute,
+			TriggerReconcile: triggerReconcile,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		return detector
+	}
+
+	BeforeEach(func() {
+		gomockController = gomock.NewController(GinkgoT())
+		privateEndpointsClient = mock_azure.NewMockPrivateEndpointsClient(gomockController)
+		fakeRecorder = k8sevents.NewFakeRecorder(10)
+
+		managementAzureCluster = testhelpers.NewAzureClusterBuilder("org-giantswarm", "giant").
+			WithSubscriptionID("mc-subscription").
+			WithResourceGroup("giant").
+			WithAPILoadBalancerType(capz.Internal).
+			WithSubnet("giant-node-subnet", capz.SubnetNode, capz.PrivateEndpoints{
+				{
+					Name: managedPrivateEndpointName,
+					PrivateLinkServiceConnections: []capz.PrivateLinkServiceConnection{
+						{PrivateLinkServiceID: wcPrivateLinkServiceID},
+					},
+				},
+				{
+					Name: storagePrivateEndpointName,
+					PrivateLinkServiceConnections: []capz.PrivateLinkServiceConnection{
+						{PrivateLinkServiceID: "/subscriptions/mc-subscription/resourceGroups/giant/providers/Microsoft.Storage/storageAccounts/giant"},
+					},
+				},
+			}).
+			WithCondition(&capi.Condition{
+				Type:   capz.PrivateEndpointsReadyCondition,
+				Status: corev1.ConditionTrue,
+			}).
+			Build()
+		publicAzureCluster := testhelpers.NewAzureClusterBuilder("org-acme", "public-wc").
+			WithAPILoadBalancerType(capz.Public).
+			Build()
+
+		scheme := runtime.NewScheme()
+		Expect(capz.AddToScheme(scheme)).To(Succeed())
+		k8sClient = fake.NewClientBuilder().
+			WithScheme(scheme).
+			WithObjects(managementAzureCluster, publicAzureCluster).
+			WithStatusSubresource(&capz.AzureCluster{}).
+			Build()
+
+		detector = newDetector(false)
+	})
+
+	It("fails to create detector without interval", func() {
+		_, err := drift.NewDetector(k8sClient, azure.NewPrivateEndpointClient, &events.Recorder{}, drift.Options{})
+		Expect(errors.IsInvalidConfig(err)).To(BeTrue())
+	})
+
+	It("does not report private endpoints that are in sync", func(ctx context.Context) {
+		testhelpers.SetupPrivateEndpointClientToListPrivateEndpoints(privateEndpointsClient, "giant",
+			privateEndpointTo(managedPrivateEndpointName, wcPrivateLinkServiceID))
+
+		Expect(detector.Detect(ctx)).To(Succeed())
+
+		Expect(v1beta1conditions.Has(getManagementAzureCluster(ctx), drift.ConditionGSPrivateEndpointsInSync)).To(BeFalse())
+		Expect(fakeRecorder.Events).To(BeEmpty())
+	})
+
+	It("reports private endpoints that are missing in Azure or not managed", func(ctx context.Context) {
+		testhelpers.SetupPrivateEndpointClientToListPrivateEndpoints(privateEndpointsClient, "giant",
+			privateEndpointTo(unmanagedPrivateEndpointName, "/subscriptions/other/resourceGroups/other/providers/Microsoft.Network/privateLinkServices/other"))
+
+		Expect(detector.Detect(ctx)).To(Succeed())
+
+		azureCluster := getManagementAzureCluster(ctx)
+		condition := v1beta1conditions.Get(azureCluster, drift.ConditionGSPrivateEndpointsInSync)
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Status).To(Equal(corev1.ConditionFalse))
+		Expect(condition.Reason).To(Equal(drift.PrivateEndpointsDriftedReason))
+		Expect(condition.Message).To(ContainSubstring(managedPrivateEndpointName))
+		Expect(condition.Message).To(ContainSubstring(unmanagedPrivateEndpointName))
+		Expect(condition.Message).NotTo(ContainSubstring(storagePrivateEndpointName))
+		Expect(azureCluster.Annotations).NotTo(HaveKey(drift.DriftDetectedAtAnnotation))
+		Expect(fakeRecorder.Events).To(HaveLen(2))
+	})
+
+	It("does not report missing private endpoints while CAPZ creates them", func(ctx context.Context) {
+		azureCluster := getManagementAzureCluster(ctx)
+		v1beta1conditions.MarkFalse(azureCluster, capz.PrivateEndpointsReadyCondition, "Creating", capi.ConditionSeverityInfo, "")
+		Expect(k8sClient.Status().Update(ctx, azureCluster)).To(Succeed())
+		testhelpers.SetupPrivateEndpointClientToListPrivateEndpoints(privateEndpointsClient, "giant")
+
+		Expect(detector.Detect(ctx)).To(Succeed())
+
+		Expect(v1beta1conditions.Has(getManagementAzureCluster(ctx), drift.ConditionGSPrivateEndpointsInSync)).To(BeFalse())
+	})
+
+	It("triggers CAPZ reconciliation when private endpoints are missing in Azure", func(ctx context.Context) {
+		testhelpers.SetupPrivateEndpointClientToListPrivateEndpoints(privateEndpointsClient, "giant")
+
+		Expect(newDetector(true).Detect(ctx)).To(Succeed())
+
+		Expect(getManagementAzureCluster(ctx).Annotations).To(HaveKey(drift.DriftDetectedAtAnnotation))
+	})
+
+	It("marks the private endpoints as in sync again after the drift has been fixed", func(ctx context.Context) {
+		testhelpers.SetupPrivateEndpointClientToListPrivateEndpoints(privateEndpointsClient, "giant")
+		Expect(detector.Detect(ctx)).To(Succeed())
+		Expect(v1beta1conditions.IsFalse(getManagementAzureCluster(ctx), drift.ConditionGSPrivateEndpointsInSync)).To(BeTrue())
+
+		testhelpers.SetupPrivateEndpointClientToListPrivateEndpoints(privateEndpointsClient, "giant",
+			privateEndpointTo(managedPrivateEndpointName, wcPrivateLinkServiceID))
+		Expect(detector.Detect(ctx)).To(Succeed())
+		Expect(v1beta1conditions.IsTrue(getManagementAzureCluster(ctx), drift.ConditionGSPrivateEndpointsInSync)).To(BeTrue())
+	})
+})
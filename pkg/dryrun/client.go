@@ -0,0 +1,223 @@
+package dryrun
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/giantswarm/microerror"
+
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/errors"
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/events"
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/metrics"
+)
+
+const (
+	// maxChangeLengthInEvent limits the length of the change in the event message, as the Kubernetes
+	// API rejects events with a note longer than 1 kB. The full change is always logged.
+	maxChangeLengthInEvent = 768
+)
+
+// Client is a client.Client that does not persist any changes. Every write is sent to the API
+// server as a dry run, so that it is still validated and defaulted, and the change that would have
+// been applied is logged, emitted as an event on the changed object, and counted in the metrics.
+//
+// Reads are not changed, so the operator sees the same state as without dry-run mode.
+type Client struct {
+	client.Client
+	recorder *events.Recorder
+}
+
+func NewClient(c client.Client, recorder *events.Recorder) (*Client, error) {
+	if c == nil {
+		return nil, microerror.Maskf(errors.InvalidConfigError, "client must be set")
+	}
+	if recorder == nil {
+		return nil, microerror.Maskf(errors.InvalidConfigError, "recorder must be set")
+	}
+
+	return &Client{
+		Client:   client.NewDryRunClient(c),
+		recorder: recorder,
+	}, nil
+}
+
+func (c *Client) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	change, err := json.Marshal(obj)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	if err = c.Client.Create(ctx, obj, opts...); err != nil {
+		return microerror.Mask(err)
+	}
+
+	c.record(ctx, obj, events.ActionCreate, "", change)
+	return nil
+}
+
+func (c *Client) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	change, err := c.updatePatch(ctx, obj)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	if err = c.Client.Update(ctx, obj, opts...); err != nil {
+		return microerror.Mask(err)
+	}
+
+	c.record(ctx, obj, events.ActionUpdate, "", change)
+	return nil
+}
+
+func (c *Client) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	change, err := patch.Data(obj)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	if err = c.Client.Patch(ctx, obj, patch, opts...); err != nil {
+		return microerror.Mask(err)
+	}
+
+	c.record(ctx, obj, events.ActionPatch, "", change)
+	return nil
+}
+
+func (c *Client) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	if err := c.Client.Delete(ctx, obj, opts...); err != nil {
+		return microerror.Mask(err)
+	}
+
+	c.record(ctx, obj, events.ActionDelete, "", nil)
+	return nil
+}
+
+func (c *Client) Status() client.SubResourceWriter {
+	return c.SubResource("status")
+}
+
+func (c *Client) SubResource(subResource string) client.SubResourceClient {
+	return &subResourceClient{
+		SubResourceClient: c.Client.SubResource(subResource),
+		client:            c,
+		subResource:       subResource,
+	}
+}
+
+// updatePatch returns the merge patch from the current object in the cluster to the updated
+// object, or the whole updated object when the current object can not be read.
+func (c *Client) updatePatch(ctx context.Context, obj client.Object) ([]byte, error) {
+	current, ok := obj.DeepCopyObject().(client.Object)
+	if !ok {
+		return nil, microerror.Maskf(errors.InvalidConfigError, "%T is not a client.Object", obj)
+	}
+	if err := c.Get(ctx, client.ObjectKeyFromObject(obj), current); err != nil {
+		log.FromContext(ctx).V(1).Info("dry run: failed to get current object, showing whole object", "error", err)
+		change, err := json.Marshal(obj)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+		return change, nil
+	}
+	// Get clears the type meta of typed objects, which would otherwise show up in the patch.
+	current.GetObjectKind().SetGroupVersionKind(obj.GetObjectKind().GroupVersionKind())
+
+	change, err := client.MergeFrom(current).Data(obj)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	return change, nil
+}
+
+// record logs the change that would have been applied to the object, emits it as an event and
+// counts it in the metrics.
+func (c *Client) record(ctx context.Context, obj client.Object, operation, subResource string, change []byte) {
+	kind := c.kind(obj)
+	if subResource != "" {
+		kind = fmt.Sprintf("%s/%s", kind, subResource)
+	}
+
+	record(ctx, c.recorder, obj, kind, operation, change)
+}
+
+// record logs the change that would have been applied to a resource of the kind, emits it as an
+// event on the object and counts it in the metrics.
+func record(ctx context.Context, recorder *events.Recorder, obj client.Object, kind, operation string, change []byte) {
+	log.FromContext(ctx).Info(fmt.Sprintf("dry run: would %s %s", operation, kind),
+		"namespace", obj.GetNamespace(),
+		"name", obj.GetName(),
+		"change", string(change))
+	metrics.RecordDryRunChange(kind, operation)
+
+	message := fmt.Sprintf("Dry run: would %s %s", operation, kind)
+	if len(change) > 0 {
+		shown := string(change)
+		if len(shown) > maxChangeLengthInEvent {
+			shown = shown[:maxChangeLengthInEvent] + "..."
+		}
+		message = fmt.Sprintf("%s: %s", message, shown)
+	}
+	recorder.Normalf(obj, events.ReasonDryRun, operation, "%s", message)
+}
+
+func (c *Client) kind(obj runtime.Object) string {
+	gvk, err := c.GroupVersionKindFor(obj)
+	if err != nil {
+		return fmt.Sprintf("%T", obj)
+	}
+	return gvk.Kind
+}
+
+// subResourceClient records the writes to subresources, e.g. status, in the same way as Client.
+type subResourceClient struct {
+	client.SubResourceClient
+	client      *Client
+	subResource string
+}
+
+func (c *subResourceClient) Create(ctx context.Context, obj, subResource client.Object, opts ...client.SubResourceCreateOption) error {
+	change, err := json.Marshal(subResource)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	if err = c.SubResourceClient.Create(ctx, obj, subResource, opts...); err != nil {
+		return microerror.Mask(err)
+	}
+
+	c.client.record(ctx, obj, events.ActionCreate, c.subResource, change)
+	return nil
+}
+
+func (c *subResourceClient) Update(ctx context.Context, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+	change, err := c.client.updatePatch(ctx, obj)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	if err = c.SubResourceClient.Update(ctx, obj, opts...); err != nil {
+		return microerror.Mask(err)
+	}
+
+	c.client.record(ctx, obj, events.ActionUpdate, c.subResource, change)
+	return nil
+}
+
+func (c *subResourceClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.SubResourcePatchOption) error {
+	change, err := patch.Data(obj)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	if err = c.SubResourceClient.Patch(ctx, obj, patch, opts...); err != nil {
+		return microerror.Mask(err)
+	}
+
+	c.client.record(ctx, obj, events.ActionPatch, c.subResource, change)
+	return nil
+}
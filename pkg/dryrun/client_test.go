@@ -0,0 +1,115 @@
+package dryrun_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8sevents "k8s.io/client-go/tools/events"
+	capz "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/dryrun"
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/errors"
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/events"
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/testhelpers"
+)
+
+func TestDryRun(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Dry Run Suite")
+}
+
+var _ = Describe("Client", func() {
+	var k8sClient client.Client
+	var fakeRecorder *k8sevents.FakeRecorder
+	var dryRunClient *dryrun.Client
+	var azureCluster *capz.AzureCluster
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(capz.AddToScheme(scheme)).To(Succeed())
+
+		azureCluster = testhelpers.NewAzureClusterBuilder("org-giantswarm", "awesome-wc").
+			WithSubscriptionID("1234").
+			Build()
+		k8sClient = fake.NewClientBuilder().
+			WithScheme(scheme).
+			WithObjects(azureCluster).
+			WithStatusSubresource(azureCluster).
+			Build()
+
+		fakeRecorder = k8sevents.NewFakeRecorder(10)
+		recorder, err := events.NewRecorder(fakeRecorder, time.Hour)
+		Expect(err).NotTo(HaveOccurred())
+
+		dryRunClient, err = dryrun.NewClient(k8sClient, recorder)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("fails to create client when the recorder is nil", func() {
+		_, err := dryrun.NewClient(k8sClient, nil)
+		Expect(err).To(HaveOccurred())
+		Expect(errors.IsInvalidConfig(err)).To(BeTrue())
+	})
+
+	It("does not persist a patch and emits it as an event", func(ctx context.Context) {
+		patch := client.MergeFrom(azureCluster.DeepCopy())
+		azureCluster.Labels = map[string]string{"foo": "bar"}
+		Expect(dryRunClient.Patch(ctx, azureCluster, patch)).To(Succeed())
+
+		Expect(fakeRecorder.Events).To(Receive(Equal(`Normal DryRun Dry run: would Patch AzureCluster: {"metadata":{"labels":{"foo":"bar"}}}`)))
+
+		var persisted capz.AzureCluster
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(azureCluster), &persisted)).To(Succeed())
+		Expect(persisted.Labels).To(BeEmpty())
+	})
+
+	It("does not persist an update and emits the changes as an event", func(ctx context.Context) {
+		azureCluster.Spec.SubscriptionID = "5678"
+		Expect(dryRunClient.Update(ctx, azureCluster)).To(Succeed())
+
+		Expect(fakeRecorder.Events).To(Receive(Equal(`Normal DryRun Dry run: would Update AzureCluster: {"spec":{"subscriptionID":"5678"}}`)))
+
+		var persisted capz.AzureCluster
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(azureCluster), &persisted)).To(Succeed())
+		Expect(persisted.Spec.SubscriptionID).To(Equal("1234"))
+	})
+
+	It("does not persist a status patch", func(ctx context.Context) {
+		patch := client.MergeFrom(azureCluster.DeepCopy())
+		azureCluster.Status.Ready = true
+		Expect(dryRunClient.Status().Patch(ctx, azureCluster, patch)).To(Succeed())
+
+		Expect(fakeRecorder.Events).To(Receive(Equal(`Normal DryRun Dry run: would Patch AzureCluster/status: {"status":{"ready":true}}`)))
+
+		var persisted capz.AzureCluster
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(azureCluster), &persisted)).To(Succeed())
+		Expect(persisted.Status.Ready).To(BeFalse())
+	})
+
+	It("does not create an object", func(ctx context.Context) {
+		newAzureCluster := testhelpers.NewAzureClusterBuilder("org-giantswarm", "new-wc").Build()
+		Expect(dryRunClient.Create(ctx, newAzureCluster)).To(Succeed())
+
+		Expect(fakeRecorder.Events).To(Receive(HavePrefix("Normal DryRun Dry run: would Create AzureCluster: {")))
+
+		var persisted capz.AzureCluster
+		err := k8sClient.Get(ctx, client.ObjectKeyFromObject(newAzureCluster), &persisted)
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("does not delete an object", func(ctx context.Context) {
+		Expect(dryRunClient.Delete(ctx, azureCluster)).To(Succeed())
+
+		Expect(fakeRecorder.Events).To(Receive(Equal("Normal DryRun Dry run: would Delete AzureCluster")))
+
+		var persisted capz.AzureCluster
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(azureCluster), &persisted)).To(Succeed())
+	})
+})
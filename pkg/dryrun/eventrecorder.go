@@ -0,0 +1,35 @@
+package dryrun
+
+import (
+	"fmt"
+
+	"github.com/giantswarm/microerror"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8sevents "k8s.io/client-go/tools/events"
+
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/errors"
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/events"
+)
+
+// EventRecorder emits the events of the controllers as DryRun events, because the changes that
+// they report, e.g. an added private endpoint, have not been persisted in dry-run mode. The original
+// reason is kept in the message.
+type EventRecorder struct {
+	recorder k8sevents.EventRecorder
+}
+
+var _ k8sevents.EventRecorder = &EventRecorder{}
+
+func NewEventRecorder(recorder k8sevents.EventRecorder) (*EventRecorder, error) {
+	if recorder == nil {
+		return nil, microerror.Maskf(errors.InvalidConfigError, "recorder must be set")
+	}
+
+	return &EventRecorder{
+		recorder: recorder,
+	}, nil
+}
+
+func (r *EventRecorder) Eventf(regarding runtime.Object, related runtime.Object, eventtype, reason, action, note string, args ...any) {
+	r.recorder.Eventf(regarding, related, eventtype, events.ReasonDryRun, action, "Dry run: would emit %s: %s", reason, fmt.Sprintf(note, args...))
+}
@@ -0,0 +1,41 @@
+package dryrun_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	k8sevents "k8s.io/client-go/tools/events"
+
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/dryrun"
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/errors"
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/events"
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/testhelpers"
+)
+
+var _ = Describe("EventRecorder", func() {
+	var fakeRecorder *k8sevents.FakeRecorder
+	var recorder *events.Recorder
+
+	BeforeEach(func() {
+		fakeRecorder = k8sevents.NewFakeRecorder(10)
+		dryRunRecorder, err := dryrun.NewEventRecorder(fakeRecorder)
+		Expect(err).NotTo(HaveOccurred())
+
+		recorder, err = events.NewRecorder(dryRunRecorder, events.DefaultDeduplicationWindow)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("fails to create recorder when the recorder is nil", func() {
+		_, err := dryrun.NewEventRecorder(nil)
+		Expect(err).To(HaveOccurred())
+		Expect(errors.IsInvalidConfig(err)).To(BeTrue())
+	})
+
+	It("emits events as DryRun events with the original reason", func() {
+		azureCluster := testhelpers.NewAzureClusterBuilder("org-giantswarm", "awesome-wc").Build()
+		recorder.Normalf(azureCluster, events.ReasonPrivateEndpointAdded, events.ActionAddPrivateEndpoint, "Added private endpoint %s", "awesome-pe")
+		recorder.Warningf(azureCluster, events.ReasonReconcileError, events.ActionReconcile, "Failed to reconcile")
+
+		Expect(fakeRecorder.Events).To(Receive(Equal("Normal DryRun Dry run: would emit PrivateEndpointAdded: Added private endpoint awesome-pe")))
+		Expect(fakeRecorder.Events).To(Receive(Equal("Warning DryRun Dry run: would emit ReconcileError: Failed to reconcile")))
+	})
+})
@@ -0,0 +1,72 @@
+package dryrun
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/msi/armmsi"
+	"github.com/giantswarm/microerror"
+	capz "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/azure"
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/errors"
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/events"
+)
+
+const federatedIdentityCredentialKind = "FederatedIdentityCredential"
+
+// NewFederatedIdentityCredentialsClientCreator wraps the creator, so that its clients do not write
+// any federated identity credentials to Azure. The credentials of the AzureCluster are still
+// created, so that they are validated, and the writes are logged, emitted as events on the
+// AzureCluster, and counted in the metrics, like the writes of Client.
+func NewFederatedIdentityCredentialsClientCreator(creator azure.FederatedIdentityCredentialsClientCreator, recorder *events.Recorder) (azure.FederatedIdentityCredentialsClientCreator, error) {
+	if creator == nil {
+		return nil, microerror.Maskf(errors.InvalidConfigError, "creator must be set")
+	}
+	if recorder == nil {
+		return nil, microerror.Maskf(errors.InvalidConfigError, "recorder must be set")
+	}
+
+	return func(ctx context.Context, c client.Client, azureCluster *capz.AzureCluster) (azure.FederatedIdentityCredentialsClient, error) {
+		if _, err := creator(ctx, c, azureCluster); err != nil {
+			return nil, microerror.Mask(err)
+		}
+		return &federatedIdentityCredentialsClient{
+			azureCluster: azureCluster,
+			recorder:     recorder,
+		}, nil
+	}, nil
+}
+
+type federatedIdentityCredentialsClient struct {
+	azureCluster *capz.AzureCluster
+	recorder     *events.Recorder
+}
+
+func (c *federatedIdentityCredentialsClient) CreateOrUpdate(ctx context.Context, identityClientID string, name string, properties armmsi.FederatedIdentityCredentialProperties) error {
+	change, err := json.Marshal(map[string]any{
+		"identityClientID": identityClientID,
+		"name":             name,
+		"properties":       properties,
+	})
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	record(ctx, c.recorder, c.azureCluster, federatedIdentityCredentialKind, events.ActionCreate, change)
+	return nil
+}
+
+func (c *federatedIdentityCredentialsClient) Delete(ctx context.Context, identityClientID string, name string) error {
+	change, err := json.Marshal(map[string]any{
+		"identityClientID": identityClientID,
+		"name":             name,
+	})
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	record(ctx, c.recorder, c.azureCluster, federatedIdentityCredentialKind, events.ActionDelete, change)
+	return nil
+}
@@ -0,0 +1,77 @@
+package dryrun_test
+
+import (
+	"context"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/msi/armmsi"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/mock/gomock"
+	k8sevents "k8s.io/client-go/tools/events"
+	capz "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/azure"
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/azure/mock_azure"
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/dryrun"
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/errors"
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/events"
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/testhelpers"
+)
+
+var _ = Describe("FederatedIdentityCredentialsClient", func() {
+	var fakeRecorder *k8sevents.FakeRecorder
+	var recorder *events.Recorder
+	var credentialsClient azure.FederatedIdentityCredentialsClient
+	var azureCluster *capz.AzureCluster
+
+	BeforeEach(func(ctx context.Context) {
+		fakeRecorder = k8sevents.NewFakeRecorder(10)
+		var err error
+		recorder, err = events.NewRecorder(fakeRecorder, time.Hour)
+		Expect(err).NotTo(HaveOccurred())
+
+		// The mock fails the test on any call, as nothing must be written to Azure.
+		mockClient := mock_azure.NewMockFederatedIdentityCredentialsClient(gomock.NewController(GinkgoT()))
+		creator, err := dryrun.NewFederatedIdentityCredentialsClientCreator(func(context.Context, client.Client, *capz.AzureCluster) (azure.FederatedIdentityCredentialsClient, error) {
+			return mockClient, nil
+		}, recorder)
+		Expect(err).NotTo(HaveOccurred())
+
+		azureCluster = testhelpers.NewAzureClusterBuilder("org-giantswarm", "awesome-wc").Build()
+		credentialsClient, err = creator(ctx, nil, azureCluster)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("fails to create the creator when the recorder is nil", func() {
+		_, err := dryrun.NewFederatedIdentityCredentialsClientCreator(azure.NewFederatedIdentityCredentialsClient, nil)
+		Expect(errors.IsInvalidConfig(err)).To(BeTrue())
+	})
+
+	It("does not create the credential and emits it as an event", func(ctx context.Context) {
+		err := credentialsClient.CreateOrUpdate(ctx, "client", "crossplane", armmsi.FederatedIdentityCredentialProperties{
+			Issuer: to.Ptr("https://oidc.example.com"),
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(fakeRecorder.Events).To(Receive(Equal(`Normal DryRun Dry run: would Create FederatedIdentityCredential: {"identityClientID":"client","name":"crossplane","properties":{"issuer":"https://oidc.example.com"}}`)))
+	})
+
+	It("does not delete the credential and emits it as an event", func(ctx context.Context) {
+		Expect(credentialsClient.Delete(ctx, "client", "crossplane")).To(Succeed())
+
+		Expect(fakeRecorder.Events).To(Receive(Equal(`Normal DryRun Dry run: would Delete FederatedIdentityCredential: {"identityClientID":"client","name":"crossplane"}`)))
+	})
+
+	It("returns the errors of the wrapped creator", func(ctx context.Context) {
+		creator, err := dryrun.NewFederatedIdentityCredentialsClientCreator(func(context.Context, client.Client, *capz.AzureCluster) (azure.FederatedIdentityCredentialsClient, error) {
+			return nil, errors.AzureClusterIdentityNotAllowedError
+		}, recorder)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = creator(ctx, nil, azureCluster)
+		Expect(errors.IsAzureClusterIdentityNotAllowed(err)).To(BeTrue())
+	})
+})
@@ -3,8 +3,36 @@ package errors
 import (
 	"errors"
 	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/giantswarm/microerror"
+)
+
+var (
+	// azureAuthFailureErrorCodes are ARM error codes that are returned when the identity used by
+	// the operator is not allowed to make the request.
+	azureAuthFailureErrorCodes = []string{
+		"AuthenticationFailed",
+		"AuthorizationFailed",
+		"InvalidAuthenticationToken",
+		"InvalidAuthenticationTokenTenant",
+		"LinkedAuthorizationFailed",
+	}
+
+	// azureTransientStatusCodes are HTTP status codes that indicate a temporary problem on the
+	// Azure side, so the request can be retried.
+	azureTransientStatusCodes = []int{
+		http.StatusRequestTimeout,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout,
+	}
 )
 
 // IsAzureResourceNotFound asserts if Azure API call returned a NotFound error
@@ -15,3 +43,106 @@ func IsAzureResourceNotFound(err error) bool {
 	}
 	return false
 }
+
+// IsAzureThrottled asserts if Azure API call was throttled.
+func IsAzureThrottled(err error) bool {
+	var responseError *azcore.ResponseError
+	if errors.As(err, &responseError) {
+		return responseError.StatusCode == http.StatusTooManyRequests
+	}
+	return false
+}
+
+// IsAzureTransient asserts if Azure API call failed because of a temporary problem on the Azure
+// side, so that it can be retried.
+func IsAzureTransient(err error) bool {
+	var responseError *azcore.ResponseError
+	if errors.As(err, &responseError) {
+		return slices.Contains(azureTransientStatusCodes, responseError.StatusCode)
+	}
+	return false
+}
+
+// IsAzureAuthFailure asserts if Azure API call failed because the credentials are invalid, or
+// because the identity is not allowed to make the request. Retrying does not help here, as the
+// identity or its role assignments have to be fixed first.
+func IsAzureAuthFailure(err error) bool {
+	var authenticationFailedError *azidentity.AuthenticationFailedError
+	if errors.As(err, &authenticationFailedError) {
+		return true
+	}
+
+	var responseError *azcore.ResponseError
+	if errors.As(err, &responseError) {
+		return responseError.StatusCode == http.StatusUnauthorized ||
+			responseError.StatusCode == http.StatusForbidden ||
+			slices.Contains(azureAuthFailureErrorCodes, responseError.ErrorCode)
+	}
+	return false
+}
+
+// IsAzureTerminal asserts if Azure API call failed in a way that retrying does not fix, e.g.
+// because the identity is not allowed to make the request, a quota has been reached or the request
+// is invalid. Throttled requests and temporary problems on the Azure side are not terminal.
+func IsAzureTerminal(err error) bool {
+	if IsAzureAuthFailure(err) {
+		return true
+	}
+
+	var responseError *azcore.ResponseError
+	if errors.As(err, &responseError) {
+		return responseError.StatusCode >= http.StatusBadRequest &&
+			responseError.StatusCode < http.StatusInternalServerError &&
+			!IsAzureThrottled(err) &&
+			!IsAzureTransient(err)
+	}
+	return false
+}
+
+// IsAzureQuotaExceeded asserts if Azure API call failed because a subscription quota or limit has
+// been reached.
+func IsAzureQuotaExceeded(err error) bool {
+	var responseError *azcore.ResponseError
+	if errors.As(err, &responseError) {
+		return strings.Contains(responseError.ErrorCode, "QuotaExceeded") ||
+			strings.Contains(responseError.ErrorCode, "LimitExceeded")
+	}
+	return false
+}
+
+// AzureRetryAfter returns the duration from the Retry-After header of the Azure API response,
+// which Azure sets when the request has been throttled. The second return value is false when the
+// response does not have a valid Retry-After header.
+func AzureRetryAfter(err error) (time.Duration, bool) {
+	var responseError *azcore.ResponseError
+	if !errors.As(err, &responseError) || responseError.RawResponse == nil {
+		return 0, false
+	}
+
+	retryAfter := responseError.RawResponse.Header.Get("Retry-After")
+	if retryAfter == "" {
+		return 0, false
+	}
+
+	// Retry-After is either a number of seconds, or an HTTP date.
+	if seconds, err := strconv.Atoi(retryAfter); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(retryAfter); err == nil {
+		return max(time.Until(date), 0), true
+	}
+
+	return 0, false
+}
+
+var UnknownAzureEnvironmentError = &microerror.Error{
+	Kind: "UnknownAzureEnvironmentError",
+}
+
+// IsUnknownAzureEnvironment asserts UnknownAzureEnvironmentError.
+func IsUnknownAzureEnvironment(err error) bool {
+	return microerror.Cause(err) == UnknownAzureEnvironmentError
+}
@@ -0,0 +1,151 @@
+package errors_test
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/giantswarm/microerror"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/errors"
+)
+
+// classification is the result of all Azure error predicates for an error.
+type classification struct {
+	throttled     bool
+	transient     bool
+	authFailure   bool
+	quotaExceeded bool
+	terminal      bool
+}
+
+func responseError(statusCode int, errorCode string, header http.Header) *azcore.ResponseError {
+	return &azcore.ResponseError{
+		StatusCode: statusCode,
+		ErrorCode:  errorCode,
+		RawResponse: &http.Response{
+			StatusCode: statusCode,
+			Header:     header,
+		},
+	}
+}
+
+var _ = DescribeTable("classifying Azure errors",
+	func(err error, want classification) {
+		Expect(classification{
+			throttled:     errors.IsAzureThrottled(err),
+			transient:     errors.IsAzureTransient(err),
+			authFailure:   errors.IsAzureAuthFailure(err),
+			quotaExceeded: errors.IsAzureQuotaExceeded(err),
+			terminal:      errors.IsAzureTerminal(err),
+		}).To(Equal(want))
+	},
+	Entry("throttled request",
+		responseError(http.StatusTooManyRequests, "TooManyRequests", http.Header{"Retry-After": {"7"}}),
+		classification{throttled: true},
+	),
+	Entry("internal server error",
+		responseError(http.StatusInternalServerError, "InternalServerError", nil),
+		classification{transient: true},
+	),
+	Entry("service unavailable",
+		responseError(http.StatusServiceUnavailable, "", nil),
+		classification{transient: true},
+	),
+	Entry("gateway timeout",
+		responseError(http.StatusGatewayTimeout, "", nil),
+		classification{transient: true},
+	),
+	Entry("unauthorized",
+		responseError(http.StatusUnauthorized, "InvalidAuthenticationToken", nil),
+		classification{authFailure: true, terminal: true},
+	),
+	Entry("forbidden",
+		responseError(http.StatusForbidden, "AuthorizationFailed", nil),
+		classification{authFailure: true, terminal: true},
+	),
+	Entry("failed token request",
+		&azidentity.AuthenticationFailedError{RawResponse: &http.Response{StatusCode: http.StatusUnauthorized}},
+		classification{authFailure: true, terminal: true},
+	),
+	Entry("quota exceeded",
+		responseError(http.StatusConflict, "QuotaExceeded", nil),
+		classification{quotaExceeded: true, terminal: true},
+	),
+	Entry("limit exceeded",
+		responseError(http.StatusBadRequest, "PrivateEndpointLimitExceeded", nil),
+		classification{quotaExceeded: true, terminal: true},
+	),
+	Entry("bad request",
+		responseError(http.StatusBadRequest, "InvalidParameter", nil),
+		classification{terminal: true},
+	),
+	Entry("wrapped throttled request",
+		fmt.Errorf("failed to list private endpoints: %w", responseError(http.StatusTooManyRequests, "", nil)),
+		classification{throttled: true},
+	),
+	Entry("masked internal server error",
+		microerror.Mask(responseError(http.StatusInternalServerError, "", nil)),
+		classification{transient: true},
+	),
+	Entry("masked forbidden",
+		microerror.Mask(responseError(http.StatusForbidden, "AuthorizationFailed", nil)),
+		classification{authFailure: true, terminal: true},
+	),
+	Entry("wrapped masked quota exceeded",
+		fmt.Errorf("failed to create private endpoint: %w", microerror.Mask(responseError(http.StatusConflict, "QuotaExceeded", nil))),
+		classification{quotaExceeded: true, terminal: true},
+	),
+	Entry("other error",
+		fmt.Errorf("connection refused"),
+		classification{},
+	),
+	Entry("nil error",
+		nil,
+		classification{},
+	),
+)
+
+var _ = DescribeTable("AzureRetryAfter",
+	func(err error, wantRetryAfter time.Duration, wantOK bool) {
+		retryAfter, ok := errors.AzureRetryAfter(err)
+		Expect(ok).To(Equal(wantOK))
+		Expect(retryAfter).To(Equal(wantRetryAfter))
+	},
+	Entry("throttled request with seconds",
+		responseError(http.StatusTooManyRequests, "", http.Header{"Retry-After": {"7"}}),
+		7*time.Second, true,
+	),
+	Entry("throttled request with a date in the past",
+		responseError(http.StatusTooManyRequests, "", http.Header{"Retry-After": {"Mon, 02 Jan 2006 15:04:05 GMT"}}),
+		time.Duration(0), true,
+	),
+	Entry("throttled request without Retry-After",
+		responseError(http.StatusTooManyRequests, "", nil),
+		time.Duration(0), false,
+	),
+	Entry("throttled request with invalid Retry-After",
+		responseError(http.StatusTooManyRequests, "", http.Header{"Retry-After": {"soon"}}),
+		time.Duration(0), false,
+	),
+	Entry("throttled request with negative Retry-After",
+		responseError(http.StatusTooManyRequests, "", http.Header{"Retry-After": {"-1"}}),
+		time.Duration(0), false,
+	),
+	Entry("response error without response",
+		&azcore.ResponseError{StatusCode: http.StatusTooManyRequests},
+		time.Duration(0), false,
+	),
+	Entry("wrapped masked throttled request",
+		fmt.Errorf("failed to get private endpoint: %w", microerror.Mask(responseError(http.StatusTooManyRequests, "", http.Header{"Retry-After": {"30"}}))),
+		30*time.Second, true,
+	),
+	Entry("other error",
+		fmt.Errorf("connection refused"),
+		time.Duration(0), false,
+	),
+)
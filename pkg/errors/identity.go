@@ -0,0 +1,41 @@
+package errors
+
+import (
+	"github.com/giantswarm/microerror"
+)
+
+var AzureClusterIdentityNotAllowedError = &microerror.Error{
+	Kind: "AzureClusterIdentityNotAllowedError",
+}
+
+// IsAzureClusterIdentityNotAllowed asserts AzureClusterIdentityNotAllowedError.
+func IsAzureClusterIdentityNotAllowed(err error) bool {
+	return microerror.Cause(err) == AzureClusterIdentityNotAllowedError
+}
+
+var IdentityRefNotSetError = &microerror.Error{
+	Kind: "IdentityRefNotSetError",
+}
+
+// IsIdentityRefNotSet asserts IdentityRefNotSetError.
+func IsIdentityRefNotSet(err error) bool {
+	return microerror.Cause(err) == IdentityRefNotSetError
+}
+
+var UnsupportedIdentityTypeError = &microerror.Error{
+	Kind: "UnsupportedIdentityTypeError",
+}
+
+// IsUnsupportedIdentityType asserts UnsupportedIdentityTypeError.
+func IsUnsupportedIdentityType(err error) bool {
+	return microerror.Cause(err) == UnsupportedIdentityTypeError
+}
+
+var UserAssignedIdentityNotFoundError = &microerror.Error{
+	Kind: "UserAssignedIdentityNotFoundError",
+}
+
+// IsUserAssignedIdentityNotFound asserts UserAssignedIdentityNotFoundError.
+func IsUserAssignedIdentityNotFound(err error) bool {
+	return microerror.Cause(err) == UserAssignedIdentityNotFoundError
+}
@@ -0,0 +1,52 @@
+package errors
+
+import (
+	"errors"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/giantswarm/microerror"
+)
+
+const (
+	azureResponseErrorKind      = "AzureResponseError"
+	azureThrottledKind          = "AzureThrottled"
+	azureTransientErrorKind     = "AzureTransientError"
+	azureAuthFailureKind        = "AzureAuthFailure"
+	azureResourceNotFoundKind   = "AzureResourceNotFound"
+	azureQuotaExceededErrorKind = "AzureQuotaExceeded"
+	unknownErrorKind            = "UnknownError"
+)
+
+// Kind returns the kind of the error, so that errors can be grouped, e.g. in metrics. For errors
+// defined in this package it is the microerror kind, and Azure API errors are grouped by their
+// classification.
+func Kind(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	var microErr *microerror.Error
+	if errors.As(microerror.Cause(err), &microErr) {
+		return microErr.Kind
+	}
+
+	switch {
+	case IsAzureThrottled(err):
+		return azureThrottledKind
+	case IsAzureAuthFailure(err):
+		return azureAuthFailureKind
+	case IsAzureResourceNotFound(err):
+		return azureResourceNotFoundKind
+	case IsAzureQuotaExceeded(err):
+		return azureQuotaExceededErrorKind
+	case IsAzureTransient(err):
+		return azureTransientErrorKind
+	}
+
+	var responseError *azcore.ResponseError
+	if errors.As(err, &responseError) {
+		return azureResponseErrorKind
+	}
+
+	return unknownErrorKind
+}
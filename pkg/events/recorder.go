@@ -0,0 +1,125 @@
+package events
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	k8sevents "k8s.io/client-go/tools/events"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/giantswarm/microerror"
+
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/errors"
+)
+
+const (
+	ReasonPrivateEndpointAdded            = "PrivateEndpointAdded"
+	ReasonPrivateEndpointRemoved          = "PrivateEndpointRemoved"
+	ReasonPrivateEndpointIPAddressChanged = "PrivateEndpointIPAddressChanged"
+	ReasonPrivateEndpointApprovalPending  = "PrivateEndpointApprovalPending"
+	ReasonReconcileError                  = "ReconcileError"
+	ReasonDryRun                          = "DryRun"
+	ReasonPrivateEndpointDrift            = "PrivateEndpointDrift"
+	ReasonProviderConfigReady             = "ProviderConfigReady"
+	ReasonProviderConfigNotReady          = "ProviderConfigNotReady"
+
+	ActionAddPrivateEndpoint     = "AddPrivateEndpoint"
+	ActionRemovePrivateEndpoint  = "RemovePrivateEndpoint"
+	ActionSetPrivateEndpointIP   = "SetPrivateEndpointIPAddress"
+	ActionApprovePrivateEndpoint = "ApprovePrivateEndpoint"
+	ActionReconcile              = "Reconcile"
+	ActionCreate                 = "Create"
+	ActionUpdate                 = "Update"
+	ActionPatch                  = "Patch"
+	ActionDelete                 = "Delete"
+	ActionDetectDrift            = "DetectDrift"
+	ActionGenerateProviderConfig = "GenerateProviderConfig"
+
+	// DefaultDeduplicationWindow is the time during which an identical event for the same object is
+	// emitted only once.
+	DefaultDeduplicationWindow = time.Hour
+)
+
+// Recorder emits Kubernetes events for AzureCluster objects. Identical events (same object, type,
+// reason and message) are emitted only once per deduplication window, so that periodic resyncs of
+// an unchanged cluster do not produce the same events over and over again.
+type Recorder struct {
+	recorder k8sevents.EventRecorder
+	window   time.Duration
+	now      func() time.Time
+
+	mu   sync.Mutex
+	seen map[eventKey]time.Time
+}
+
+type eventKey struct {
+	object    types.NamespacedName
+	uid       types.UID
+	eventType string
+	reason    string
+	message   string
+}
+
+func NewRecorder(recorder k8sevents.EventRecorder, window time.Duration) (*Recorder, error) {
+	if recorder == nil {
+		return nil, microerror.Maskf(errors.InvalidConfigError, "recorder must be set")
+	}
+	if window < 0 {
+		return nil, microerror.Maskf(errors.InvalidConfigError, "window must not be negative")
+	}
+
+	return &Recorder{
+		recorder: recorder,
+		window:   window,
+		now:      time.Now,
+		seen:     map[eventKey]time.Time{},
+	}, nil
+}
+
+// Normalf emits an event of type Normal for the specified object.
+func (r *Recorder) Normalf(object client.Object, reason, action, messageFmt string, args ...any) {
+	r.eventf(object, corev1.EventTypeNormal, reason, action, messageFmt, args...)
+}
+
+// Warningf emits an event of type Warning for the specified object.
+func (r *Recorder) Warningf(object client.Object, reason, action, messageFmt string, args ...any) {
+	r.eventf(object, corev1.EventTypeWarning, reason, action, messageFmt, args...)
+}
+
+func (r *Recorder) eventf(object client.Object, eventType, reason, action, messageFmt string, args ...any) {
+	message := fmt.Sprintf(messageFmt, args...)
+	if !r.shouldEmit(eventKey{
+		object:    client.ObjectKeyFromObject(object),
+		uid:       object.GetUID(),
+		eventType: eventType,
+		reason:    reason,
+		message:   message,
+	}) {
+		return
+	}
+
+	r.recorder.Eventf(object, nil, eventType, reason, action, "%s", message)
+}
+
+func (r *Recorder) shouldEmit(key eventKey) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+
+	// Forget expired events, so that the map does not grow with every deleted cluster.
+	for k, emittedAt := range r.seen {
+		if now.Sub(emittedAt) >= r.window {
+			delete(r.seen, k)
+		}
+	}
+
+	if _, ok := r.seen[key]; ok {
+		return false
+	}
+	r.seen[key] = now
+	return true
+}
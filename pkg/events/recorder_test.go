@@ -0,0 +1,68 @@
+package events
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	k8sevents "k8s.io/client-go/tools/events"
+
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/errors"
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/testhelpers"
+)
+
+func TestEvents(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Events Suite")
+}
+
+var _ = Describe("Recorder", func() {
+	var fakeRecorder *k8sevents.FakeRecorder
+	var recorder *Recorder
+	var now time.Time
+
+	BeforeEach(func() {
+		fakeRecorder = k8sevents.NewFakeRecorder(10)
+		now = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+		var err error
+		recorder, err = NewRecorder(fakeRecorder, time.Hour)
+		Expect(err).NotTo(HaveOccurred())
+		recorder.now = func() time.Time { return now }
+	})
+
+	It("fails to create recorder when the event recorder is nil", func() {
+		_, err := NewRecorder(nil, time.Hour)
+		Expect(err).To(HaveOccurred())
+		Expect(errors.IsInvalidConfig(err)).To(BeTrue())
+	})
+
+	It("emits an event", func() {
+		azureCluster := testhelpers.NewAzureClusterBuilder("org-giantswarm", "foo").Build()
+		recorder.Normalf(azureCluster, ReasonPrivateEndpointAdded, ActionAddPrivateEndpoint, "added %s", "foo-privateendpoint")
+		Expect(fakeRecorder.Events).To(Receive(Equal("Normal PrivateEndpointAdded added foo-privateendpoint")))
+	})
+
+	It("does not emit the same event twice within the deduplication window", func() {
+		azureCluster := testhelpers.NewAzureClusterBuilder("org-giantswarm", "foo").Build()
+		recorder.Warningf(azureCluster, ReasonReconcileError, ActionReconcile, "boom")
+		recorder.Warningf(azureCluster, ReasonReconcileError, ActionReconcile, "boom")
+		Expect(fakeRecorder.Events).To(HaveLen(1))
+	})
+
+	It("emits events with different messages", func() {
+		azureCluster := testhelpers.NewAzureClusterBuilder("org-giantswarm", "foo").Build()
+		recorder.Warningf(azureCluster, ReasonReconcileError, ActionReconcile, "boom")
+		recorder.Warningf(azureCluster, ReasonReconcileError, ActionReconcile, "bang")
+		Expect(fakeRecorder.Events).To(HaveLen(2))
+	})
+
+	It("emits the same event again after the deduplication window", func() {
+		azureCluster := testhelpers.NewAzureClusterBuilder("org-giantswarm", "foo").Build()
+		recorder.Warningf(azureCluster, ReasonReconcileError, ActionReconcile, "boom")
+		now = now.Add(time.Hour)
+		recorder.Warningf(azureCluster, ReasonReconcileError, ActionReconcile, "boom")
+		Expect(fakeRecorder.Events).To(HaveLen(2))
+	})
+})
@@ -0,0 +1,161 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/types"
+	capz "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/giantswarm/microerror"
+
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/errors"
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/privatelinks"
+)
+
+const (
+	collectTimeout = 10 * time.Second
+)
+
+var (
+	privateEndpointsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "private_endpoints"),
+		"Number of private endpoints managed by the operator per management cluster and direction.",
+		[]string{"management_cluster", "direction"},
+		nil,
+	)
+
+	privateEndpointInfoDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "private_endpoint_info"),
+		"Information about a private endpoint managed by the operator and its published IP address.",
+		[]string{"cluster_namespace", "cluster_name", "private_endpoint", "direction", "ip_address"},
+		nil,
+	)
+)
+
+// PrivateEndpointsCollector collects the state of the private endpoints that are managed by the
+// operator from the AzureCluster CRs every time the metrics are scraped, so that deleted clusters
+// and removed private endpoints disappear from the metrics automatically.
+type PrivateEndpointsCollector struct {
+	client                client.Reader
+	managementClusterName types.NamespacedName
+}
+
+func NewPrivateEndpointsCollector(client client.Reader, managementClusterName types.NamespacedName) (*PrivateEndpointsCollector, error) {
+	if client == nil {
+		return nil, microerror.Maskf(errors.InvalidConfigError, "client must be set")
+	}
+	if managementClusterName.Name == "" {
+		return nil, microerror.Maskf(errors.InvalidConfigError, "%T.Name must be set", managementClusterName)
+	}
+	if managementClusterName.Namespace == "" {
+		return nil, microerror.Maskf(errors.InvalidConfigError, "%T.Namespace must be set", managementClusterName)
+	}
+
+	return &PrivateEndpointsCollector{
+		client:                client,
+		managementClusterName: managementClusterName,
+	}, nil
+}
+
+func (c *PrivateEndpointsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- privateEndpointsDesc
+	ch <- privateEndpointInfoDesc
+}
+
+func (c *PrivateEndpointsCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), collectTimeout)
+	defer cancel()
+	logger := log.FromContext(ctx).WithName("metrics")
+
+	var azureClusters capz.AzureClusterList
+	if err := c.client.List(ctx, &azureClusters); err != nil {
+		logger.Error(err, "failed to list AzureClusters")
+		return
+	}
+
+	var managementAzureCluster *capz.AzureCluster
+	for i := range azureClusters.Items {
+		if azureClusters.Items[i].Name == c.managementClusterName.Name &&
+			azureClusters.Items[i].Namespace == c.managementClusterName.Namespace {
+			managementAzureCluster = &azureClusters.Items[i]
+			break
+		}
+	}
+	if managementAzureCluster == nil {
+		logger.Info("management cluster AzureCluster not found", "name", c.managementClusterName)
+		return
+	}
+
+	count := map[string]int{
+		DirectionMcToWcApi:     0,
+		DirectionWcToMcIngress: 0,
+	}
+	for i := range azureClusters.Items {
+		workloadAzureCluster := &azureClusters.Items[i]
+		if workloadAzureCluster == managementAzureCluster {
+			continue
+		}
+
+		mcToWcApi := privateEndpointsConnectingTo(managementAzureCluster,
+			workloadAzureCluster.Spec.SubscriptionID,
+			workloadAzureCluster.Spec.ResourceGroup)
+		count[DirectionMcToWcApi] += len(mcToWcApi)
+		c.collectInfo(ch, workloadAzureCluster, mcToWcApi, DirectionMcToWcApi, privatelinks.AzurePrivateEndpointOperatorApiServerAnnotation)
+
+		// Private endpoints to the MC ingress connect to the private link in the MC resource group,
+		// which is named after the MC (see privateendpoints.WcToMcIngressPrivateEndpointSpecs).
+		wcToMcIngress := privateEndpointsConnectingTo(workloadAzureCluster,
+			managementAzureCluster.Spec.SubscriptionID,
+			managementAzureCluster.Name)
+		count[DirectionWcToMcIngress] += len(wcToMcIngress)
+		c.collectInfo(ch, workloadAzureCluster, wcToMcIngress, DirectionWcToMcIngress, privatelinks.AzurePrivateEndpointOperatorMcIngressAnnotation)
+	}
+
+	for direction, n := range count {
+		ch <- prometheus.MustNewConstMetric(privateEndpointsDesc, prometheus.GaugeValue, float64(n),
+			c.managementClusterName.Name, direction)
+	}
+}
+
+func (c *PrivateEndpointsCollector) collectInfo(ch chan<- prometheus.Metric, workloadAzureCluster *capz.AzureCluster, privateEndpoints []capz.PrivateEndpointSpec, direction, ipAnnotation string) {
+	ip := net.ParseIP(workloadAzureCluster.GetAnnotations()[ipAnnotation])
+	ipAddress := ""
+	if ip != nil {
+		ipAddress = ip.String()
+	}
+
+	for _, privateEndpoint := range privateEndpoints {
+		ch <- prometheus.MustNewConstMetric(privateEndpointInfoDesc, prometheus.GaugeValue, 1,
+			workloadAzureCluster.Namespace,
+			workloadAzureCluster.Name,
+			privateEndpoint.Name,
+			direction,
+			ipAddress)
+	}
+}
+
+// privateEndpointsConnectingTo returns the private endpoints of the AzureCluster that connect to
+// private links in the specified subscription and resource group.
+func privateEndpointsConnectingTo(azureCluster *capz.AzureCluster, subscriptionID, resourceGroup string) []capz.PrivateEndpointSpec {
+	prefix := fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/", subscriptionID, resourceGroup)
+
+	var result []capz.PrivateEndpointSpec
+	for _, subnet := range azureCluster.Spec.NetworkSpec.Subnets {
+		for _, privateEndpoint := range subnet.PrivateEndpoints {
+			for _, connection := range privateEndpoint.PrivateLinkServiceConnections {
+				if strings.HasPrefix(connection.PrivateLinkServiceID, prefix) {
+					result = append(result, privateEndpoint)
+					break
+				}
+			}
+		}
+	}
+	return result
+}
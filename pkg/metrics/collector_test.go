@@ -0,0 +1,91 @@
+package metrics_test
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	capz "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/errors"
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/metrics"
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/privatelinks"
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/testhelpers"
+)
+
+var _ = Describe("PrivateEndpointsCollector", func() {
+	var managementClusterName types.NamespacedName
+	var k8sClient client.Client
+
+	BeforeEach(func() {
+		managementClusterName = types.NamespacedName{Namespace: "org-giantswarm", Name: "giant"}
+
+		scheme := runtime.NewScheme()
+		Expect(capz.AddToScheme(scheme)).To(Succeed())
+
+		managementAzureCluster := testhelpers.NewAzureClusterBuilder(managementClusterName.Namespace, managementClusterName.Name).
+			WithSubscriptionID("mc-subscription").
+			WithResourceGroup(managementClusterName.Name).
+			WithSubnet("node-subnet", capz.SubnetNode, capz.PrivateEndpoints{
+				testhelpers.NewPrivateEndpointBuilder("awesome-wc-api-privatelink-privateendpoint").
+					WithPrivateLinkServiceConnection("wc-subscription", "awesome-wc", "awesome-wc-api-privatelink").
+					Build(),
+			}).
+			Build()
+
+		workloadAzureCluster := testhelpers.NewAzureClusterBuilder("org-awesome", "awesome-wc").
+			WithSubscriptionID("wc-subscription").
+			WithResourceGroup("awesome-wc").
+			WithSubnet("node-subnet", capz.SubnetNode, capz.PrivateEndpoints{
+				testhelpers.NewPrivateEndpointBuilder("awesome-wc-to-giant-gateway-privateendpoint").
+					WithPrivateLinkServiceConnection("mc-subscription", "giant", "giant-gateway-privatelink").
+					Build(),
+			}).
+			Build()
+		workloadAzureCluster.Annotations = map[string]string{
+			privatelinks.AzurePrivateEndpointOperatorApiServerAnnotation: "10.0.0.4",
+			privatelinks.AzurePrivateEndpointOperatorMcIngressAnnotation: "10.1.0.4",
+		}
+
+		k8sClient = fake.NewClientBuilder().
+			WithScheme(scheme).
+			WithObjects(managementAzureCluster, workloadAzureCluster).
+			Build()
+	})
+
+	It("fails to create collector when client is nil", func() {
+		_, err := metrics.NewPrivateEndpointsCollector(nil, managementClusterName)
+		Expect(err).To(HaveOccurred())
+		Expect(errors.IsInvalidConfig(err)).To(BeTrue())
+	})
+
+	It("collects private endpoints of the workload clusters", func() {
+		collector, err := metrics.NewPrivateEndpointsCollector(k8sClient, managementClusterName)
+		Expect(err).NotTo(HaveOccurred())
+
+		expected := `
+# HELP azure_private_endpoint_operator_private_endpoints Number of private endpoints managed by the operator per management cluster and direction.
+# TYPE azure_private_endpoint_operator_private_endpoints gauge
+azure_private_endpoint_operator_private_endpoints{direction="mc_to_wc_api",management_cluster="giant"} 1
+azure_private_endpoint_operator_private_endpoints{direction="wc_to_mc_ingress",management_cluster="giant"} 1
+# HELP azure_private_endpoint_operator_private_endpoint_info Information about a private endpoint managed by the operator and its published IP address.
+# TYPE azure_private_endpoint_operator_private_endpoint_info gauge
+azure_private_endpoint_operator_private_endpoint_info{cluster_name="awesome-wc",cluster_namespace="org-awesome",direction="mc_to_wc_api",ip_address="10.0.0.4",private_endpoint="awesome-wc-api-privatelink-privateendpoint"} 1
+azure_private_endpoint_operator_private_endpoint_info{cluster_name="awesome-wc",cluster_namespace="org-awesome",direction="wc_to_mc_ingress",ip_address="10.1.0.4",private_endpoint="awesome-wc-to-giant-gateway-privateendpoint"} 1
+`
+		Expect(testutil.CollectAndCompare(collector, strings.NewReader(expected))).To(Succeed())
+	})
+
+	It("does not collect anything when the management cluster does not exist", func() {
+		managementClusterName.Name = "ghost"
+		collector, err := metrics.NewPrivateEndpointsCollector(k8sClient, managementClusterName)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(testutil.CollectAndCount(collector)).To(Equal(0))
+	})
+})
@@ -0,0 +1,170 @@
+package metrics
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/types"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	operatorerrors "github.com/giantswarm/azure-private-endpoint-operator/pkg/errors"
+)
+
+const (
+	namespace = "azure_private_endpoint_operator"
+
+	// DirectionMcToWcApi is the direction label value for private endpoints in the management
+	// cluster that connect to the workload cluster API server.
+	DirectionMcToWcApi = "mc_to_wc_api"
+	// DirectionWcToMcIngress is the direction label value for private endpoints in the workload
+	// cluster that connect to the management cluster ingress.
+	DirectionWcToMcIngress = "wc_to_mc_ingress"
+
+	ControllerAzureCluster  = "azurecluster"
+	ControllerDriftDetector = "driftdetector"
+
+	statusCodeOK    = "200"
+	statusCodeError = "error"
+)
+
+var (
+	reconcileErrors = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "reconcile_errors_total",
+			Help:      "Number of reconcile errors by controller and error kind.",
+		},
+		[]string{"controller", "kind"},
+	)
+
+	azureAPIRequestDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "azure_api_request_duration_seconds",
+			Help:      "Duration of Azure API requests by operation and response status code.",
+			Buckets:   prometheus.DefBuckets,
+		},
+		[]string{"operation", "status_code"},
+	)
+
+	privateEndpointIPPublishDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "private_endpoint_ip_publish_duration_seconds",
+			Help:      "Time from the workload cluster private links becoming ready until the private endpoint IP address is published in the workload AzureCluster.",
+			Buckets:   []float64{10, 30, 60, 120, 300, 600, 1200, 1800, 3600},
+		},
+		[]string{"direction"},
+	)
+
+	retriableErrorAttempts = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "retriable_error_attempts",
+			Help:      "Number of consecutive reconciliations of a workload cluster that ended with a retriable error, e.g. because its private links are not ready yet.",
+		},
+		[]string{"cluster_namespace", "cluster_name"},
+	)
+
+	dryRunChanges = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "dry_run_changes_total",
+			Help:      "Number of changes that the operator would have applied, but did not because it runs in dry-run mode, by kind and operation.",
+		},
+		[]string{"kind", "operation"},
+	)
+
+	privateEndpointDrift = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "private_endpoint_drift",
+			Help:      "Number of private endpoints whose AzureCluster spec differs from Azure, by cluster and drift type, as found by the last drift detection.",
+		},
+		[]string{"cluster_namespace", "cluster_name", "drift"},
+	)
+)
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(
+		reconcileErrors,
+		azureAPIRequestDuration,
+		privateEndpointIPPublishDuration,
+		retriableErrorAttempts,
+		dryRunChanges,
+		privateEndpointDrift,
+	)
+}
+
+// RecordReconcileError increments the reconcile errors counter for the kind of the given error.
+func RecordReconcileError(controller string, err error) {
+	if err == nil {
+		return
+	}
+	reconcileErrors.WithLabelValues(controller, operatorerrors.Kind(err)).Inc()
+}
+
+// ObserveAzureAPIRequest records the duration and the response status code of an Azure API request
+// that started at the given time.
+func ObserveAzureAPIRequest(operation string, start time.Time, err error) {
+	azureAPIRequestDuration.
+		WithLabelValues(operation, statusCode(err)).
+		Observe(time.Since(start).Seconds())
+}
+
+// ObservePrivateEndpointIPPublished records how long it took from the private links becoming ready
+// until the private endpoint IP address was published.
+func ObservePrivateEndpointIPPublished(direction string, privateLinksReadyAt time.Time) {
+	privateEndpointIPPublishDuration.
+		WithLabelValues(direction).
+		Observe(time.Since(privateLinksReadyAt).Seconds())
+}
+
+// SetRetriableErrorAttempts records the number of consecutive retriable errors for the workload
+// cluster, so that clusters which are stuck can be alerted on.
+func SetRetriableErrorAttempts(cluster types.NamespacedName, attempts int) {
+	retriableErrorAttempts.
+		WithLabelValues(cluster.Namespace, cluster.Name).
+		Set(float64(attempts))
+}
+
+// ResetRetriableErrorAttempts removes the retriable errors metric of the workload cluster, after
+// it has been reconciled successfully or deleted.
+func ResetRetriableErrorAttempts(cluster types.NamespacedName) {
+	retriableErrorAttempts.DeleteLabelValues(cluster.Namespace, cluster.Name)
+}
+
+// RecordDryRunChange increments the counter of changes that were not applied in dry-run mode.
+func RecordDryRunChange(kind, operation string) {
+	dryRunChanges.WithLabelValues(kind, operation).Inc()
+}
+
+// SetPrivateEndpointDrift records the number of drifted private endpoints of the given drift type
+// in the cluster.
+func SetPrivateEndpointDrift(cluster types.NamespacedName, drift string, count int) {
+	privateEndpointDrift.
+		WithLabelValues(cluster.Namespace, cluster.Name, drift).
+		Set(float64(count))
+}
+
+// ResetPrivateEndpointDrift removes the drift metrics of all clusters before a new drift detection,
+// so that deleted clusters disappear from the metrics.
+func ResetPrivateEndpointDrift() {
+	privateEndpointDrift.Reset()
+}
+
+func statusCode(err error) string {
+	if err == nil {
+		return statusCodeOK
+	}
+
+	var responseError *azcore.ResponseError
+	if errors.As(err, &responseError) {
+		return strconv.Itoa(responseError.StatusCode)
+	}
+
+	return statusCodeError
+}
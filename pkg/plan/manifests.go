@@ -0,0 +1,53 @@
+package plan
+
+import (
+	"io"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	capz "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+
+	"github.com/giantswarm/microerror"
+)
+
+const (
+	// defaultNamespace is used for AzureClusters without a namespace, e.g. when the manifests are
+	// rendered with helm template without --namespace.
+	defaultNamespace = "default"
+)
+
+// LoadAzureClusters reads the AzureClusters from the YAML or JSON manifests. All other objects in
+// the manifests, e.g. the rest of the cluster-azure chart, are ignored.
+func LoadAzureClusters(reader io.Reader) ([]*capz.AzureCluster, error) {
+	azureClusterGroupKind := capz.GroupVersion.WithKind("AzureCluster").GroupKind()
+	decoder := yaml.NewYAMLOrJSONDecoder(reader, 4096)
+
+	var azureClusters []*capz.AzureCluster
+	for {
+		var object unstructured.Unstructured
+		err := decoder.Decode(&object)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		// Empty documents, e.g. from templates that are not rendered, are decoded as empty objects.
+		if object.Object == nil || object.GroupVersionKind().GroupKind() != azureClusterGroupKind {
+			continue
+		}
+
+		var azureCluster capz.AzureCluster
+		err = runtime.DefaultUnstructuredConverter.FromUnstructured(object.Object, &azureCluster)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+		if azureCluster.Namespace == "" {
+			azureCluster.Namespace = defaultNamespace
+		}
+		azureClusters = append(azureClusters, &azureCluster)
+	}
+
+	return azureClusters, nil
+}
@@ -0,0 +1,294 @@
+package plan
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"slices"
+	"strings"
+	"sync"
+
+	azruntime "github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v9"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	capz "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	capi "sigs.k8s.io/cluster-api/api/core/v1beta1"
+	v1beta1conditions "sigs.k8s.io/cluster-api/util/deprecated/v1beta1/conditions"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/yaml"
+
+	"github.com/giantswarm/microerror"
+
+	"github.com/giantswarm/azure-private-endpoint-operator/controllers"
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/azure"
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/errors"
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/events"
+)
+
+// Options configures the offline reconciliation of AzureClusters.
+type Options struct {
+	// ManagementCluster is the AzureCluster of the management cluster.
+	ManagementCluster *capz.AzureCluster
+	// WorkloadClusters are the AzureClusters of the workload clusters, which are reconciled in
+	// the specified order.
+	WorkloadClusters []*capz.AzureCluster
+	// PrivateEndpointIP is the IP address that the fake Azure API returns for every private
+	// endpoint.
+	PrivateEndpointIP net.IP
+	// AssumePrivateLinksReady sets the PrivateLinksReady condition on the workload clusters that
+	// don't have it, which is the case for manifests that were not applied yet.
+	AssumePrivateLinksReady bool
+}
+
+// Plan is the result of reconciling the AzureClusters without a cluster and without Azure.
+type Plan struct {
+	// Reconciliations are the results of the reconciliations of the workload clusters.
+	Reconciliations []Reconciliation
+	// Changes are the changes of the management and workload AzureClusters, in the order of the
+	// options.
+	Changes []Change
+}
+
+// Reconciliation is the result of reconciling a single workload cluster.
+type Reconciliation struct {
+	Cluster types.NamespacedName
+	Result  ctrl.Result
+	Err     error
+	Events  []string
+}
+
+// Change is the JSON merge patch that the operator would apply to an AzureCluster.
+type Change struct {
+	Cluster types.NamespacedName
+	Patch   []byte
+}
+
+// Run reconciles the workload clusters with the same reconciler that the operator uses, against a
+// fake Kubernetes API that holds the AzureClusters from the options and a fake Azure API, and
+// returns the changes that the operator would apply to the AzureClusters.
+func Run(ctx context.Context, options Options) (*Plan, error) {
+	if options.ManagementCluster == nil {
+		return nil, microerror.Maskf(errors.InvalidConfigError, "management cluster must be set")
+	}
+	if options.PrivateEndpointIP == nil {
+		return nil, microerror.Maskf(errors.InvalidConfigError, "private endpoint IP must be set")
+	}
+
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		return nil, microerror.Mask(err)
+	}
+	if err := capz.AddToScheme(scheme); err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	azureClusters := []*capz.AzureCluster{options.ManagementCluster.DeepCopy()}
+	for _, workloadCluster := range options.WorkloadClusters {
+		workloadCluster = workloadCluster.DeepCopy()
+		if options.AssumePrivateLinksReady && !v1beta1conditions.Has(workloadCluster, capz.PrivateLinksReadyCondition) {
+			v1beta1conditions.Set(workloadCluster, &capi.Condition{
+				Type:               capz.PrivateLinksReadyCondition,
+				Status:             corev1.ConditionTrue,
+				LastTransitionTime: metav1.Now(),
+			})
+		}
+		azureClusters = append(azureClusters, workloadCluster)
+	}
+
+	fakeClientBuilder := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithStatusSubresource(&capz.AzureCluster{})
+	for _, azureCluster := range azureClusters {
+		azureCluster.ResourceVersion = ""
+		fakeClientBuilder = fakeClientBuilder.WithObjects(azureCluster)
+	}
+	k8sClient := fakeClientBuilder.Build()
+
+	before, err := getAzureClusters(ctx, k8sClient, azureClusters)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	eventRecorder := &eventRecorder{}
+	recorder, err := events.NewRecorder(eventRecorder, 0)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	privateEndpointsClient := &privateEndpointsClient{ip: options.PrivateEndpointIP}
+	privateEndpointsClientCreator := func(context.Context, client.Client, *capz.AzureCluster) (azure.PrivateEndpointsClient, error) {
+		return privateEndpointsClient, nil
+	}
+
+	reconciler, err := controllers.NewAzureClusterReconciler(k8sClient, privateEndpointsClientCreator, recorder, client.ObjectKeyFromObject(options.ManagementCluster), controllers.Options{})
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	var plan Plan
+	for _, workloadCluster := range azureClusters[1:] {
+		clusterName := client.ObjectKeyFromObject(workloadCluster)
+		result, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: clusterName})
+		plan.Reconciliations = append(plan.Reconciliations, Reconciliation{
+			Cluster: clusterName,
+			Result:  result,
+			Err:     err,
+			Events:  eventRecorder.flush(),
+		})
+	}
+
+	after, err := getAzureClusters(ctx, k8sClient, azureClusters)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	for i := range before {
+		patch, err := client.MergeFrom(before[i]).Data(after[i])
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+		if string(patch) == "{}" {
+			continue
+		}
+		plan.Changes = append(plan.Changes, Change{
+			Cluster: client.ObjectKeyFromObject(after[i]),
+			Patch:   patch,
+		})
+	}
+
+	return &plan, nil
+}
+
+// Failed returns true when the reconciliation of any workload cluster failed.
+func (p *Plan) Failed() bool {
+	return slices.ContainsFunc(p.Reconciliations, func(reconciliation Reconciliation) bool {
+		return reconciliation.Err != nil
+	})
+}
+
+// Write writes the results of the reconciliations and the changes of the AzureClusters as YAML.
+func (p *Plan) Write(w io.Writer) error {
+	var b strings.Builder
+	for _, reconciliation := range p.Reconciliations {
+		switch {
+		case reconciliation.Err != nil:
+			fmt.Fprintf(&b, "Workload cluster %s: failed: %s\n", reconciliation.Cluster, microerror.Pretty(reconciliation.Err, false))
+		case reconciliation.Result.RequeueAfter > 0:
+			fmt.Fprintf(&b, "Workload cluster %s: not ready, would retry after %s\n", reconciliation.Cluster, reconciliation.Result.RequeueAfter)
+		default:
+			fmt.Fprintf(&b, "Workload cluster %s: reconciled\n", reconciliation.Cluster)
+		}
+		for _, event := range reconciliation.Events {
+			fmt.Fprintf(&b, "  %s\n", event)
+		}
+	}
+
+	if len(p.Changes) == 0 {
+		b.WriteString("\nNo changes.\n")
+	}
+	for _, change := range p.Changes {
+		patch, err := yaml.JSONToYAML(change.Patch)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+		fmt.Fprintf(&b, "\nAzureCluster %s:\n", change.Cluster)
+		for _, line := range strings.Split(strings.TrimSuffix(string(patch), "\n"), "\n") {
+			fmt.Fprintf(&b, "  %s\n", line)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return microerror.Mask(err)
+}
+
+// getAzureClusters returns the current AzureClusters without the fields that are changed by every
+// write, so that they don't show up in the changes.
+func getAzureClusters(ctx context.Context, k8sClient client.Client, azureClusters []*capz.AzureCluster) ([]*capz.AzureCluster, error) {
+	var result []*capz.AzureCluster
+	for _, azureCluster := range azureClusters {
+		var current capz.AzureCluster
+		if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(azureCluster), &current); err != nil {
+			return nil, microerror.Mask(err)
+		}
+		current.TypeMeta = metav1.TypeMeta{}
+		current.ResourceVersion = ""
+		current.ManagedFields = nil
+		result = append(result, &current)
+	}
+	return result, nil
+}
+
+// privateEndpointsClient is a fake Azure private endpoints client that returns a private endpoint
+// with the same IP address for every name.
+type privateEndpointsClient struct {
+	ip net.IP
+}
+
+func (c *privateEndpointsClient) Get(_ context.Context, _ string, _ string, _ *armnetwork.PrivateEndpointsClientGetOptions) (armnetwork.PrivateEndpointsClientGetResponse, error) {
+	return armnetwork.PrivateEndpointsClientGetResponse{
+		PrivateEndpoint: armnetwork.PrivateEndpoint{
+			Properties: &armnetwork.PrivateEndpointProperties{
+				NetworkInterfaces: []*armnetwork.Interface{
+					{
+						Properties: &armnetwork.InterfacePropertiesFormat{
+							IPConfigurations: []*armnetwork.InterfaceIPConfiguration{
+								{
+									Properties: &armnetwork.InterfaceIPConfigurationPropertiesFormat{
+										PrivateIPAddress: to.Ptr(c.ip.String()),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}, nil
+}
+
+// NewListPager returns a pager without private endpoints, as the plan only looks up the private
+// endpoints that the operator creates.
+func (c *privateEndpointsClient) NewListPager(_ string, _ *armnetwork.PrivateEndpointsClientListOptions) *azruntime.Pager[armnetwork.PrivateEndpointsClientListResponse] {
+	return azruntime.NewPager(azruntime.PagingHandler[armnetwork.PrivateEndpointsClientListResponse]{
+		More: func(armnetwork.PrivateEndpointsClientListResponse) bool {
+			return false
+		},
+		Fetcher: func(context.Context, *armnetwork.PrivateEndpointsClientListResponse) (armnetwork.PrivateEndpointsClientListResponse, error) {
+			return armnetwork.PrivateEndpointsClientListResponse{}, nil
+		},
+	})
+}
+
+// eventRecorder collects the events that the operator would emit.
+type eventRecorder struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (r *eventRecorder) Eventf(regarding runtime.Object, _ runtime.Object, eventType, reason, _, note string, args ...any) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	object := ""
+	if regardingObject, ok := regarding.(client.Object); ok {
+		object = client.ObjectKeyFromObject(regardingObject).String()
+	}
+	r.events = append(r.events, fmt.Sprintf("%s %s on %s: %s", eventType, reason, object, fmt.Sprintf(note, args...)))
+}
+
+func (r *eventRecorder) flush() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	events := r.events
+	r.events = nil
+	return events
+}
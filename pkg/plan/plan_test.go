@@ -0,0 +1,109 @@
+package plan_test
+
+import (
+	"context"
+	"net"
+	"os"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/types"
+	capz "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/errors"
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/plan"
+)
+
+var _ = Describe("Plan", func() {
+	var managementCluster *capz.AzureCluster
+	var workloadCluster *capz.AzureCluster
+
+	loadAzureClusters := func(file string) []*capz.AzureCluster {
+		f, err := os.Open(file)
+		Expect(err).NotTo(HaveOccurred())
+		defer f.Close()
+
+		azureClusters, err := plan.LoadAzureClusters(f)
+		Expect(err).NotTo(HaveOccurred())
+		return azureClusters
+	}
+
+	BeforeEach(func() {
+		managementClusters := loadAzureClusters("testdata/mc.yaml")
+		Expect(managementClusters).To(HaveLen(1))
+		managementCluster = managementClusters[0]
+
+		workloadClusters := loadAzureClusters("testdata/wc.yaml")
+		Expect(workloadClusters).To(HaveLen(1))
+		workloadCluster = workloadClusters[0]
+	})
+
+	It("loads only AzureClusters from the manifests", func() {
+		Expect(workloadCluster.Namespace).To(Equal("org-acme"))
+		Expect(workloadCluster.Name).To(Equal("awesome-wc"))
+		Expect(workloadCluster.Spec.NetworkSpec.APIServerLB.PrivateLinks).To(HaveLen(1))
+	})
+
+	It("defaults the namespace of AzureClusters without namespace", func() {
+		azureClusters, err := plan.LoadAzureClusters(strings.NewReader(`
+apiVersion: infrastructure.cluster.x-k8s.io/v1beta1
+kind: AzureCluster
+metadata:
+  name: awesome-wc
+`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(azureClusters).To(HaveLen(1))
+		Expect(azureClusters[0].Namespace).To(Equal("default"))
+	})
+
+	It("fails when the management cluster is not set", func(ctx context.Context) {
+		_, err := plan.Run(ctx, plan.Options{
+			WorkloadClusters:  []*capz.AzureCluster{workloadCluster},
+			PrivateEndpointIP: net.ParseIP("10.0.0.100"),
+		})
+		Expect(errors.IsInvalidConfig(err)).To(BeTrue())
+	})
+
+	It("returns the changes of the management and workload AzureClusters", func(ctx context.Context) {
+		result, err := plan.Run(ctx, plan.Options{
+			ManagementCluster:       managementCluster,
+			WorkloadClusters:        []*capz.AzureCluster{workloadCluster},
+			PrivateEndpointIP:       net.ParseIP("10.0.0.100"),
+			AssumePrivateLinksReady: true,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Failed()).To(BeFalse())
+
+		Expect(result.Reconciliations).To(HaveLen(1))
+		Expect(result.Reconciliations[0].Events).To(ContainElement(
+			"Normal PrivateEndpointAdded on org-giantswarm/giant: Added private endpoint awesome-wc-privatelink-privateendpoint to AzureCluster org-giantswarm/giant"))
+
+		Expect(result.Changes).To(HaveLen(2))
+		Expect(result.Changes[0].Cluster).To(Equal(types.NamespacedName{Namespace: "org-giantswarm", Name: "giant"}))
+		Expect(string(result.Changes[0].Patch)).To(ContainSubstring(`"name":"awesome-wc-privatelink-privateendpoint"`))
+		Expect(result.Changes[1].Cluster).To(Equal(types.NamespacedName{Namespace: "org-acme", Name: "awesome-wc"}))
+		Expect(string(result.Changes[1].Patch)).To(ContainSubstring(`"azure-private-endpoint-operator.giantswarm.io/private-link-apiserver-ip":"10.0.0.100"`))
+		Expect(string(result.Changes[1].Patch)).To(ContainSubstring(`"name":"awesome-wc-to-giant-gateway-privateendpoint"`))
+
+		var output strings.Builder
+		Expect(result.Write(&output)).To(Succeed())
+		Expect(output.String()).To(ContainSubstring("Workload cluster org-acme/awesome-wc: reconciled"))
+		Expect(output.String()).To(ContainSubstring("AzureCluster org-giantswarm/giant:"))
+	})
+
+	It("does not assume that private links are ready", func(ctx context.Context) {
+		result, err := plan.Run(ctx, plan.Options{
+			ManagementCluster: managementCluster,
+			WorkloadClusters:  []*capz.AzureCluster{workloadCluster},
+			PrivateEndpointIP: net.ParseIP("10.0.0.100"),
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Failed()).To(BeFalse())
+		Expect(result.Reconciliations[0].Result.RequeueAfter).To(BeNumerically(">", 0))
+
+		var output strings.Builder
+		Expect(result.Write(&output)).To(Succeed())
+		Expect(output.String()).To(ContainSubstring("Workload cluster org-acme/awesome-wc: not ready, would retry after"))
+	})
+})
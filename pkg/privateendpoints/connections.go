@@ -0,0 +1,61 @@
+package privateendpoints
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v9"
+	capz "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+)
+
+// PrivateLinkServiceConnections returns both the automatically and the manually approved
+// connections of the private endpoint.
+func PrivateLinkServiceConnections(privateEndpoint *armnetwork.PrivateEndpoint) []*armnetwork.PrivateLinkServiceConnection {
+	if privateEndpoint == nil || privateEndpoint.Properties == nil {
+		return nil
+	}
+
+	var connections []*armnetwork.PrivateLinkServiceConnection
+	for _, connection := range privateEndpoint.Properties.PrivateLinkServiceConnections {
+		if connection != nil {
+			connections = append(connections, connection)
+		}
+	}
+	for _, connection := range privateEndpoint.Properties.ManualPrivateLinkServiceConnections {
+		if connection != nil {
+			connections = append(connections, connection)
+		}
+	}
+	return connections
+}
+
+// ConnectedResourceIDs returns the IDs of the resources that the private endpoint in Azure
+// connects to.
+func ConnectedResourceIDs(privateEndpoint *armnetwork.PrivateEndpoint) []string {
+	var resourceIDs []string
+	for _, connection := range PrivateLinkServiceConnections(privateEndpoint) {
+		if connection.Properties != nil && connection.Properties.PrivateLinkServiceID != nil {
+			resourceIDs = append(resourceIDs, *connection.Properties.PrivateLinkServiceID)
+		}
+	}
+	return resourceIDs
+}
+
+// SpecConnectedResourceIDs returns the IDs of the resources that the private endpoint spec
+// connects to.
+func SpecConnectedResourceIDs(spec capz.PrivateEndpointSpec) []string {
+	var resourceIDs []string
+	for _, connection := range spec.PrivateLinkServiceConnections {
+		resourceIDs = append(resourceIDs, connection.PrivateLinkServiceID)
+	}
+	return resourceIDs
+}
+
+// ConnectsToPrivateLinkService returns true when any of the connected resources is a private link
+// service, as opposed to e.g. a storage account. The operator only manages private endpoints to
+// private link services.
+func ConnectsToPrivateLinkService(resourceIDs []string) bool {
+	return slices.ContainsFunc(resourceIDs, func(resourceID string) bool {
+		return strings.Contains(strings.ToLower(resourceID), "/providers/microsoft.network/privatelinkservices/")
+	})
+}
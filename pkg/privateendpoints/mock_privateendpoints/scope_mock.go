@@ -14,6 +14,7 @@ import (
 	net "net"
 	reflect "reflect"
 
+	v9 "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v9"
 	gomock "go.uber.org/mock/gomock"
 	types "k8s.io/apimachinery/pkg/types"
 	v1beta1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
@@ -83,6 +84,20 @@ func (mr *MockScopeMockRecorder) ContainsPrivateEndpointSpec(arg0 any) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ContainsPrivateEndpointSpec", reflect.TypeOf((*MockScope)(nil).ContainsPrivateEndpointSpec), arg0)
 }
 
+// GetAzureCluster mocks base method.
+func (m *MockScope) GetAzureCluster() *v1beta1.AzureCluster {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAzureCluster")
+	ret0, _ := ret[0].(*v1beta1.AzureCluster)
+	return ret0
+}
+
+// GetAzureCluster indicates an expected call of GetAzureCluster.
+func (mr *MockScopeMockRecorder) GetAzureCluster() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAzureCluster", reflect.TypeOf((*MockScope)(nil).GetAzureCluster))
+}
+
 // GetClusterName mocks base method.
 func (m *MockScope) GetClusterName() types.NamespacedName {
 	m.ctrl.T.Helper()
@@ -182,6 +197,21 @@ func (mr *MockScopeMockRecorder) GetSubscriptionID() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubscriptionID", reflect.TypeOf((*MockScope)(nil).GetSubscriptionID))
 }
 
+// ListAzurePrivateEndpoints mocks base method.
+func (m *MockScope) ListAzurePrivateEndpoints(ctx context.Context) ([]*v9.PrivateEndpoint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAzurePrivateEndpoints", ctx)
+	ret0, _ := ret[0].([]*v9.PrivateEndpoint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAzurePrivateEndpoints indicates an expected call of ListAzurePrivateEndpoints.
+func (mr *MockScopeMockRecorder) ListAzurePrivateEndpoints(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAzurePrivateEndpoints", reflect.TypeOf((*MockScope)(nil).ListAzurePrivateEndpoints), ctx)
+}
+
 // PatchObject mocks base method.
 func (m *MockScope) PatchObject(ctx context.Context) error {
 	m.ctrl.T.Helper()
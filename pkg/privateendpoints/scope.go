@@ -22,12 +22,14 @@ import (
 // Scope is the interface for working with private endpoints.
 type Scope interface {
 	GetClusterName() types.NamespacedName
+	GetAzureCluster() *capz.AzureCluster
 	GetSubscriptionID() string
 	GetLocation() string
 	GetResourceGroup() string
 	GetPrivateEndpoints() []capz.PrivateEndpointSpec
 	GetPrivateEndpointsToWorkloadCluster(workloadClusterSubscriptionID, workloadClusterResourceGroup string) []capz.PrivateEndpointSpec
 	GetPrivateEndpointIPAddress(ctx context.Context, privateEndpointName string) (net.IP, error)
+	ListAzurePrivateEndpoints(ctx context.Context) ([]*armnetwork.PrivateEndpoint, error)
 	ContainsPrivateEndpointSpec(capz.PrivateEndpointSpec) bool
 	AddPrivateEndpointSpec(capz.PrivateEndpointSpec)
 	RemovePrivateEndpointByName(string)
@@ -143,6 +145,22 @@ func (s *scope) GetPrivateEndpointIPAddress(ctx context.Context, privateEndpoint
 	}
 }
 
+// ListAzurePrivateEndpoints returns all private endpoints that exist in Azure in the resource group
+// of the cluster, including those that are not in the AzureCluster spec.
+func (s *scope) ListAzurePrivateEndpoints(ctx context.Context) ([]*armnetwork.PrivateEndpoint, error) {
+	var privateEndpoints []*armnetwork.PrivateEndpoint
+	pager := s.privateEndpointsClient.NewListPager(s.GetResourceGroup(), nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+		privateEndpoints = append(privateEndpoints, page.Value...)
+	}
+
+	return privateEndpoints, nil
+}
+
 func (s *scope) GetPrivateEndpointsToWorkloadCluster(workloadClusterSubscriptionID, workloadClusterResourceGroup string) []capz.PrivateEndpointSpec {
 	workloadClusterSubscriptionIDPrefix := fmt.Sprintf(
 		"/subscriptions/%s/resourceGroups/%s",
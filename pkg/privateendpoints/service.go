@@ -17,6 +17,8 @@ import (
 	"github.com/giantswarm/microerror"
 
 	"github.com/giantswarm/azure-private-endpoint-operator/pkg/errors"
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/events"
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/metrics"
 	"github.com/giantswarm/azure-private-endpoint-operator/pkg/util"
 )
 
@@ -29,6 +31,7 @@ type PrivateLinksScope interface {
 	v1beta1conditions.Getter
 
 	GetClusterName() types.NamespacedName
+	GetAzureCluster() *capz.AzureCluster
 	GetSubscriptionID() string
 	GetLocation() string
 	GetResourceGroup() string
@@ -36,6 +39,8 @@ type PrivateLinksScope interface {
 	LookupPrivateLink(privateLinkResourceID string) (capz.PrivateLink, bool)
 	PatchObject(ctx context.Context) error
 	PrivateLinksReady() bool
+	GetPrivateEndpointIPAddressForWcApi() net.IP
+	GetPrivateEndpointIPAddressForMcIngress() net.IP
 	SetPrivateEndpointIPAddressForWcApi(ip net.IP)
 	SetPrivateEndpointIPAddressForMcIngress(ip net.IP)
 	SetCondition(condition capi.Condition)
@@ -45,19 +50,24 @@ type PrivateLinksScope interface {
 type Service struct {
 	privateEndpointsScope Scope
 	privateLinksScope     PrivateLinksScope
+	recorder              *events.Recorder
 }
 
-func NewService(privateEndpointsScope Scope, privateLinksScope PrivateLinksScope) (*Service, error) {
+func NewService(privateEndpointsScope Scope, privateLinksScope PrivateLinksScope, recorder *events.Recorder) (*Service, error) {
 	if privateEndpointsScope == nil {
 		return nil, microerror.Maskf(errors.InvalidConfigError, "privateEndpointsScope must be set")
 	}
 	if privateLinksScope == nil {
 		return nil, microerror.Maskf(errors.InvalidConfigError, "privateLinksScope must be set")
 	}
+	if recorder == nil {
+		return nil, microerror.Maskf(errors.InvalidConfigError, "recorder must be set")
+	}
 
 	return &Service{
 		privateEndpointsScope: privateEndpointsScope,
 		privateLinksScope:     privateLinksScope,
+		recorder:              recorder,
 	}, nil
 }
 
@@ -88,32 +98,8 @@ func (s *Service) ReconcileMcToWcApi(ctx context.Context) error {
 	//
 	for _, privateLink := range privateLinks {
 		logger.Info(fmt.Sprintf("Found private link %s", privateLink.Name))
-		manualApproval := !slices.Contains(util.ConvertToStringSlice(privateLink.AutoApprovedSubscriptions), s.privateEndpointsScope.GetSubscriptionID())
-		var requestMessage string
-		if manualApproval {
-			requestMessage = fmt.Sprintf("Giant Swarm azure-private-endpoint-operator that is running in "+
-				"management cluster %s created private endpoint in order to access private workload cluster %s",
-				s.privateEndpointsScope.GetClusterName().Name,
-				s.privateLinksScope.GetClusterName().Name)
-		}
-
-		wantedPrivateEndpoint := capz.PrivateEndpointSpec{
-			Name:     fmt.Sprintf("%s-privateendpoint", privateLink.Name),
-			Location: s.privateEndpointsScope.GetLocation(),
-			PrivateLinkServiceConnections: []capz.PrivateLinkServiceConnection{
-				{
-					Name: fmt.Sprintf("%s-connection", privateLink.Name),
-					PrivateLinkServiceID: fmt.Sprintf(
-						"/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Network/privateLinkServices/%s",
-						s.privateLinksScope.GetSubscriptionID(),
-						s.privateLinksScope.GetResourceGroup(),
-						privateLink.Name),
-					RequestMessage: requestMessage,
-				},
-			},
-			ManualApproval: manualApproval,
-		}
-		s.privateEndpointsScope.AddPrivateEndpointSpec(wantedPrivateEndpoint)
+		wantedPrivateEndpoint := s.mcToWcApiPrivateEndpointSpec(privateLink)
+		s.addPrivateEndpointSpec(wantedPrivateEndpoint)
 		logger.Info(fmt.Sprintf("Ensured private endpoint %s is added to %s", wantedPrivateEndpoint.Name, s.privateEndpointsScope.GetClusterName()))
 
 		// Apps running in the MC access WC API server via private endpoint that connects to private
@@ -126,6 +112,11 @@ func (s *Service) ReconcileMcToWcApi(ctx context.Context) error {
 			return microerror.Mask(err)
 		}
 		logger.Info("found private endpoint IP address in MC", "ipAddress", privateEndpointIPAddress.String())
+		previousPrivateEndpointIPAddress := s.privateLinksScope.GetPrivateEndpointIPAddressForWcApi()
+		s.recordPrivateEndpointIPAddressChange(wantedPrivateEndpoint.Name, previousPrivateEndpointIPAddress, privateEndpointIPAddress)
+		if previousPrivateEndpointIPAddress == nil {
+			s.observePrivateEndpointIPAddressPublished()
+		}
 		s.privateLinksScope.SetPrivateEndpointIPAddressForWcApi(privateEndpointIPAddress)
 		logger.Info("set private endpoint IP address in WC AzureCluster", "ipAddress", privateEndpointIPAddress.String())
 	}
@@ -149,7 +140,7 @@ func (s *Service) ReconcileMcToWcApi(ctx context.Context) error {
 			}
 		}
 		if !privateEndpointIsUsed {
-			s.privateEndpointsScope.RemovePrivateEndpointByName(privateEndpoint.Name)
+			s.removePrivateEndpoint(privateEndpoint.Name, "the private link it connects to no longer exists")
 			logger.Info(fmt.Sprintf("Removed private endpoint %s that is not used", privateEndpoint.Name))
 		}
 	}
@@ -157,11 +148,71 @@ func (s *Service) ReconcileMcToWcApi(ctx context.Context) error {
 	return nil
 }
 
+// DesiredMcToWcApiPrivateEndpoints returns the private endpoints that connect the MC to the API
+// server of the WC, one for every WC private link that allows the MC subscription.
+func (s *Service) DesiredMcToWcApiPrivateEndpoints() []capz.PrivateEndpointSpec {
+	var specs []capz.PrivateEndpointSpec
+	for _, privateLink := range s.privateLinksScope.GetPrivateLinksWithAllowedSubscription(s.privateEndpointsScope.GetSubscriptionID()) {
+		specs = append(specs, s.mcToWcApiPrivateEndpointSpec(privateLink))
+	}
+	return specs
+}
+
+func (s *Service) mcToWcApiPrivateEndpointSpec(privateLink capz.PrivateLink) capz.PrivateEndpointSpec {
+	manualApproval := !slices.Contains(util.ConvertToStringSlice(privateLink.AutoApprovedSubscriptions), s.privateEndpointsScope.GetSubscriptionID())
+	var requestMessage string
+	if manualApproval {
+		requestMessage = fmt.Sprintf("Giant Swarm azure-private-endpoint-operator that is running in "+
+			"management cluster %s created private endpoint in order to access private workload cluster %s",
+			s.privateEndpointsScope.GetClusterName().Name,
+			s.privateLinksScope.GetClusterName().Name)
+	}
+
+	return capz.PrivateEndpointSpec{
+		Name:     fmt.Sprintf("%s-privateendpoint", privateLink.Name),
+		Location: s.privateEndpointsScope.GetLocation(),
+		PrivateLinkServiceConnections: []capz.PrivateLinkServiceConnection{
+			{
+				Name: fmt.Sprintf("%s-connection", privateLink.Name),
+				PrivateLinkServiceID: fmt.Sprintf(
+					"/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Network/privateLinkServices/%s",
+					s.privateLinksScope.GetSubscriptionID(),
+					s.privateLinksScope.GetResourceGroup(),
+					privateLink.Name),
+				RequestMessage: requestMessage,
+			},
+		},
+		ManualApproval: manualApproval,
+	}
+}
+
+// WcToMcIngressPrivateEndpointSpecs generates PrivateEndpointSpecs for the private endpoint that
+// connects the WC to the gateway of the management cluster.
+// It assumes a private link named "<mc-name>-gateway-privatelink".
+func WcToMcIngressPrivateEndpointSpecs(wc capz.AzureCluster, mc capz.AzureCluster) []capz.PrivateEndpointSpec {
+	gatewaySpec := capz.PrivateEndpointSpec{
+		Name:     fmt.Sprintf("%s-to-%s-gateway-privateendpoint", wc.Name, mc.Name),
+		Location: wc.Spec.Location,
+		PrivateLinkServiceConnections: []capz.PrivateLinkServiceConnection{
+			{
+				Name: fmt.Sprintf("%s-to-%s-gateway-connection", wc.Name, mc.Name),
+				PrivateLinkServiceID: fmt.Sprintf(
+					"/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Network/privateLinkServices/%s",
+					mc.Spec.SubscriptionID,
+					mc.Name,
+					fmt.Sprintf("%s-gateway-privatelink", mc.Name)),
+			},
+		},
+		ManualApproval: false,
+	}
+	return []capz.PrivateEndpointSpec{gatewaySpec}
+}
+
 func (s *Service) ReconcileWcToMcIngress(ctx context.Context, specs []capz.PrivateEndpointSpec) error {
 	logger := log.FromContext(ctx)
 
 	for _, spec := range specs {
-		s.privateEndpointsScope.AddPrivateEndpointSpec(spec)
+		s.addPrivateEndpointSpec(spec)
 		logger.Info(fmt.Sprintf("Ensured private endpoint %s is added to %s", spec.Name, s.privateEndpointsScope.GetClusterName()))
 
 		ip, err := s.privateEndpointsScope.GetPrivateEndpointIPAddress(ctx, spec.Name)
@@ -169,6 +220,7 @@ func (s *Service) ReconcileWcToMcIngress(ctx context.Context, specs []capz.Priva
 			return microerror.Mask(err)
 		}
 		logger.Info("found private endpoint IP address in WC", "name", spec.Name, "ipAddress", ip.String())
+		s.recordPrivateEndpointIPAddressChange(spec.Name, s.privateLinksScope.GetPrivateEndpointIPAddressForMcIngress(), ip)
 		s.privateLinksScope.SetPrivateEndpointIPAddressForMcIngress(ip)
 		logger.Info("set private endpoint IP address in WC AzureCluster", "name", spec.Name, "ipAddress", ip.String())
 	}
@@ -193,8 +245,90 @@ func (s *Service) DeleteMcToWcApi(_ context.Context) error {
 	// For every private link, delete its corresponding private endpoint.
 	for _, privateLink := range privateLinks {
 		privateEndpointName := fmt.Sprintf("%s-privateendpoint", privateLink.Name)
-		s.privateEndpointsScope.RemovePrivateEndpointByName(privateEndpointName)
+		s.removePrivateEndpoint(privateEndpointName, "the workload cluster is being deleted")
 	}
 
 	return nil
 }
+
+// addPrivateEndpointSpec adds the private endpoint to the AzureCluster, and emits events for both
+// the private endpoints and private links AzureClusters when the private endpoint was not already
+// there.
+func (s *Service) addPrivateEndpointSpec(spec capz.PrivateEndpointSpec) {
+	if s.privateEndpointsScope.ContainsPrivateEndpointSpec(spec) {
+		return
+	}
+	s.privateEndpointsScope.AddPrivateEndpointSpec(spec)
+
+	for _, object := range s.eventObjects() {
+		s.recorder.Normalf(object, events.ReasonPrivateEndpointAdded, events.ActionAddPrivateEndpoint,
+			"Added private endpoint %s to AzureCluster %s",
+			spec.Name,
+			s.privateEndpointsScope.GetClusterName())
+		if spec.ManualApproval {
+			s.recorder.Warningf(object, events.ReasonPrivateEndpointApprovalPending, events.ActionApprovePrivateEndpoint,
+				"Private endpoint %s requires manual approval, as subscription %s is not auto-approved by the private link",
+				spec.Name,
+				s.privateEndpointsScope.GetSubscriptionID())
+		}
+	}
+}
+
+// removePrivateEndpoint removes the private endpoint from the AzureCluster, and emits events for
+// both the private endpoints and private links AzureClusters when the private endpoint was there.
+func (s *Service) removePrivateEndpoint(name, reason string) {
+	if !s.privateEndpointsScope.ContainsPrivateEndpointSpec(capz.PrivateEndpointSpec{Name: name}) {
+		return
+	}
+	s.privateEndpointsScope.RemovePrivateEndpointByName(name)
+
+	for _, object := range s.eventObjects() {
+		s.recorder.Normalf(object, events.ReasonPrivateEndpointRemoved, events.ActionRemovePrivateEndpoint,
+			"Removed private endpoint %s from AzureCluster %s because %s",
+			name,
+			s.privateEndpointsScope.GetClusterName(),
+			reason)
+	}
+}
+
+func (s *Service) recordPrivateEndpointIPAddressChange(name string, oldIP, newIP net.IP) {
+	if oldIP.Equal(newIP) {
+		return
+	}
+
+	for _, object := range s.eventObjects() {
+		if oldIP == nil {
+			s.recorder.Normalf(object, events.ReasonPrivateEndpointIPAddressChanged, events.ActionSetPrivateEndpointIP,
+				"Private endpoint %s IP address set to %s",
+				name,
+				newIP)
+		} else {
+			s.recorder.Normalf(object, events.ReasonPrivateEndpointIPAddressChanged, events.ActionSetPrivateEndpointIP,
+				"Private endpoint %s IP address changed from %s to %s",
+				name,
+				oldIP,
+				newIP)
+		}
+	}
+}
+
+// observePrivateEndpointIPAddressPublished records how long it took from the private links becoming
+// ready until the private endpoint IP address is published for the first time.
+func (s *Service) observePrivateEndpointIPAddressPublished() {
+	privateLinksReadyCondition := v1beta1conditions.Get(s.privateLinksScope, capz.PrivateLinksReadyCondition)
+	if privateLinksReadyCondition == nil || privateLinksReadyCondition.LastTransitionTime.IsZero() {
+		return
+	}
+	metrics.ObservePrivateEndpointIPPublished(metrics.DirectionMcToWcApi, privateLinksReadyCondition.LastTransitionTime.Time)
+}
+
+// eventObjects returns the AzureClusters for which the events are emitted. When both scopes are
+// working with the same AzureCluster (WC to MC connections), it is returned only once.
+func (s *Service) eventObjects() []*capz.AzureCluster {
+	privateEndpointsCluster := s.privateEndpointsScope.GetAzureCluster()
+	privateLinksCluster := s.privateLinksScope.GetAzureCluster()
+	if privateEndpointsCluster == privateLinksCluster {
+		return []*capz.AzureCluster{privateEndpointsCluster}
+	}
+	return []*capz.AzureCluster{privateEndpointsCluster, privateLinksCluster}
+}
@@ -9,12 +9,14 @@ import (
 	"go.uber.org/mock/gomock"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	k8sevents "k8s.io/client-go/tools/events"
 	capz "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
 	capi "sigs.k8s.io/cluster-api/api/core/v1beta1"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	"github.com/giantswarm/azure-private-endpoint-operator/pkg/azure/mock_azure"
 	"github.com/giantswarm/azure-private-endpoint-operator/pkg/errors"
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/events"
 	"github.com/giantswarm/azure-private-endpoint-operator/pkg/privateendpoints"
 	"github.com/giantswarm/azure-private-endpoint-operator/pkg/privatelinks"
 	"github.com/giantswarm/azure-private-endpoint-operator/pkg/testhelpers"
@@ -38,6 +40,8 @@ var _ = Describe("Service", func() {
 	var privateEndpointsScope privateendpoints.Scope
 	var service *privateendpoints.Service
 	var scheme *runtime.Scheme
+	var fakeRecorder *k8sevents.FakeRecorder
+	var recorder *events.Recorder
 
 	BeforeEach(func() {
 		subscriptionID = "1234"
@@ -46,6 +50,9 @@ var _ = Describe("Service", func() {
 		wcResourceGroup = "test-wc-rg"
 		scheme = runtime.NewScheme()
 		Expect(capz.AddToScheme(scheme)).To(Succeed())
+		fakeRecorder = k8sevents.NewFakeRecorder(100)
+		recorder, err = events.NewRecorder(fakeRecorder, events.DefaultDeduplicationWindow)
+		Expect(err).NotTo(HaveOccurred())
 	})
 
 	When("there is no private link where MC subscription is allowed", func() {
@@ -88,7 +95,7 @@ var _ = Describe("Service", func() {
 			Expect(err).NotTo(HaveOccurred())
 
 			// Private endpoints service
-			service, err = privateendpoints.NewService(privateEndpointsScope, privateLinksScope)
+			service, err = privateendpoints.NewService(privateEndpointsScope, privateLinksScope, recorder)
 			Expect(err).NotTo(HaveOccurred())
 		})
 
@@ -137,7 +144,7 @@ var _ = Describe("Service", func() {
 			Expect(err).NotTo(HaveOccurred())
 
 			// Private endpoints service
-			service, err = privateendpoints.NewService(privateEndpointsScope, privateLinksScope)
+			service, err = privateendpoints.NewService(privateEndpointsScope, privateLinksScope, recorder)
 			Expect(err).NotTo(HaveOccurred())
 		})
 
@@ -194,7 +201,7 @@ var _ = Describe("Service", func() {
 			Expect(err).NotTo(HaveOccurred())
 
 			// Private endpoints service
-			service, err = privateendpoints.NewService(privateEndpointsScope, privateLinksScope)
+			service, err = privateendpoints.NewService(privateEndpointsScope, privateLinksScope, recorder)
 			Expect(err).NotTo(HaveOccurred())
 		})
 
@@ -254,6 +261,19 @@ var _ = Describe("Service", func() {
 			privateEndpointIpAnnotation, ok := workloadAzureCluster.Annotations[privatelinks.AzurePrivateEndpointOperatorApiServerAnnotation]
 			Expect(ok).To(BeTrue())
 			Expect(privateEndpointIpAnnotation).To(Equal(expectedPrivateEndpointIp))
+
+			// events are emitted for both MC and WC AzureClusters
+			close(fakeRecorder.Events)
+			var emittedEvents []string
+			for event := range fakeRecorder.Events {
+				emittedEvents = append(emittedEvents, event)
+			}
+			Expect(emittedEvents).To(HaveLen(6))
+			Expect(emittedEvents).To(ContainElements(
+				HavePrefix("Normal PrivateEndpointAdded Added private endpoint %s", expectedPrivateEndpointName),
+				HavePrefix("Warning PrivateEndpointApprovalPending Private endpoint %s requires manual approval", expectedPrivateEndpointName),
+				Equal(fmt.Sprintf("Normal PrivateEndpointIPAddressChanged Private endpoint %s IP address set to %s", expectedPrivateEndpointName, expectedPrivateEndpointIp)),
+			))
 		})
 	})
 
@@ -306,7 +326,7 @@ var _ = Describe("Service", func() {
 			Expect(err).NotTo(HaveOccurred())
 
 			// Private endpoints service
-			service, err = privateendpoints.NewService(privateEndpointsScope, privateLinksScope)
+			service, err = privateendpoints.NewService(privateEndpointsScope, privateLinksScope, recorder)
 			Expect(err).NotTo(HaveOccurred())
 		})
 
@@ -330,6 +350,9 @@ var _ = Describe("Service", func() {
 
 			// and there is still just one private endpoint in the MC AzureCluster
 			Expect(managementAzureCluster.Spec.NetworkSpec.Subnets[0].PrivateEndpoints).To(HaveLen(1))
+
+			// no private endpoint added events are emitted
+			Expect(fakeRecorder.Events).NotTo(Receive(ContainSubstring("PrivateEndpointAdded")))
 		})
 	})
 
@@ -388,7 +411,7 @@ var _ = Describe("Service", func() {
 			Expect(err).NotTo(HaveOccurred())
 
 			// Private endpoints service
-			service, err = privateendpoints.NewService(privateEndpointsScope, privateLinksScope)
+			service, err = privateendpoints.NewService(privateEndpointsScope, privateLinksScope, recorder)
 			Expect(err).NotTo(HaveOccurred())
 		})
 
@@ -465,7 +488,7 @@ var _ = Describe("Service", func() {
 			Expect(err).NotTo(HaveOccurred())
 
 			// Private endpoints service
-			service, err = privateendpoints.NewService(privateEndpointsScope, privateLinksScope)
+			service, err = privateendpoints.NewService(privateEndpointsScope, privateLinksScope, recorder)
 			Expect(err).NotTo(HaveOccurred())
 		})
 
@@ -489,6 +512,10 @@ var _ = Describe("Service", func() {
 			// so the removed private endpoint does not exist anymore
 			exists = privateEndpointsScope.ContainsPrivateEndpointSpec(removedPrivateEndpoint)
 			Expect(exists).To(BeFalse())
+
+			// and the removal is reported for both MC and WC AzureClusters
+			Expect(fakeRecorder.Events).To(HaveLen(2))
+			Expect(fakeRecorder.Events).To(Receive(HavePrefix("Normal PrivateEndpointRemoved Removed private endpoint %s", removedPrivateEndpoint.Name)))
 		})
 	})
 })
@@ -75,6 +75,14 @@ func (s *Scope) PrivateLinksReady() bool {
 	return s.IsConditionTrue(capz.PrivateLinksReadyCondition)
 }
 
+func (s *Scope) GetPrivateEndpointIPAddressForWcApi() net.IP {
+	return net.ParseIP(s.GetAnnotation(AzurePrivateEndpointOperatorApiServerAnnotation))
+}
+
+func (s *Scope) GetPrivateEndpointIPAddressForMcIngress() net.IP {
+	return net.ParseIP(s.GetAnnotation(AzurePrivateEndpointOperatorMcIngressAnnotation))
+}
+
 func (s *Scope) SetPrivateEndpointIPAddressForWcApi(ip net.IP) {
 	s.SetAnnotation(AzurePrivateEndpointOperatorApiServerAnnotation, ip.String())
 }
@@ -37,7 +37,19 @@ func (b *AzureASOCredentialsSecretBuilder) WithClientID(id string) *AzureASOCred
 	return b
 }
 
+func (b *AzureASOCredentialsSecretBuilder) WithClientSecret(secret string) *AzureASOCredentialsSecretBuilder {
+	b.o.StringData["AZURE_CLIENT_SECRET"] = secret
+	return b
+}
+
+func (b *AzureASOCredentialsSecretBuilder) WithAuthMode(authMode string) *AzureASOCredentialsSecretBuilder {
+	b.o.StringData["AUTH_MODE"] = authMode
+	return b
+}
+
 func (b *AzureASOCredentialsSecretBuilder) Build() *corev1.Secret {
-	b.o.StringData["AUTH_MODE"] = "workloadidentity"
+	if _, ok := b.o.StringData["AUTH_MODE"]; !ok {
+		b.o.StringData["AUTH_MODE"] = "workloadidentity"
+	}
 	return b.o.DeepCopy()
 }
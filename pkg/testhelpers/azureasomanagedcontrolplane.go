@@ -20,7 +20,7 @@ func NewAzureASOManagedControlPlaneBuilder(namespace, name string) *AzureASOMana
 	b.managedCluster.SetGroupVersionKind(schema.GroupVersionKind{
 		Group:   "containerservice.azure.com",
 		Version: "v1api20240901",
-		Kind:    capz.AzureASOManagedClusterKind,
+		Kind:    "ManagedCluster",
 	})
 	b.managedCluster.SetNamespace(namespace)
 	b.managedCluster.SetName(name)
@@ -31,6 +31,7 @@ func NewAzureASOManagedControlPlaneBuilder(namespace, name string) *AzureASOMana
 type AzureASOManagedControlPlaneBuilder struct {
 	o              *capz.AzureASOManagedControlPlane
 	managedCluster *unstructured.Unstructured
+	resources      []*unstructured.Unstructured
 }
 
 func (b *AzureASOManagedControlPlaneBuilder) WithCredentialSecret(o *corev1.Secret) *AzureASOManagedControlPlaneBuilder {
@@ -40,11 +41,19 @@ func (b *AzureASOManagedControlPlaneBuilder) WithCredentialSecret(o *corev1.Secr
 	return b
 }
 
+// WithResource adds an ASO resource before the ManagedCluster.
+func (b *AzureASOManagedControlPlaneBuilder) WithResource(o *unstructured.Unstructured) *AzureASOManagedControlPlaneBuilder {
+	b.resources = append(b.resources, o)
+	return b
+}
+
 func (b *AzureASOManagedControlPlaneBuilder) Build() *capz.AzureASOManagedControlPlane {
 	b.o.Kind = capz.AzureASOManagedControlPlaneKind
-	b.o.Spec.Resources = []runtime.RawExtension{
-		{Object: b.managedCluster.DeepCopy()},
+	b.o.Spec.Resources = nil
+	for _, resource := range b.resources {
+		b.o.Spec.Resources = append(b.o.Spec.Resources, runtime.RawExtension{Object: resource.DeepCopy()})
 	}
+	b.o.Spec.Resources = append(b.o.Spec.Resources, runtime.RawExtension{Object: b.managedCluster.DeepCopy()})
 
 	return b.o
 }
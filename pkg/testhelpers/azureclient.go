@@ -5,6 +5,7 @@ import (
 	"net/http"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v9"
 	"go.uber.org/mock/gomock"
@@ -135,3 +136,58 @@ func SetupPrivateEndpointClientToReturnNotFoundAndThenPrivateEndpointWithPrivate
 			}
 		})
 }
+
+// SetupPrivateEndpointClientToReturnError sets up the client to fail with an Azure response error
+// with the specified status code, error code and headers, e.g. to simulate throttling.
+func SetupPrivateEndpointClientToReturnError(
+	privateEndpointClient *mock_azure.MockPrivateEndpointsClient,
+	mcResourceGroup string,
+	expectedPrivateEndpointName string,
+	statusCode int,
+	errorCode string,
+	header http.Header) {
+
+	privateEndpointClient.
+		EXPECT().
+		Get(
+			gomock.Any(),
+			gomock.Eq(mcResourceGroup),
+			gomock.Eq(expectedPrivateEndpointName),
+			gomock.Eq(&armnetwork.PrivateEndpointsClientGetOptions{
+				Expand: to.Ptr[string]("NetworkInterfaces"),
+			})).
+		Times(1).
+		Return(armnetwork.PrivateEndpointsClientGetResponse{}, &azcore.ResponseError{
+			StatusCode: statusCode,
+			ErrorCode:  errorCode,
+			RawResponse: &http.Response{
+				StatusCode: statusCode,
+				Header:     header,
+			},
+		})
+}
+
+// SetupPrivateEndpointClientToListPrivateEndpoints sets up the client to list the specified private
+// endpoints in the resource group, in a single page.
+func SetupPrivateEndpointClientToListPrivateEndpoints(
+	privateEndpointClient *mock_azure.MockPrivateEndpointsClient,
+	resourceGroup string,
+	privateEndpoints ...*armnetwork.PrivateEndpoint) {
+
+	privateEndpointClient.
+		EXPECT().
+		NewListPager(gomock.Eq(resourceGroup), gomock.Any()).
+		Times(1).
+		Return(runtime.NewPager(runtime.PagingHandler[armnetwork.PrivateEndpointsClientListResponse]{
+			More: func(armnetwork.PrivateEndpointsClientListResponse) bool {
+				return false
+			},
+			Fetcher: func(context.Context, *armnetwork.PrivateEndpointsClientListResponse) (armnetwork.PrivateEndpointsClientListResponse, error) {
+				return armnetwork.PrivateEndpointsClientListResponse{
+					PrivateEndpointListResult: armnetwork.PrivateEndpointListResult{
+						Value: privateEndpoints,
+					},
+				}, nil
+			},
+		}))
+}
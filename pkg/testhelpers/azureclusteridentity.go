@@ -1,6 +1,7 @@
 package testhelpers
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	capz "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
 )
@@ -9,13 +10,36 @@ func NewAzureClusterIdentityBuilder(namespace, name string) *AzureClusterIdentit
 	return &AzureClusterIdentityBuilder{
 		namespace: namespace,
 		name:      name,
+		// allow all namespaces by default
+		allowedNamespaces: &capz.AllowedNamespaces{},
 	}
 }
 
 type AzureClusterIdentityBuilder struct {
-	namespace, name string
-	tenantID        string
-	clientID        string
+	namespace, name   string
+	identityType      capz.IdentityType
+	tenantID          string
+	clientID          string
+	clientSecret      corev1.SecretReference
+	allowedNamespaces *capz.AllowedNamespaces
+}
+
+func (b *AzureClusterIdentityBuilder) WithType(identityType capz.IdentityType) *AzureClusterIdentityBuilder {
+	b.identityType = identityType
+	return b
+}
+
+func (b *AzureClusterIdentityBuilder) WithClientSecret(namespace, name string) *AzureClusterIdentityBuilder {
+	b.clientSecret = corev1.SecretReference{
+		Namespace: namespace,
+		Name:      name,
+	}
+	return b
+}
+
+func (b *AzureClusterIdentityBuilder) WithAllowedNamespaces(allowedNamespaces *capz.AllowedNamespaces) *AzureClusterIdentityBuilder {
+	b.allowedNamespaces = allowedNamespaces
+	return b
 }
 
 func (b *AzureClusterIdentityBuilder) WithTenantID(id string) *AzureClusterIdentityBuilder {
@@ -29,6 +53,11 @@ func (b *AzureClusterIdentityBuilder) WithClientID(id string) *AzureClusterIdent
 }
 
 func (b *AzureClusterIdentityBuilder) Build() *capz.AzureClusterIdentity {
+	identityType := b.identityType
+	if identityType == "" {
+		identityType = capz.WorkloadIdentity
+	}
+
 	return &capz.AzureClusterIdentity{
 		TypeMeta: v1.TypeMeta{
 			Kind: capz.AzureClusterIdentityKind,
@@ -38,9 +67,11 @@ func (b *AzureClusterIdentityBuilder) Build() *capz.AzureClusterIdentity {
 			Name:      b.name,
 		},
 		Spec: capz.AzureClusterIdentitySpec{
-			Type:     capz.WorkloadIdentity,
-			TenantID: b.tenantID,
-			ClientID: b.clientID,
+			Type:              identityType,
+			TenantID:          b.tenantID,
+			ClientID:          b.clientID,
+			ClientSecret:      b.clientSecret,
+			AllowedNamespaces: b.allowedNamespaces,
 		},
 	}
 }
@@ -59,10 +59,14 @@ func (b *ClusterBuilder) WithKubeadmControlPlane(kcp *kcp.KubeadmControlPlane) *
 
 func (b *ClusterBuilder) WithAzureASOManagedControlPlane(o *capz.AzureASOManagedControlPlane) *ClusterBuilder {
 	b.o.Spec.ControlPlaneRef = capi.ContractVersionedObjectReference{
-		APIGroup: capi.GroupVersionControlPlane.Group,
+		APIGroup: capz.GroupVersion.Group,
 		Kind:     o.Kind,
 		Name:     o.Name,
 	}
+	err := ctrl.SetControllerReference(b.o, o, scheme)
+	if err != nil {
+		panic(err)
+	}
 	b.o.Spec.InfrastructureRef = capi.ContractVersionedObjectReference{
 		APIGroup: capi.GroupVersionInfrastructure.Group,
 		Kind:     capz.AzureASOManagedClusterKind,
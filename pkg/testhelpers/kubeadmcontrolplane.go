@@ -4,6 +4,8 @@ import (
 	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
 	kcp "sigs.k8s.io/cluster-api/api/controlplane/kubeadm/v1beta2"
 	capi "sigs.k8s.io/cluster-api/api/core/v1beta2"
 )
@@ -48,3 +50,15 @@ func (b *KubeadmControlPlaneBuilder) WithStatusProvisioned() *KubeadmControlPlan
 func (b *KubeadmControlPlaneBuilder) Build() *kcp.KubeadmControlPlane {
 	return b.o
 }
+
+// BuildUnstructured builds the KubeadmControlPlane as it is seen by controllers that work on any
+// kind of control plane.
+func (b *KubeadmControlPlaneBuilder) BuildUnstructured() *unstructured.Unstructured {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(b.o)
+	if err != nil {
+		panic(err)
+	}
+	o := &unstructured.Unstructured{Object: content}
+	o.SetGroupVersionKind(kcp.GroupVersion.WithKind("KubeadmControlPlane"))
+	return o
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+
+	capz "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	"github.com/giantswarm/microerror"
+
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/plan"
+)
+
+// runPlan runs the plan subcommand, which reconciles AzureCluster manifests from files without a
+// cluster and without Azure, and prints the changes the operator would make, e.g. to review the
+// impact of cluster-azure chart changes in CI:
+//
+//	manager plan -management-cluster=mc.yaml wc1.yaml wc2.yaml
+//
+// It returns the exit code: 1 when the manifests can't be loaded or the reconciliation of a
+// workload cluster fails, and 0 otherwise.
+func runPlan(args []string) int {
+	var (
+		managementClusterFile   string
+		privateEndpointIP       string
+		assumePrivateLinksReady bool
+		verbose                 bool
+	)
+	flags := flag.NewFlagSet("plan", flag.ContinueOnError)
+	flags.StringVar(&managementClusterFile, "management-cluster", "",
+		"The file with the management cluster AzureCluster manifest")
+	flags.StringVar(&privateEndpointIP, "private-endpoint-ip", "10.0.0.100",
+		"The IP address of the private endpoints that is returned by the fake Azure API")
+	flags.BoolVar(&assumePrivateLinksReady, "assume-private-links-ready", true,
+		"Assume that the private links of the workload clusters are ready when their AzureClusters don't have the PrivateLinksReady condition")
+	flags.BoolVar(&verbose, "v", false,
+		"Print the operator logs to stderr")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s plan -management-cluster=FILE [flags] WORKLOAD_CLUSTER_FILE...\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	logOutput := io.Discard
+	if verbose {
+		logOutput = os.Stderr
+	}
+	ctrl.SetLogger(zap.New(zap.WriteTo(logOutput)))
+
+	ip := net.ParseIP(privateEndpointIP)
+	if ip == nil {
+		fmt.Fprintf(os.Stderr, "invalid private endpoint IP %q\n", privateEndpointIP)
+		return 1
+	}
+
+	managementClusters, err := loadAzureClusters(managementClusterFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "unable to load management cluster from %q: %s\n", managementClusterFile, microerror.Pretty(err, false))
+		return 1
+	}
+	if len(managementClusters) != 1 {
+		fmt.Fprintf(os.Stderr, "expected 1 AzureCluster in %q, found %d\n", managementClusterFile, len(managementClusters))
+		return 1
+	}
+
+	var workloadClusters []*capz.AzureCluster
+	for _, file := range flags.Args() {
+		azureClusters, err := loadAzureClusters(file)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "unable to load workload clusters from %q: %s\n", file, microerror.Pretty(err, false))
+			return 1
+		}
+		workloadClusters = append(workloadClusters, azureClusters...)
+	}
+
+	result, err := plan.Run(context.Background(), plan.Options{
+		ManagementCluster:       managementClusters[0],
+		WorkloadClusters:        workloadClusters,
+		PrivateEndpointIP:       ip,
+		AssumePrivateLinksReady: assumePrivateLinksReady,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "unable to plan: %s\n", microerror.Pretty(err, false))
+		return 1
+	}
+
+	if err = result.Write(os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "unable to write plan: %s\n", microerror.Pretty(err, false))
+		return 1
+	}
+	if result.Failed() {
+		return 1
+	}
+	return 0
+}
+
+func loadAzureClusters(file string) ([]*capz.AzureCluster, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	defer f.Close()
+
+	azureClusters, err := plan.LoadAzureClusters(f)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	return azureClusters, nil
+}
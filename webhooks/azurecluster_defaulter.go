@@ -0,0 +1,120 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhooks
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/types"
+	capz "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/giantswarm/microerror"
+
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/errors"
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/util"
+)
+
+//+kubebuilder:webhook:path=/mutate-infrastructure-cluster-x-k8s-io-v1beta1-azurecluster,mutating=true,failurePolicy=ignore,sideEffects=None,groups=infrastructure.cluster.x-k8s.io,resources=azureclusters,verbs=create;update,versions=v1beta1,name=default.azurecluster.azure-private-endpoint-operator.giantswarm.io,admissionReviewVersions=v1
+
+// PrivateLinkSubscriptions selects the private link subscription lists of workload clusters to
+// which AzureClusterDefaulter adds the management cluster subscription.
+type PrivateLinkSubscriptions string
+
+const (
+	// PrivateLinkSubscriptionsAllowed adds the management cluster subscription only to
+	// AllowedSubscriptions, so that the private endpoints of the management cluster must be
+	// approved manually.
+	PrivateLinkSubscriptionsAllowed PrivateLinkSubscriptions = "Allowed"
+
+	// PrivateLinkSubscriptionsAutoApproved adds the management cluster subscription to both
+	// AllowedSubscriptions and AutoApprovedSubscriptions.
+	PrivateLinkSubscriptionsAutoApproved PrivateLinkSubscriptions = "AutoApproved"
+)
+
+// AzureClusterDefaulter adds the management cluster subscription to the private links of private
+// workload clusters (Internal load balancer), so that the workload cluster charts do not have to
+// know the management cluster, and the management cluster can connect to the private links without
+// failing with SubscriptionCannotConnectToPrivateLinkError or waiting for manual approval.
+type AzureClusterDefaulter struct {
+	client                   client.Reader
+	managementClusterName    types.NamespacedName
+	privateLinkSubscriptions PrivateLinkSubscriptions
+}
+
+func NewAzureClusterDefaulter(client client.Reader, managementClusterName types.NamespacedName, privateLinkSubscriptions PrivateLinkSubscriptions) (*AzureClusterDefaulter, error) {
+	if client == nil {
+		return nil, microerror.Maskf(errors.InvalidConfigError, "client must be set")
+	}
+	if managementClusterName.Name == "" || managementClusterName.Namespace == "" {
+		return nil, microerror.Maskf(errors.InvalidConfigError, "managementClusterName must be set")
+	}
+	if privateLinkSubscriptions != PrivateLinkSubscriptionsAllowed && privateLinkSubscriptions != PrivateLinkSubscriptionsAutoApproved {
+		return nil, microerror.Maskf(errors.InvalidConfigError, "privateLinkSubscriptions must be %s or %s, got %q",
+			PrivateLinkSubscriptionsAllowed, PrivateLinkSubscriptionsAutoApproved, privateLinkSubscriptions)
+	}
+
+	return &AzureClusterDefaulter{
+		client:                   client,
+		managementClusterName:    managementClusterName,
+		privateLinkSubscriptions: privateLinkSubscriptions,
+	}, nil
+}
+
+// SetupWebhookWithManager registers the defaulting webhook with the webhook server of the Manager.
+func (d *AzureClusterDefaulter) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr, &capz.AzureCluster{}).
+		WithDefaulter(d).
+		Complete()
+}
+
+func (d *AzureClusterDefaulter) Default(ctx context.Context, azureCluster *capz.AzureCluster) error {
+	if !azureCluster.DeletionTimestamp.IsZero() {
+		return nil
+	}
+	apiServerLB := azureCluster.Spec.NetworkSpec.APIServerLB
+	if apiServerLB == nil || apiServerLB.Type != capz.Internal {
+		return nil
+	}
+	if client.ObjectKeyFromObject(azureCluster) == d.managementClusterName {
+		return nil
+	}
+
+	managementClusterSubscriptionID, err := getManagementClusterSubscriptionID(ctx, d.client, d.managementClusterName)
+	if err != nil {
+		return microerror.Mask(err)
+	} else if managementClusterSubscriptionID == "" {
+		log.FromContext(ctx).Info(fmt.Sprintf("Management cluster %s subscription is not known, not defaulting private links", d.managementClusterName))
+		return nil
+	}
+
+	for i := range apiServerLB.PrivateLinks {
+		privateLink := &apiServerLB.PrivateLinks[i]
+		if !util.ContainsPtr(privateLink.AllowedSubscriptions, managementClusterSubscriptionID) {
+			privateLink.AllowedSubscriptions = append(privateLink.AllowedSubscriptions, &managementClusterSubscriptionID)
+		}
+		if d.privateLinkSubscriptions == PrivateLinkSubscriptionsAutoApproved &&
+			!util.ContainsPtr(privateLink.AutoApprovedSubscriptions, managementClusterSubscriptionID) {
+			privateLink.AutoApprovedSubscriptions = append(privateLink.AutoApprovedSubscriptions, &managementClusterSubscriptionID)
+		}
+	}
+
+	return nil
+}
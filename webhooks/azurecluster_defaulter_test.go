@@ -0,0 +1,106 @@
+package webhooks_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	capz "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/errors"
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/testhelpers"
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/util"
+	"github.com/giantswarm/azure-private-endpoint-operator/webhooks"
+)
+
+var _ = Describe("AzureClusterDefaulter", func() {
+	var k8sClient client.Client
+	var managementAzureCluster *capz.AzureCluster
+	var workloadAzureCluster *capz.AzureCluster
+
+	newDefaulter := func(privateLinkSubscriptions webhooks.PrivateLinkSubscriptions) *webhooks.AzureClusterDefaulter {
+		defaulter, err := webhooks.NewAzureClusterDefaulter(k8sClient, client.ObjectKeyFromObject(managementAzureCluster), privateLinkSubscriptions)
+		Expect(err).NotTo(HaveOccurred())
+		return defaulter
+	}
+
+	BeforeEach(func() {
+		managementAzureCluster = testhelpers.NewAzureClusterBuilder("org-giantswarm", "giant").
+			WithSubscriptionID("mc-subscription").
+			WithAPILoadBalancerType(capz.Internal).
+			Build()
+		workloadAzureCluster = testhelpers.NewAzureClusterBuilder("org-acme", "awesome-wc").
+			WithSubscriptionID("wc-subscription").
+			WithAPILoadBalancerType(capz.Internal).
+			WithPrivateLink(testhelpers.NewPrivateLinkBuilder("awesome-wc-privatelink").
+				WithAllowedSubscription("wc-subscription").
+				WithAutoApprovedSubscription("wc-subscription").
+				Build()).
+			Build()
+
+		scheme := runtime.NewScheme()
+		Expect(capz.AddToScheme(scheme)).To(Succeed())
+		k8sClient = fake.NewClientBuilder().
+			WithScheme(scheme).
+			WithObjects(managementAzureCluster).
+			Build()
+	})
+
+	It("fails to create defaulter with unknown private link subscriptions", func() {
+		_, err := webhooks.NewAzureClusterDefaulter(k8sClient, client.ObjectKeyFromObject(managementAzureCluster), "Everything")
+		Expect(errors.IsInvalidConfig(err)).To(BeTrue())
+	})
+
+	It("adds the management cluster subscription to allowed and auto-approved subscriptions", func(ctx context.Context) {
+		Expect(newDefaulter(webhooks.PrivateLinkSubscriptionsAutoApproved).Default(ctx, workloadAzureCluster)).To(Succeed())
+
+		privateLink := workloadAzureCluster.Spec.NetworkSpec.APIServerLB.PrivateLinks[0]
+		Expect(util.ConvertToStringSlice(privateLink.AllowedSubscriptions)).To(Equal([]string{"wc-subscription", "mc-subscription"}))
+		Expect(util.ConvertToStringSlice(privateLink.AutoApprovedSubscriptions)).To(Equal([]string{"wc-subscription", "mc-subscription"}))
+	})
+
+	It("only adds the management cluster subscription to allowed subscriptions", func(ctx context.Context) {
+		Expect(newDefaulter(webhooks.PrivateLinkSubscriptionsAllowed).Default(ctx, workloadAzureCluster)).To(Succeed())
+
+		privateLink := workloadAzureCluster.Spec.NetworkSpec.APIServerLB.PrivateLinks[0]
+		Expect(util.ConvertToStringSlice(privateLink.AllowedSubscriptions)).To(Equal([]string{"wc-subscription", "mc-subscription"}))
+		Expect(util.ConvertToStringSlice(privateLink.AutoApprovedSubscriptions)).To(Equal([]string{"wc-subscription"}))
+	})
+
+	It("does not add the management cluster subscription twice", func(ctx context.Context) {
+		defaulter := newDefaulter(webhooks.PrivateLinkSubscriptionsAutoApproved)
+		Expect(defaulter.Default(ctx, workloadAzureCluster)).To(Succeed())
+		Expect(defaulter.Default(ctx, workloadAzureCluster)).To(Succeed())
+
+		privateLink := workloadAzureCluster.Spec.NetworkSpec.APIServerLB.PrivateLinks[0]
+		Expect(util.ConvertToStringSlice(privateLink.AllowedSubscriptions)).To(Equal([]string{"wc-subscription", "mc-subscription"}))
+		Expect(util.ConvertToStringSlice(privateLink.AutoApprovedSubscriptions)).To(Equal([]string{"wc-subscription", "mc-subscription"}))
+	})
+
+	It("does not change public workload clusters", func(ctx context.Context) {
+		workloadAzureCluster.Spec.NetworkSpec.APIServerLB.Type = capz.Public
+		expected := workloadAzureCluster.DeepCopy()
+		Expect(newDefaulter(webhooks.PrivateLinkSubscriptionsAutoApproved).Default(ctx, workloadAzureCluster)).To(Succeed())
+		Expect(workloadAzureCluster).To(Equal(expected))
+	})
+
+	It("does not change AzureClusters that are being deleted", func(ctx context.Context) {
+		workloadAzureCluster.Finalizers = []string{"capz"}
+		workloadAzureCluster.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+		expected := workloadAzureCluster.DeepCopy()
+		Expect(newDefaulter(webhooks.PrivateLinkSubscriptionsAutoApproved).Default(ctx, workloadAzureCluster)).To(Succeed())
+		Expect(workloadAzureCluster).To(Equal(expected))
+	})
+
+	It("does not change AzureClusters when the management cluster does not exist", func(ctx context.Context) {
+		Expect(k8sClient.Delete(ctx, managementAzureCluster)).To(Succeed())
+		expected := workloadAzureCluster.DeepCopy()
+		Expect(newDefaulter(webhooks.PrivateLinkSubscriptionsAutoApproved).Default(ctx, workloadAzureCluster)).To(Succeed())
+		Expect(workloadAzureCluster).To(Equal(expected))
+	})
+})
@@ -0,0 +1,192 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhooks
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	capz "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/giantswarm/microerror"
+
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/azurecluster"
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/errors"
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/util"
+)
+
+//+kubebuilder:webhook:path=/validate-infrastructure-cluster-x-k8s-io-v1beta1-azurecluster,mutating=false,failurePolicy=ignore,sideEffects=None,groups=infrastructure.cluster.x-k8s.io,resources=azureclusters,verbs=create;update,versions=v1beta1,name=validation.azurecluster.azure-private-endpoint-operator.giantswarm.io,admissionReviewVersions=v1
+
+// AzureClusterValidator rejects AzureClusters for which the private endpoints can not be
+// reconciled, so that the mistakes are reported when the AzureCluster is applied instead of only
+// in the operator logs and events:
+//   - the API server load balancer type must be Internal or Public,
+//   - private clusters (Internal load balancer) must have a subnet for the private endpoints,
+//   - private workload clusters must have a private link that allows the management cluster
+//     subscription.
+//
+// On update, only errors that the old AzureCluster did not already have are rejected, so that
+// existing AzureClusters can still be changed, e.g. by CAPZ, and AzureClusters that are being
+// deleted are not validated at all.
+type AzureClusterValidator struct {
+	client                client.Reader
+	managementClusterName types.NamespacedName
+}
+
+func NewAzureClusterValidator(client client.Reader, managementClusterName types.NamespacedName) (*AzureClusterValidator, error) {
+	if client == nil {
+		return nil, microerror.Maskf(errors.InvalidConfigError, "client must be set")
+	}
+	if managementClusterName.Name == "" || managementClusterName.Namespace == "" {
+		return nil, microerror.Maskf(errors.InvalidConfigError, "managementClusterName must be set")
+	}
+
+	return &AzureClusterValidator{
+		client:                client,
+		managementClusterName: managementClusterName,
+	}, nil
+}
+
+// SetupWebhookWithManager registers the validating webhook with the webhook server of the Manager.
+func (v *AzureClusterValidator) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr, &capz.AzureCluster{}).
+		WithValidator(v).
+		Complete()
+}
+
+func (v *AzureClusterValidator) ValidateCreate(ctx context.Context, azureCluster *capz.AzureCluster) (admission.Warnings, error) {
+	allErrs, err := v.validate(ctx, azureCluster)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return nil, invalid(azureCluster, allErrs)
+}
+
+func (v *AzureClusterValidator) ValidateUpdate(ctx context.Context, oldAzureCluster, newAzureCluster *capz.AzureCluster) (admission.Warnings, error) {
+	if !newAzureCluster.DeletionTimestamp.IsZero() {
+		return nil, nil
+	}
+
+	oldErrs, err := v.validate(ctx, oldAzureCluster)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	newErrs, err := v.validate(ctx, newAzureCluster)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	var allErrs field.ErrorList
+	for _, newErr := range newErrs {
+		alreadyInvalid := slices.ContainsFunc(oldErrs, func(oldErr *field.Error) bool {
+			return oldErr.Type == newErr.Type && oldErr.Field == newErr.Field
+		})
+		if !alreadyInvalid {
+			allErrs = append(allErrs, newErr)
+		}
+	}
+
+	return nil, invalid(newAzureCluster, allErrs)
+}
+
+func (v *AzureClusterValidator) ValidateDelete(_ context.Context, _ *capz.AzureCluster) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (v *AzureClusterValidator) validate(ctx context.Context, azureCluster *capz.AzureCluster) (field.ErrorList, error) {
+	// CAPZ defaults the API server load balancer before the validating webhooks are called.
+	apiServerLB := azureCluster.Spec.NetworkSpec.APIServerLB
+	if apiServerLB == nil {
+		return nil, nil
+	}
+
+	var allErrs field.ErrorList
+	apiServerLBPath := field.NewPath("spec", "networkSpec", "apiServerLB")
+	if err := azurecluster.ValidateLBType(*azureCluster); err != nil {
+		allErrs = append(allErrs, field.NotSupported(apiServerLBPath.Child("type"), apiServerLB.Type,
+			[]capz.LBType{capz.Internal, capz.Public}))
+	}
+
+	if apiServerLB.Type != capz.Internal {
+		return allErrs, nil
+	}
+
+	if len(azureCluster.Spec.NetworkSpec.Subnets) == 0 {
+		allErrs = append(allErrs, field.Required(field.NewPath("spec", "networkSpec", "subnets"),
+			"private clusters need a subnet where the private endpoints are created"))
+	}
+
+	if client.ObjectKeyFromObject(azureCluster) == v.managementClusterName {
+		return allErrs, nil
+	}
+
+	managementClusterSubscriptionID, err := getManagementClusterSubscriptionID(ctx, v.client, v.managementClusterName)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	} else if managementClusterSubscriptionID == "" {
+		return allErrs, nil
+	}
+
+	allowed := slices.ContainsFunc(apiServerLB.PrivateLinks, func(privateLink capz.PrivateLink) bool {
+		return util.ContainsPtr(privateLink.AllowedSubscriptions, managementClusterSubscriptionID)
+	})
+	if !allowed {
+		allErrs = append(allErrs, field.Invalid(apiServerLBPath.Child("privateLinks"), privateLinkNames(apiServerLB.PrivateLinks),
+			fmt.Sprintf("management cluster %s subscription %s must be in allowedSubscriptions of at least one private link",
+				v.managementClusterName, managementClusterSubscriptionID)))
+	}
+
+	return allErrs, nil
+}
+
+func invalid(azureCluster *capz.AzureCluster, allErrs field.ErrorList) error {
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(capz.GroupVersion.WithKind(capz.AzureClusterKind).GroupKind(), azureCluster.Name, allErrs)
+}
+
+// getManagementClusterSubscriptionID returns the subscription of the management cluster, or an
+// empty string when the management cluster AzureCluster does not exist, e.g. while it is being
+// bootstrapped.
+func getManagementClusterSubscriptionID(ctx context.Context, c client.Reader, managementClusterName types.NamespacedName) (string, error) {
+	var managementAzureCluster capz.AzureCluster
+	err := c.Get(ctx, managementClusterName, &managementAzureCluster)
+	if apierrors.IsNotFound(err) {
+		return "", nil
+	} else if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	return managementAzureCluster.Spec.SubscriptionID, nil
+}
+
+func privateLinkNames(privateLinks []capz.PrivateLink) []string {
+	names := []string{}
+	for _, privateLink := range privateLinks {
+		names = append(names, privateLink.Name)
+	}
+	return names
+}
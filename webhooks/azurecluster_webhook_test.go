@@ -0,0 +1,131 @@
+package webhooks_test
+
+import (
+	"context"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	capz "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/errors"
+	"github.com/giantswarm/azure-private-endpoint-operator/pkg/testhelpers"
+	"github.com/giantswarm/azure-private-endpoint-operator/webhooks"
+)
+
+var _ = Describe("AzureClusterValidator", func() {
+	var k8sClient client.Client
+	var managementAzureCluster *capz.AzureCluster
+	var workloadAzureCluster *capz.AzureCluster
+	var validator *webhooks.AzureClusterValidator
+
+	BeforeEach(func() {
+		managementAzureCluster = testhelpers.NewAzureClusterBuilder("org-giantswarm", "giant").
+			WithSubscriptionID("mc-subscription").
+			WithAPILoadBalancerType(capz.Internal).
+			WithSubnet("giant-node-subnet", capz.SubnetNode, nil).
+			Build()
+		workloadAzureCluster = testhelpers.NewAzureClusterBuilder("org-acme", "awesome-wc").
+			WithSubscriptionID("wc-subscription").
+			WithAPILoadBalancerType(capz.Internal).
+			WithSubnet("awesome-wc-node-subnet", capz.SubnetNode, nil).
+			WithPrivateLink(capz.PrivateLink{
+				Name:                 "awesome-wc-privatelink",
+				AllowedSubscriptions: []*string{to.Ptr("mc-subscription")},
+			}).
+			Build()
+
+		scheme := runtime.NewScheme()
+		Expect(capz.AddToScheme(scheme)).To(Succeed())
+		k8sClient = fake.NewClientBuilder().
+			WithScheme(scheme).
+			WithObjects(managementAzureCluster).
+			Build()
+
+		var err error
+		validator, err = webhooks.NewAzureClusterValidator(k8sClient, client.ObjectKeyFromObject(managementAzureCluster))
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("fails to create validator when the management cluster name is not set", func() {
+		_, err := webhooks.NewAzureClusterValidator(k8sClient, types.NamespacedName{})
+		Expect(errors.IsInvalidConfig(err)).To(BeTrue())
+	})
+
+	It("accepts a valid private workload cluster", func(ctx context.Context) {
+		_, err := validator.ValidateCreate(ctx, workloadAzureCluster)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("accepts a public workload cluster without private links", func(ctx context.Context) {
+		publicAzureCluster := testhelpers.NewAzureClusterBuilder("org-acme", "public-wc").
+			WithAPILoadBalancerType(capz.Public).
+			Build()
+		_, err := validator.ValidateCreate(ctx, publicAzureCluster)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("rejects an unsupported load balancer type", func(ctx context.Context) {
+		workloadAzureCluster.Spec.NetworkSpec.APIServerLB.Type = "Hybrid"
+		_, err := validator.ValidateCreate(ctx, workloadAzureCluster)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("spec.networkSpec.apiServerLB.type"))
+	})
+
+	It("rejects a private workload cluster without subnets", func(ctx context.Context) {
+		workloadAzureCluster.Spec.NetworkSpec.Subnets = nil
+		_, err := validator.ValidateCreate(ctx, workloadAzureCluster)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("spec.networkSpec.subnets"))
+	})
+
+	It("rejects a private workload cluster whose private links don't allow the management cluster subscription", func(ctx context.Context) {
+		workloadAzureCluster.Spec.NetworkSpec.APIServerLB.PrivateLinks[0].AllowedSubscriptions = []*string{to.Ptr("other-subscription")}
+		_, err := validator.ValidateCreate(ctx, workloadAzureCluster)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("subscription mc-subscription must be in allowedSubscriptions"))
+	})
+
+	It("does not check the private links of the management cluster", func(ctx context.Context) {
+		_, err := validator.ValidateCreate(ctx, managementAzureCluster)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("does not check the private links when the management cluster does not exist", func(ctx context.Context) {
+		Expect(k8sClient.Delete(ctx, managementAzureCluster)).To(Succeed())
+		workloadAzureCluster.Spec.NetworkSpec.APIServerLB.PrivateLinks = nil
+		_, err := validator.ValidateCreate(ctx, workloadAzureCluster)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("accepts updates of an AzureCluster that was already invalid", func(ctx context.Context) {
+		workloadAzureCluster.Spec.NetworkSpec.APIServerLB.PrivateLinks = nil
+		updatedAzureCluster := workloadAzureCluster.DeepCopy()
+		updatedAzureCluster.Annotations = map[string]string{"foo": "bar"}
+		_, err := validator.ValidateUpdate(ctx, workloadAzureCluster, updatedAzureCluster)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("rejects updates that make an AzureCluster invalid", func(ctx context.Context) {
+		updatedAzureCluster := workloadAzureCluster.DeepCopy()
+		updatedAzureCluster.Spec.NetworkSpec.Subnets = nil
+		_, err := validator.ValidateUpdate(ctx, workloadAzureCluster, updatedAzureCluster)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+	})
+
+	It("accepts updates of an AzureCluster that is being deleted", func(ctx context.Context) {
+		updatedAzureCluster := testhelpers.NewAzureClusterBuilder("org-acme", "awesome-wc").
+			WithAPILoadBalancerType(capz.Internal).
+			WithFinalizer("capz").
+			WithDeletionTimestamp(time.Now()).
+			Build()
+		_, err := validator.ValidateUpdate(ctx, workloadAzureCluster, updatedAzureCluster)
+		Expect(err).NotTo(HaveOccurred())
+	})
+})